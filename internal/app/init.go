@@ -1,34 +1,66 @@
 package app
 
 import (
+	"context"
 	"fmt"
+	"net"
 	"net/http"
 	"sync/atomic"
 
 	"go.uber.org/zap"
+	"google.golang.org/grpc"
 	"gorm.io/gorm"
 
 	"github.com/silverspase/todo/internal/app/repository/sql"
 	"github.com/silverspase/todo/internal/config"
+	"github.com/silverspase/todo/internal/graphql"
 	appLogger "github.com/silverspase/todo/internal/logger"
 	"github.com/silverspase/todo/internal/modules/auth"
+	authModel "github.com/silverspase/todo/internal/modules/auth/model"
 	authMemory "github.com/silverspase/todo/internal/modules/auth/repository/memory"
 	authRepo "github.com/silverspase/todo/internal/modules/auth/repository/postgres"
 	authTransport "github.com/silverspase/todo/internal/modules/auth/transport/gorilla-mux"
+	authGRPC "github.com/silverspase/todo/internal/modules/auth/transport/grpc"
+	authPb "github.com/silverspase/todo/internal/modules/auth/transport/grpc/pb"
+	"github.com/silverspase/todo/internal/modules/list"
+	listMemory "github.com/silverspase/todo/internal/modules/list/repository/memory"
+	listRepo "github.com/silverspase/todo/internal/modules/list/repository/postgres"
+	listTransport "github.com/silverspase/todo/internal/modules/list/transport/gorilla-mux"
 	"github.com/silverspase/todo/internal/modules/todo"
 	"github.com/silverspase/todo/internal/modules/todo/repository/memory"
 	"github.com/silverspase/todo/internal/modules/todo/repository/postgres"
 	todoTransport "github.com/silverspase/todo/internal/modules/todo/transport/gorilla-mux"
+	todoGRPC "github.com/silverspase/todo/internal/modules/todo/transport/grpc"
+	todoPb "github.com/silverspase/todo/internal/modules/todo/transport/grpc/pb"
+	"github.com/silverspase/todo/internal/modules/webhook"
+	webhookMemory "github.com/silverspase/todo/internal/modules/webhook/repository/memory"
+	webhookRepo "github.com/silverspase/todo/internal/modules/webhook/repository/postgres"
+	webhookTransport "github.com/silverspase/todo/internal/modules/webhook/transport/gorilla-mux"
 
 	authUseCase "github.com/silverspase/todo/internal/modules/auth/usecase"
+	listUseCase "github.com/silverspase/todo/internal/modules/list/usecase"
 	todoUseCase "github.com/silverspase/todo/internal/modules/todo/usecase"
+	webhookUseCase "github.com/silverspase/todo/internal/modules/webhook/usecase"
 )
 
 type App struct {
 	Todo todo.Transport
-	Auth auth.Transport
+	// Items purges the trash in the background
+	Items todo.UseCase
+	List  list.Transport
+	Auth  auth.Transport
+
+	Webhook webhook.Transport
+	// Webhooks sends the webhook deliveries in the background
+	Webhooks webhook.UseCase
+
+	GraphQL http.Handler
+
+	TodoGRPC todoPb.TodoServiceServer
+	AuthGRPC authPb.UserServiceServer
 
 	Srv     *http.Server
+	GRPCSrv *grpc.Server
 	Logger  *zap.Logger
 	Cfg     config.Config
 	isReady *atomic.Value
@@ -39,56 +71,157 @@ func Init() (*App, error) {
 
 	logger := appLogger.Init(cfg)
 	logger.Info("Starting server", zap.String("params:",
-		fmt.Sprintf("port: %s, log level: %s, repo: %s", cfg.Port, cfg.LogLevel, cfg.Repository)))
+		fmt.Sprintf("port: %s, grpc port: %s, log level: %s, repo: %s", cfg.Port, cfg.GRPCPort, cfg.LogLevel, cfg.Repository)))
+
+	var sqlConn *gorm.DB
+	if cfg.Repository.SQL() {
+		var err error
+		sqlConn, err = sql.NewConn(logger, cfg)
+		if err != nil {
+			return nil, err
+		}
+
+		// refuse to serve against a schema the code doesn't expect
+		migrator, err := sql.NewMigrator(sqlConn, logger)
+		if err != nil {
+			return nil, err
+		}
+		if err = migrator.Check(context.Background()); err != nil {
+			return nil, err
+		}
+	}
+
+	itemRepo := newTodoRepository(cfg, logger, sqlConn)
+	listRepo := newListRepository(cfg, logger, sqlConn)
+	userRepo := newUserRepository(cfg, logger, sqlConn)
 
-	sqlConn, err := sql.NewConn(logger, cfg)
+	webhookCase := initWebhookModule(cfg, logger, sqlConn)
+	todoCase := initTodoModule(cfg, logger, itemRepo, listRepo, userRepo, webhookCase)
+	listCase := initListModule(logger, listRepo, itemRepo, todoCase, userRepo)
+	authCase, err := initAuthModule(cfg, logger, sqlConn, userRepo)
 	if err != nil {
 		return nil, err
 	}
 
+	// all transports serve the same use cases
 	application := &App{
-		Todo:   initTodoModule(cfg, logger, sqlConn),
-		Auth:   initAuthModule(cfg, logger, sqlConn),
-		Logger: logger,
-		Cfg:    cfg,
+		Todo:     todoTransport.NewTransport(logger, todoCase, cfg.AllowedOrigins),
+		Items:    todoCase,
+		List:     listTransport.NewTransport(logger, listCase),
+		Auth:     authTransport.NewTransport(logger, authCase),
+		Webhook:  webhookTransport.NewTransport(logger, webhookCase),
+		Webhooks: webhookCase,
+		GraphQL:  graphql.NewHandler(logger, todoCase, listCase, authCase),
+		TodoGRPC: todoGRPC.NewTransport(logger, todoCase),
+		AuthGRPC: authGRPC.NewTransport(logger, authCase),
+		Logger:   logger,
+		Cfg:      cfg,
 	}
 
+	// Shutdown waits for the active requests, event streams end when this context is cancelled
+	baseCtx, cancel := context.WithCancel(context.Background())
 	application.Srv = &http.Server{
-		Addr:    ":" + cfg.Port,
-		Handler: gorillaMuxRouter(application),
+		Addr:        ":" + cfg.Port,
+		Handler:     gorillaMuxRouter(application),
+		BaseContext: func(net.Listener) context.Context { return baseCtx },
 	}
+	application.Srv.RegisterOnShutdown(cancel)
+	application.GRPCSrv = grpcServer(application, authGRPC.UnaryInterceptor(logger, authCase))
 
 	return application, nil
 }
 
-func initTodoModule(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB) todo.Transport {
-	var repo todo.Repository
+// The todo and list modules depend on each other's repositories and on the users one,
+// so they are created upfront.
+// The gorm repositories stick to portable SQL and serve both postgres and sqlite.
+func newTodoRepository(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB) (repo todo.Repository) {
 	switch cfg.Repository {
 	case config.MemoryRepo:
 		repo = memory.NewMemoryStorage(logger)
-	case config.PostgresRepo:
+	case config.PostgresRepo, config.SQLiteRepo:
 		repo = postgres.NewRepository(sqlConn, logger)
 	default:
 		logger.Fatal("unable to define repo type")
 	}
 
-	useCase := todoUseCase.NewItemUseCase(logger, repo)
+	return repo
+}
+
+func newListRepository(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB) (repo list.Repository) {
+	switch cfg.Repository {
+	case config.MemoryRepo:
+		repo = listMemory.NewMemoryStorage(logger)
+	case config.PostgresRepo, config.SQLiteRepo:
+		repo = listRepo.NewRepository(sqlConn, logger)
+	default:
+		logger.Fatal("unable to define repo type")
+	}
 
-	return todoTransport.NewTransport(logger, useCase) // add support of several transports
+	return repo
 }
 
-func initAuthModule(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB) auth.Transport {
-	var repo auth.Repository
+func newUserRepository(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB) (repo auth.Repository) {
 	switch cfg.Repository {
 	case config.MemoryRepo:
 		repo = authMemory.NewMemoryStorage(logger)
-	case config.PostgresRepo:
+	case config.PostgresRepo, config.SQLiteRepo:
 		repo = authRepo.NewRepository(sqlConn, logger)
 	default:
 		logger.Fatal("unable to define repo type")
 	}
 
-	useCase := authUseCase.NewUseCase(logger, repo)
+	return repo
+}
+
+func initTodoModule(cfg config.Config, logger *zap.Logger, repo todo.Repository, lists list.Repository,
+	users auth.Repository, listeners ...todo.Listener) todo.UseCase {
+	completion := todo.CompletionPolicy(cfg.SubtaskCompletion)
+	if !completion.Valid() {
+		logger.Fatal("unknown subtask completion policy", zap.String("policy", cfg.SubtaskCompletion))
+	}
+
+	return todoUseCase.NewItemUseCase(logger, repo, lists, users, completion, cfg.TrashRetention, listeners...)
+}
+
+func initListModule(logger *zap.Logger, repo list.Repository, items todo.Repository, itemCase todo.UseCase,
+	users auth.Repository) list.UseCase {
+	return listUseCase.NewListUseCase(logger, repo, items, itemCase, users)
+}
+
+func initAuthModule(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB, repo auth.Repository) (auth.UseCase, error) {
+	var auditLog auth.AuditRepository
+	switch cfg.Repository {
+	case config.MemoryRepo:
+		auditLog = authMemory.NewAuditStorage(logger)
+	case config.PostgresRepo, config.SQLiteRepo:
+		auditLog = authRepo.NewAuditRepository(sqlConn, logger)
+	default:
+		logger.Fatal("unable to define repo type")
+	}
+
+	useCase := authUseCase.NewUseCase(logger, repo, auditLog, cfg.SessionTTL)
+	if cfg.BootstrapAdminEmail == "" {
+		return useCase, nil
+	}
+
+	err := useCase.Bootstrap(context.Background(), authModel.User{
+		Name:     cfg.BootstrapAdminName,
+		Email:    cfg.BootstrapAdminEmail,
+		Password: cfg.BootstrapAdminPassword,
+	})
+	return useCase, err
+}
+
+func initWebhookModule(cfg config.Config, logger *zap.Logger, sqlConn *gorm.DB) webhook.UseCase {
+	var repo webhook.Repository
+	switch cfg.Repository {
+	case config.MemoryRepo:
+		repo = webhookMemory.NewMemoryStorage(logger)
+	case config.PostgresRepo, config.SQLiteRepo:
+		repo = webhookRepo.NewRepository(sqlConn, logger)
+	default:
+		logger.Fatal("unable to define repo type")
+	}
 
-	return authTransport.NewTransport(logger, useCase) // add support of several transports
+	return webhookUseCase.NewWebhookUseCase(logger, repo, cfg.WebhookMaxAttempts, cfg.WebhookBackoff)
 }
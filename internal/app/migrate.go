@@ -0,0 +1,81 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/silverspase/todo/internal/app/repository/sql"
+	"github.com/silverspase/todo/internal/config"
+	appLogger "github.com/silverspase/todo/internal/logger"
+)
+
+const migrateUsage = "usage: todo migrate up | down [steps] | status"
+
+// Migrate runs the `todo migrate` subcommand: up applies the pending migrations,
+// down reverts the latest one (or the given number of steps), status lists them.
+func Migrate(args []string, out io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	cfg := config.Init()
+	if !cfg.Repository.SQL() {
+		return fmt.Errorf("migrations need a SQL repository, REPOSITORY is %q", cfg.Repository)
+	}
+
+	logger := appLogger.Init(cfg)
+	conn, err := sql.NewConn(logger, cfg)
+	if err != nil {
+		return err
+	}
+	migrator, err := sql.NewMigrator(conn, logger)
+	if err != nil {
+		return err
+	}
+
+	ctx := context.Background()
+	switch {
+	case args[0] == "up" && len(args) == 1:
+		done, err := migrator.Up(ctx)
+		for _, m := range done {
+			fmt.Fprintf(out, "applied %d_%s\n", m.Version, m.Name)
+		}
+		if err == nil && len(done) == 0 {
+			fmt.Fprintln(out, "schema is up to date")
+		}
+		return err
+	case args[0] == "down" && len(args) <= 2:
+		steps := 1
+		if len(args) == 2 {
+			if steps, err = strconv.Atoi(args[1]); err != nil || steps < 1 {
+				return errors.New(migrateUsage)
+			}
+		}
+		done, err := migrator.Down(ctx, steps)
+		for _, m := range done {
+			fmt.Fprintf(out, "reverted %d_%s\n", m.Version, m.Name)
+		}
+		return err
+	case args[0] == "status" && len(args) == 1:
+		statuses, err := migrator.Status(ctx)
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(out, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED AT")
+		for _, s := range statuses {
+			appliedAt := "pending"
+			if s.AppliedAt != nil {
+				appliedAt = s.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Fprintf(w, "%d\t%s\t%s\n", s.Version, s.Name, appliedAt)
+		}
+		return w.Flush()
+	default:
+		return errors.New(migrateUsage)
+	}
+}
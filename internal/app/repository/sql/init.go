@@ -3,16 +3,14 @@ package sql
 import (
 	"errors"
 	"fmt"
-	"log"
 
+	"github.com/glebarez/sqlite"
 	"go.uber.org/zap"
 	"gorm.io/driver/postgres"
 	_ "gorm.io/driver/postgres"
 	"gorm.io/gorm"
 
 	"github.com/silverspase/todo/internal/config"
-	model2 "github.com/silverspase/todo/internal/modules/auth/model"
-	"github.com/silverspase/todo/internal/modules/todo/model"
 )
 
 const (
@@ -20,7 +18,12 @@ const (
 	PORT = 5432
 )
 
+// NewConn connects to the database, the schema is managed by the Migrator.
 func NewConn(logger *zap.Logger, cfg config.Config) (*gorm.DB, error) {
+	if cfg.Repository == config.SQLiteRepo {
+		return newSQLiteConn(logger, cfg)
+	}
+
 	if cfg.Username == "" {
 		return nil, errors.New("env var POSTGRES_USER not set")
 	}
@@ -42,15 +45,33 @@ func NewConn(logger *zap.Logger, cfg config.Config) (*gorm.DB, error) {
 		return nil, err
 	}
 
-	logger.Info("Migrating", zap.String("model", "Item"))
+	logger.Info("database connection established", zap.String("dialect", conn.Dialector.Name()))
+
+	return conn, nil
+}
+
+func newSQLiteConn(logger *zap.Logger, cfg config.Config) (*gorm.DB, error) {
+	if cfg.SQLitePath == "" {
+		return nil, errors.New("env var SQLITE_PATH not set")
+	}
+
+	// WAL lets the readers go on while a write is in progress
+	dsn := cfg.SQLitePath + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)&_pragma=foreign_keys(1)"
+	conn, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{})
+	if err != nil {
+		logger.Error("failed to open the sqlite database", zap.String("path", cfg.SQLitePath))
+		return nil, err
+	}
 
-	err = conn.AutoMigrate(&model.Item{}, model2.User{})
+	// SQLite has a single writer, one connection serializes the transactions
+	// instead of failing them with SQLITE_BUSY
+	db, err := conn.DB()
 	if err != nil {
-		logger.Error("Error during migrating Item struct", zap.Error(err))
 		return nil, err
 	}
+	db.SetMaxOpenConns(1)
 
-	log.Println("database connection established")
+	logger.Info("database connection established", zap.String("dialect", conn.Dialector.Name()))
 
 	return conn, nil
 }
@@ -0,0 +1,265 @@
+package sql
+
+import (
+	"context"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+)
+
+// migrations/<dialect>/<version>_<name>.up.sql with a matching .down.sql
+//
+//go:embed migrations
+var migrationFiles embed.FS
+
+const migrationsTable = "schema_migrations"
+
+var (
+	ErrSchemaBehind     = errors.New("database schema is behind")
+	ErrInvalidMigration = errors.New("invalid migration")
+)
+
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+type MigrationStatus struct {
+	Migration
+	// AppliedAt is nil for the pending migrations
+	AppliedAt *time.Time
+}
+
+type appliedMigration struct {
+	Version   int64 `gorm:"primaryKey"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (appliedMigration) TableName() string {
+	return migrationsTable
+}
+
+// Migrator applies the embedded migrations of the connection's dialect in version order.
+// Every migration runs in its own transaction together with its schema_migrations row.
+type Migrator struct {
+	conn       *gorm.DB
+	logger     *zap.Logger
+	migrations []Migration
+}
+
+func NewMigrator(conn *gorm.DB, logger *zap.Logger) (*Migrator, error) {
+	migrations, err := loadMigrations(conn.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	return &Migrator{conn: conn, logger: logger, migrations: migrations}, nil
+}
+
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("%w: no migrations for %s", ErrInvalidMigration, dialect)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		base := strings.TrimSuffix(entry.Name(), ".sql")
+		direction := path.Ext(base)
+		base = strings.TrimSuffix(base, direction)
+
+		sep := strings.IndexByte(base, '_')
+		if sep < 0 || (direction != ".up" && direction != ".down") {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMigration, entry.Name())
+		}
+		version, err := strconv.ParseInt(base[:sep], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("%w: %s", ErrInvalidMigration, entry.Name())
+		}
+
+		content, err := migrationFiles.ReadFile(path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: base[sep+1:]}
+			byVersion[version] = m
+		} else if m.Name != base[sep+1:] {
+			return nil, fmt.Errorf("%w: version %d is used twice", ErrInvalidMigration, version)
+		}
+		if direction == ".up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("%w: %d_%s needs both an up and a down file", ErrInvalidMigration, m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+
+	return migrations, nil
+}
+
+// Up applies all the pending migrations and returns them.
+func (m *Migrator) Up(ctx context.Context) ([]Migration, error) {
+	err := m.conn.WithContext(ctx).Exec(`CREATE TABLE IF NOT EXISTS ` + migrationsTable + ` (
+		version BIGINT PRIMARY KEY,
+		name TEXT NOT NULL,
+		applied_at TIMESTAMP NOT NULL
+	)`).Error
+	if err != nil {
+		return nil, err
+	}
+
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		m.logger.Info("Applying migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+		err = m.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Up).Error; err != nil {
+				return err
+			}
+			return tx.Create(&appliedMigration{
+				Version:   migration.Version,
+				Name:      migration.Name,
+				AppliedAt: time.Now().UTC(),
+			}).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Down reverts the latest steps applied migrations and returns them, latest first.
+func (m *Migrator) Down(ctx context.Context, steps int) ([]Migration, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+
+		m.logger.Info("Reverting migration", zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+		err = m.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.Down).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&appliedMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Status lists the known migrations in version order.
+func (m *Migrator) Status(ctx context.Context) ([]MigrationStatus, error) {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]MigrationStatus, 0, len(m.migrations))
+	for _, migration := range m.migrations {
+		status := MigrationStatus{Migration: migration}
+		if row, ok := applied[migration.Version]; ok {
+			appliedAt := row.AppliedAt
+			status.AppliedAt = &appliedAt
+		}
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+// Check returns ErrSchemaBehind when some of the migrations are not applied.
+// A schema ahead of the binary, e.g. after a rollback of the deployment, is only logged.
+func (m *Migrator) Check(ctx context.Context) error {
+	applied, err := m.applied(ctx)
+	if err != nil {
+		return err
+	}
+
+	var pending []string
+	known := make(map[int64]bool, len(m.migrations))
+	for _, migration := range m.migrations {
+		known[migration.Version] = true
+		if _, ok := applied[migration.Version]; !ok {
+			pending = append(pending, fmt.Sprintf("%d_%s", migration.Version, migration.Name))
+		}
+	}
+	if len(pending) > 0 {
+		return fmt.Errorf("%w: pending migrations %s, run `todo migrate up`", ErrSchemaBehind, strings.Join(pending, ", "))
+	}
+
+	for version, row := range applied {
+		if !known[version] {
+			m.logger.Warn("Database has a migration unknown to this build",
+				zap.Int64("version", version), zap.String("name", row.Name))
+		}
+	}
+
+	return nil
+}
+
+// applied reads the applied migrations, none when the table is missing. It doesn't write,
+// so Check and Status work with a read-only user, Up creates the table.
+func (m *Migrator) applied(ctx context.Context) (map[int64]appliedMigration, error) {
+	conn := m.conn.WithContext(ctx)
+	if !conn.Migrator().HasTable(migrationsTable) {
+		return map[int64]appliedMigration{}, nil
+	}
+
+	var rows []appliedMigration
+	if err := conn.Order("version").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	applied := make(map[int64]appliedMigration, len(rows))
+	for _, row := range rows {
+		applied[row.Version] = row
+	}
+
+	return applied, nil
+}
@@ -0,0 +1,8 @@
+DROP TABLE IF EXISTS "webhook_deliveries";
+DROP TABLE IF EXISTS "webhooks";
+DROP TABLE IF EXISTS "sessions";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "lists";
+DROP TABLE IF EXISTS "item_tags";
+DROP TABLE IF EXISTS "tags";
+DROP TABLE IF EXISTS "items";
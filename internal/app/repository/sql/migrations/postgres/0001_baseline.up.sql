@@ -0,0 +1,108 @@
+-- The schema as gorm AutoMigrate left it, databases created before the migrations are adopted as is.
+CREATE TABLE IF NOT EXISTS "items" (
+    "id" text,
+    "owner_id" text,
+    "list_id" text,
+    "parent_id" text,
+    "title" text,
+    "description" text,
+    "completed" boolean,
+    "completed_at" timestamptz,
+    "due_at" timestamptz,
+    "priority" bigint,
+    "recurrence" text,
+    "series_start" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_items_owner_id" ON "items" ("owner_id");
+CREATE INDEX IF NOT EXISTS "idx_items_list_id" ON "items" ("list_id");
+CREATE INDEX IF NOT EXISTS "idx_items_parent_id" ON "items" ("parent_id");
+
+CREATE TABLE IF NOT EXISTS "tags" (
+    "id" text,
+    "owner_id" text,
+    "name" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_tags_owner_name" ON "tags" ("owner_id", "name");
+
+CREATE TABLE IF NOT EXISTS "item_tags" (
+    "item_id" text,
+    "tag_id" text,
+    PRIMARY KEY ("item_id", "tag_id")
+);
+CREATE INDEX IF NOT EXISTS "idx_item_tags_tag_id" ON "item_tags" ("tag_id");
+
+CREATE TABLE IF NOT EXISTS "lists" (
+    "id" text,
+    "owner_id" text,
+    "name" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_lists_owner_id" ON "lists" ("owner_id");
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" text,
+    "name" text,
+    "email" varchar(100),
+    "gender" text,
+    "password" text,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "sessions" (
+    "token_hash" text,
+    "user_id" text,
+    "expires_at" timestamptz,
+    "created_at" timestamptz,
+    PRIMARY KEY ("token_hash")
+);
+CREATE INDEX IF NOT EXISTS "idx_sessions_user_id" ON "sessions" ("user_id");
+
+CREATE TABLE IF NOT EXISTS "webhooks" (
+    "id" text,
+    "owner_id" text,
+    "url" text,
+    "secret" text,
+    "events" text,
+    "disabled" boolean,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    "deleted_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhooks_owner_id" ON "webhooks" ("owner_id");
+
+CREATE TABLE IF NOT EXISTS "webhook_deliveries" (
+    "id" text,
+    "webhook_id" text,
+    "owner_id" text,
+    "event" text,
+    "payload" bytea,
+    "status" text,
+    "attempts" bigint,
+    "next_attempt_at" timestamptz,
+    "response_status" bigint,
+    "last_error" text,
+    "replay_of" text,
+    "lease_token" text,
+    "lease_until" timestamptz,
+    "created_at" timestamptz,
+    "updated_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_webhook_id" ON "webhook_deliveries" ("webhook_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_owner_id" ON "webhook_deliveries" ("owner_id");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_status" ON "webhook_deliveries" ("status");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_next_attempt_at" ON "webhook_deliveries" ("next_attempt_at");
+CREATE INDEX IF NOT EXISTS "idx_webhook_deliveries_lease_token" ON "webhook_deliveries" ("lease_token");
@@ -0,0 +1,3 @@
+-- The version counters of the optimistic concurrency control, every update increments them.
+ALTER TABLE "items" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
+ALTER TABLE "users" ADD COLUMN "version" bigint NOT NULL DEFAULT 1;
@@ -0,0 +1,12 @@
+-- The history of the items, a row for every change, written in the transaction of the change.
+CREATE TABLE "item_revisions" (
+    "item_id" text,
+    "rev" bigint,
+    "owner_id" text,
+    "action" text,
+    "actor_id" text,
+    "changes" text,
+    "item" bytea,
+    "created_at" timestamptz,
+    PRIMARY KEY ("item_id", "rev")
+);
@@ -0,0 +1,16 @@
+-- The security audit log of the auth module, rows are only ever inserted.
+CREATE TABLE "audit_log" (
+    "id" text,
+    "actor_id" text,
+    "target_id" text,
+    "action" text,
+    "email" text,
+    "ip" text,
+    "user_agent" text,
+    "outcome" text,
+    "reason" text,
+    "created_at" timestamptz,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_audit_log_actor_id" ON "audit_log" ("actor_id");
+CREATE INDEX "idx_audit_log_created_at" ON "audit_log" ("created_at");
@@ -0,0 +1,4 @@
+-- The roles of the users, the existing ones become members. The first admin is created
+-- on an empty database, see BOOTSTRAP_ADMIN_EMAIL.
+ALTER TABLE "users" ADD COLUMN "role" text NOT NULL DEFAULT 'member';
+ALTER TABLE "audit_log" ADD COLUMN "role" text;
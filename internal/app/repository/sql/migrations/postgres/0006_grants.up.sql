@@ -0,0 +1,11 @@
+-- The items and the lists shared with other users: a row per user and item, or user and list,
+-- the other ID is empty.
+CREATE TABLE "grants" (
+    "item_id" text NOT NULL DEFAULT '',
+    "list_id" text NOT NULL DEFAULT '',
+    "user_id" text NOT NULL,
+    "access" text NOT NULL,
+    "created_at" timestamptz,
+    PRIMARY KEY ("item_id", "list_id", "user_id")
+);
+CREATE INDEX "idx_grants_user_id" ON "grants" ("user_id");
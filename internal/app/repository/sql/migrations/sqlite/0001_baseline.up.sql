@@ -0,0 +1,108 @@
+-- The same schema as the postgres baseline, in the column types of the sqlite gorm dialect.
+CREATE TABLE "items" (
+    "id" text,
+    "owner_id" text,
+    "list_id" text,
+    "parent_id" text,
+    "title" text,
+    "description" text,
+    "completed" numeric,
+    "completed_at" datetime,
+    "due_at" datetime,
+    "priority" integer,
+    "recurrence" text,
+    "series_start" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_items_owner_id" ON "items" ("owner_id");
+CREATE INDEX "idx_items_list_id" ON "items" ("list_id");
+CREATE INDEX "idx_items_parent_id" ON "items" ("parent_id");
+
+CREATE TABLE "tags" (
+    "id" text,
+    "owner_id" text,
+    "name" text,
+    "created_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX "idx_tags_owner_name" ON "tags" ("owner_id", "name");
+
+CREATE TABLE "item_tags" (
+    "item_id" text,
+    "tag_id" text,
+    PRIMARY KEY ("item_id", "tag_id")
+);
+CREATE INDEX "idx_item_tags_tag_id" ON "item_tags" ("tag_id");
+
+CREATE TABLE "lists" (
+    "id" text,
+    "owner_id" text,
+    "name" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_lists_owner_id" ON "lists" ("owner_id");
+
+CREATE TABLE "users" (
+    "id" text,
+    "name" text,
+    "email" varchar(100),
+    "gender" text,
+    "password" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE "sessions" (
+    "token_hash" text,
+    "user_id" text,
+    "expires_at" datetime,
+    "created_at" datetime,
+    PRIMARY KEY ("token_hash")
+);
+CREATE INDEX "idx_sessions_user_id" ON "sessions" ("user_id");
+
+CREATE TABLE "webhooks" (
+    "id" text,
+    "owner_id" text,
+    "url" text,
+    "secret" text,
+    "events" text,
+    "disabled" numeric,
+    "created_at" datetime,
+    "updated_at" datetime,
+    "deleted_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_webhooks_owner_id" ON "webhooks" ("owner_id");
+
+CREATE TABLE "webhook_deliveries" (
+    "id" text,
+    "webhook_id" text,
+    "owner_id" text,
+    "event" text,
+    "payload" blob,
+    "status" text,
+    "attempts" integer,
+    "next_attempt_at" datetime,
+    "response_status" integer,
+    "last_error" text,
+    "replay_of" text,
+    "lease_token" text,
+    "lease_until" datetime,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+CREATE INDEX "idx_webhook_deliveries_webhook_id" ON "webhook_deliveries" ("webhook_id");
+CREATE INDEX "idx_webhook_deliveries_owner_id" ON "webhook_deliveries" ("owner_id");
+CREATE INDEX "idx_webhook_deliveries_status" ON "webhook_deliveries" ("status");
+CREATE INDEX "idx_webhook_deliveries_next_attempt_at" ON "webhook_deliveries" ("next_attempt_at");
+CREATE INDEX "idx_webhook_deliveries_lease_token" ON "webhook_deliveries" ("lease_token");
@@ -0,0 +1,5 @@
+-- The emails are matched case-insensitively, so they are stored in lower case and unique among
+-- the users not deleted. Users signed up with the same email in different cases must be merged
+-- before migrating.
+UPDATE "users" SET "email" = lower("email");
+CREATE UNIQUE INDEX "idx_users_email" ON "users" (lower("email")) WHERE "deleted_at" IS NULL;
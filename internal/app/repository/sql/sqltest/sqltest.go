@@ -0,0 +1,117 @@
+// Package sqltest provides migrated databases to the tests of the gorm repositories.
+package sqltest
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+
+	"github.com/silverspase/todo/internal/app/repository/sql"
+	"github.com/silverspase/todo/internal/config"
+)
+
+// PostgresEnv holds the DSN of a postgres database for the tests, e.g.
+// "host=localhost user=todo password=todo dbname=todo_test sslmode=disable".
+// Every test works in its own schema, which is dropped afterwards.
+const PostgresEnv = "TEST_POSTGRES_DSN"
+
+// ciEnv is set by the CI services, which must run the postgres tests.
+const ciEnv = "CI"
+
+type Database struct {
+	Name string
+	// Open returns a connection to an empty database with all the migrations applied.
+	Open func(t *testing.T) *gorm.DB
+}
+
+// Databases lists the databases of the tests: sqlite, and postgres at the DSN of PostgresEnv.
+// Without the DSN the postgres tests are skipped, or fail when the CI variable is set.
+func Databases() []Database {
+	dsn := os.Getenv(PostgresEnv)
+
+	return []Database{
+		{Name: "sqlite", Open: openSQLite},
+		{Name: "postgres", Open: func(t *testing.T) *gorm.DB { return openPostgres(t, dsn) }},
+	}
+}
+
+func openSQLite(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	conn, err := sql.NewConn(zap.NewNop(), config.Config{
+		Repository: config.SQLiteRepo,
+		SQLitePath: filepath.Join(t.TempDir(), "todo.db"),
+	})
+	if err != nil {
+		t.Fatalf("open sqlite: %v", err)
+	}
+	conn.Logger = logger.Discard
+	t.Cleanup(func() {
+		if db, err := conn.DB(); err == nil {
+			db.Close()
+		}
+	})
+
+	migrate(t, conn)
+
+	return conn
+}
+
+func openPostgres(t *testing.T, dsn string) *gorm.DB {
+	t.Helper()
+
+	if dsn == "" {
+		if os.Getenv(ciEnv) != "" {
+			t.Fatalf("%s is required in CI", PostgresEnv)
+		}
+		t.Skipf("%s is not set", PostgresEnv)
+	}
+
+	schema := "test_" + strings.ReplaceAll(uuid.New().String(), "-", "")
+	admin, err := gorm.Open(postgres.Open(dsn), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	if err = admin.Exec(fmt.Sprintf(`CREATE SCHEMA "%s"`, schema)).Error; err != nil {
+		t.Fatalf("create schema: %v", err)
+	}
+
+	conn, err := gorm.Open(postgres.Open(dsn+" search_path="+schema), &gorm.Config{Logger: logger.Discard})
+	if err != nil {
+		t.Fatalf("open postgres: %v", err)
+	}
+	t.Cleanup(func() {
+		if db, err := conn.DB(); err == nil {
+			db.Close()
+		}
+		admin.Exec(fmt.Sprintf(`DROP SCHEMA "%s" CASCADE`, schema))
+		if db, err := admin.DB(); err == nil {
+			db.Close()
+		}
+	})
+
+	migrate(t, conn)
+
+	return conn
+}
+
+func migrate(t *testing.T, conn *gorm.DB) {
+	t.Helper()
+
+	migrator, err := sql.NewMigrator(conn, zap.NewNop())
+	if err != nil {
+		t.Fatalf("migrations: %v", err)
+	}
+	if _, err = migrator.Up(context.Background()); err != nil {
+		t.Fatalf("migrate up: %v", err)
+	}
+}
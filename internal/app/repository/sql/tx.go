@@ -0,0 +1,38 @@
+package sql
+
+import (
+	"context"
+
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/txn"
+)
+
+// Transaction runs fn in a transaction of conn, the repositories given the context of fn
+// take part in it through Conn. Within a transaction already, fn joins it.
+func Transaction(ctx context.Context, conn *gorm.DB, fn func(ctx context.Context) error) error {
+	if _, ok := txn.Conn(ctx).(*gorm.DB); ok {
+		return fn(ctx)
+	}
+
+	var txCtx context.Context
+	err := conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		txCtx = txn.Begin(ctx, tx)
+		return fn(txCtx)
+	})
+	if err != nil {
+		return err
+	}
+	txn.Committed(txCtx)
+
+	return nil
+}
+
+// Conn returns the transaction of ctx, or conn outside of one.
+func Conn(ctx context.Context, conn *gorm.DB) *gorm.DB {
+	if tx, ok := txn.Conn(ctx).(*gorm.DB); ok {
+		return tx
+	}
+
+	return conn.WithContext(ctx)
+}
@@ -4,29 +4,101 @@ import (
 	"net/http"
 
 	"github.com/gorilla/mux"
+	"google.golang.org/grpc"
 
+	"github.com/silverspase/todo/internal/httpjson"
+	authPb "github.com/silverspase/todo/internal/modules/auth/transport/grpc/pb"
 	meta "github.com/silverspase/todo/internal/modules/metadata/transport/gorilla-mux"
+	todoPb "github.com/silverspase/todo/internal/modules/todo/transport/grpc/pb"
 )
 
 // TODO move router init to separate package (resolve cycle import issue)
 func gorillaMuxRouter(t *App) http.Handler {
 	r := mux.NewRouter().StrictSlash(true)
+	r.Use(httpjson.LimitBody(t.Cfg.MaxBodyBytes))
+	r.Use(t.Auth.Client)
 	r.HandleFunc("/health", meta.HealthCheck)
 	// r.HandleFunc("/readiness", meta.Readiness(s.isReady))
 
+	// registered before /todo/{id}, which would match them otherwise
+	r.Handle("/todo/events", t.Auth.AuthenticateStream(http.HandlerFunc(t.Todo.Events))).Methods(http.MethodGet)
+	r.Handle("/todo/events/ws", t.Auth.AuthenticateStream(http.HandlerFunc(t.Todo.EventsWebSocket))).
+		Methods(http.MethodGet)
+
 	todo := r.PathPrefix("/todo").Subrouter()
+	todo.Use(t.Auth.Authenticate)
 	todo.Path("/").HandlerFunc(t.Todo.CreateItem).Methods(http.MethodPost)
 	todo.Path("/").HandlerFunc(t.Todo.GetAllItems).Methods(http.MethodGet)
+	// registered before /{id}, which would match it otherwise
+	todo.Path("/trash").HandlerFunc(t.Todo.GetTrash).Methods(http.MethodGet)
+	todo.Path("/trash/{id}").HandlerFunc(t.Todo.PurgeItem).Methods(http.MethodDelete)
 	todo.Path("/{id}").HandlerFunc(t.Todo.GetItem).Methods(http.MethodGet)
 	todo.Path("/{id}").HandlerFunc(t.Todo.UpdateItem).Methods(http.MethodPut)
+	todo.Path("/{id}").HandlerFunc(t.Todo.PatchItem).Methods(http.MethodPatch)
 	todo.Path("/{id}").HandlerFunc(t.Todo.DeleteItem).Methods(http.MethodDelete)
+	todo.Path("/{id}/tree").HandlerFunc(t.Todo.GetItemTree).Methods(http.MethodGet)
+	todo.Path("/{id}/move").HandlerFunc(t.Todo.MoveItem).Methods(http.MethodPost)
+	todo.Path("/{id}/restore").HandlerFunc(t.Todo.RestoreItem).Methods(http.MethodPost)
+	todo.Path("/{id}/history").HandlerFunc(t.Todo.GetHistory).Methods(http.MethodGet)
+	todo.Path("/{id}/history/{rev}").HandlerFunc(t.Todo.GetRevision).Methods(http.MethodGet)
+	todo.Path("/{id}/revert").HandlerFunc(t.Todo.RevertItem).Methods(http.MethodPost)
+	todo.Path("/{id}/occurrences").HandlerFunc(t.Todo.GetOccurrences).Methods(http.MethodGet)
+	todo.Path("/{id}/grants").HandlerFunc(t.Todo.GetItemGrants).Methods(http.MethodGet)
+	todo.Path("/{id}/grants/{user_id}").HandlerFunc(t.Todo.ShareItem).Methods(http.MethodPut)
+	todo.Path("/{id}/grants/{user_id}").HandlerFunc(t.Todo.UnshareItem).Methods(http.MethodDelete)
+
+	tags := r.PathPrefix("/tags").Subrouter()
+	tags.Use(t.Auth.Authenticate)
+	tags.Path("/").HandlerFunc(t.Todo.GetAllTags).Methods(http.MethodGet)
+	tags.Path("/{id}").HandlerFunc(t.Todo.RenameTag).Methods(http.MethodPut)
+
+	lists := r.PathPrefix("/lists").Subrouter()
+	lists.Use(t.Auth.Authenticate)
+	lists.Path("/").HandlerFunc(t.List.CreateList).Methods(http.MethodPost)
+	lists.Path("/").HandlerFunc(t.List.GetAllLists).Methods(http.MethodGet)
+	lists.Path("/{id}").HandlerFunc(t.List.GetList).Methods(http.MethodGet)
+	lists.Path("/{id}").HandlerFunc(t.List.UpdateList).Methods(http.MethodPut)
+	lists.Path("/{id}").HandlerFunc(t.List.DeleteList).Methods(http.MethodDelete)
+	lists.Path("/{id}/grants").HandlerFunc(t.List.GetListGrants).Methods(http.MethodGet)
+	lists.Path("/{id}/grants/{user_id}").HandlerFunc(t.List.ShareList).Methods(http.MethodPut)
+	lists.Path("/{id}/grants/{user_id}").HandlerFunc(t.List.UnshareList).Methods(http.MethodDelete)
+
+	webhooks := r.PathPrefix("/webhooks").Subrouter()
+	webhooks.Use(t.Auth.Authenticate)
+	webhooks.Path("/").HandlerFunc(t.Webhook.CreateWebhook).Methods(http.MethodPost)
+	webhooks.Path("/").HandlerFunc(t.Webhook.GetAllWebhooks).Methods(http.MethodGet)
+	webhooks.Path("/{id}").HandlerFunc(t.Webhook.GetWebhook).Methods(http.MethodGet)
+	webhooks.Path("/{id}").HandlerFunc(t.Webhook.UpdateWebhook).Methods(http.MethodPut)
+	webhooks.Path("/{id}").HandlerFunc(t.Webhook.DeleteWebhook).Methods(http.MethodDelete)
+	webhooks.Path("/{id}/deliveries").HandlerFunc(t.Webhook.GetDeliveries).Methods(http.MethodGet)
+	webhooks.Path("/{id}/deliveries/{delivery_id}/replay").HandlerFunc(t.Webhook.ReplayDelivery).Methods(http.MethodPost)
+
+	r.Handle("/graphql", t.Auth.Authenticate(t.GraphQL)).Methods(http.MethodPost)
+
+	admin := r.PathPrefix("/admin").Subrouter()
+	admin.Use(t.Auth.Authenticate)
+	admin.Path("/audit").HandlerFunc(t.Auth.GetAudit).Methods(http.MethodGet)
 
 	user := r.PathPrefix("/user").Subrouter()
+	// signing up and logging in are anonymous, the use case authorizes the rest
+	user.Use(t.Auth.Identify)
 	user.Path("/").HandlerFunc(t.Auth.CreateUser).Methods(http.MethodPost)
 	user.Path("/").HandlerFunc(t.Auth.GetAllUsers).Methods(http.MethodGet)
+	user.Path("/login").HandlerFunc(t.Auth.Login).Methods(http.MethodPost)
+	user.Path("/logout").HandlerFunc(t.Auth.Logout).Methods(http.MethodPost)
 	user.Path("/{id}").HandlerFunc(t.Auth.GetUser).Methods(http.MethodGet)
 	user.Path("/{id}").HandlerFunc(t.Auth.UpdateUser).Methods(http.MethodPut)
+	user.Path("/{id}").HandlerFunc(t.Auth.PatchUser).Methods(http.MethodPatch)
 	user.Path("/{id}").HandlerFunc(t.Auth.DeleteUser).Methods(http.MethodDelete)
+	user.Path("/{id}/role").HandlerFunc(t.Auth.SetRole).Methods(http.MethodPut)
 
 	return r
 }
+
+func grpcServer(t *App, interceptors ...grpc.UnaryServerInterceptor) *grpc.Server {
+	srv := grpc.NewServer(grpc.ChainUnaryInterceptor(interceptors...))
+	todoPb.RegisterTodoServiceServer(srv, t.TodoGRPC)
+	authPb.RegisterUserServiceServer(srv, t.AuthGRPC)
+
+	return srv
+}
@@ -0,0 +1,308 @@
+package app
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+
+	"github.com/silverspase/todo/internal/etag"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/modules/todo/transport/grpc/pb"
+)
+
+// items is the CRUD of the items through a transport. The errors are *statusError.
+type items interface {
+	create(item model.Item) (string, error)
+	get(id string) (model.Item, error)
+	// update and delete require the version when it's non-zero
+	update(item model.Item) error
+	delete(id string, version int64) error
+}
+
+// statusError is the HTTP status of a failed call, the gRPC codes are mapped to it.
+type statusError struct {
+	status int
+}
+
+func (e *statusError) Error() string {
+	return http.StatusText(e.status)
+}
+
+// outcome is the part of a call's result both transports return alike.
+type outcome struct {
+	Title     string
+	Completed bool
+	Priority  model.Priority
+	Tags      []string
+	Version   int64
+	Status    int
+}
+
+func TestTransportsParity(t *testing.T) {
+	t.Setenv("REPOSITORY", "memory")
+	t.Setenv("LOG_LEVEL", "ERROR")
+	t.Setenv("BOOTSTRAP_ADMIN_EMAIL", "")
+	application, err := Init()
+	if err != nil {
+		t.Fatalf("Init: %v", err)
+	}
+
+	srv := httptest.NewServer(application.Srv.Handler)
+	defer srv.Close()
+
+	lis := bufconn.Listen(1 << 20)
+	go application.GRPCSrv.Serve(lis)
+	defer application.GRPCSrv.Stop()
+	conn, err := grpc.DialContext(context.Background(), "bufnet", grpc.WithInsecure(),
+		grpc.WithContextDialer(func(context.Context, string) (net.Conn, error) { return lis.Dial() }))
+	if err != nil {
+		t.Fatalf("Dial: %v", err)
+	}
+	defer conn.Close()
+
+	client := &httpClient{t: t, url: srv.URL}
+	token := client.logIn("ann@example.com")
+	var list struct {
+		ID string `json:"id"`
+	}
+	if err = client.do(http.MethodPost, "/lists/", 0, map[string]string{"name": "Groceries"}, &list); err != nil {
+		t.Fatalf("create the list: %v", err)
+	}
+
+	transports := map[string]items{
+		"http": client,
+		"grpc": &grpcClient{
+			client: pb.NewTodoServiceClient(conn),
+			ctx:    metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token),
+		},
+	}
+	results := make(map[string][]outcome)
+	for name, c := range transports {
+		results[name] = crud(t, c, list.ID)
+	}
+
+	want := []outcome{
+		{Title: "Milk", Priority: model.PriorityHigh, Tags: []string{"shop"}, Version: 1},
+		{Status: http.StatusPreconditionFailed},
+		{Title: "Oat milk", Completed: true, Priority: model.PriorityHigh, Tags: []string{"shop"}, Version: 2},
+		{Status: http.StatusPreconditionFailed},
+		{Status: http.StatusNotFound},
+	}
+	for name, got := range results {
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %+v, want %+v", name, got, want)
+		}
+	}
+}
+
+// crud creates, updates and deletes an item in the list, it returns the outcome of each step.
+func crud(t *testing.T, c items, listID string) []outcome {
+	t.Helper()
+
+	var res []outcome
+	record := func(item model.Item, err error) {
+		var failed *statusError
+		switch {
+		case err == nil:
+			res = append(res, outcome{Title: item.Title, Completed: item.Completed, Priority: item.Priority,
+				Tags: item.Tags, Version: item.Version})
+		case errors.As(err, &failed):
+			res = append(res, outcome{Status: failed.status})
+		default:
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	id, err := c.create(model.Item{Title: "Milk", ListID: listID, Priority: model.PriorityHigh, Tags: []string{"shop"}})
+	if err != nil {
+		t.Fatalf("create: %v", err)
+	}
+	record(c.get(id))
+
+	stale := model.Item{ID: id, Title: "Oat milk", Priority: model.PriorityHigh, Tags: []string{"shop"}, Completed: true,
+		Version: 1}
+	if err = c.update(stale); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+	record(model.Item{}, c.update(stale))
+	record(c.get(id))
+
+	record(model.Item{}, c.delete(id, 1))
+	if err = c.delete(id, 2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	record(c.get(id))
+
+	return res
+}
+
+type httpClient struct {
+	t     *testing.T
+	url   string
+	token string
+}
+
+// logIn signs the user up and authenticates the next calls as them.
+func (c *httpClient) logIn(email string) string {
+	c.t.Helper()
+
+	credentials := map[string]string{"email": email, "password": "password1"}
+	user := map[string]string{"name": "Ann", "email": email, "password": "password1"}
+	if err := c.do(http.MethodPost, "/user/", 0, user, nil); err != nil {
+		c.t.Fatalf("sign up: %v", err)
+	}
+	var token struct {
+		AccessToken string `json:"access_token"`
+	}
+	if err := c.do(http.MethodPost, "/user/login", 0, credentials, &token); err != nil {
+		c.t.Fatalf("log in: %v", err)
+	}
+	c.token = token.AccessToken
+
+	return c.token
+}
+
+// do sends body as JSON and decodes the response into res, version goes in If-Match.
+func (c *httpClient) do(method, path string, version int64, body, res interface{}) error {
+	c.t.Helper()
+
+	var payload bytes.Buffer
+	if body != nil {
+		if err := json.NewEncoder(&payload).Encode(body); err != nil {
+			c.t.Fatalf("encode: %v", err)
+		}
+	}
+	req, err := http.NewRequest(method, c.url+path, &payload)
+	if err != nil {
+		c.t.Fatalf("NewRequest: %v", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if version != 0 {
+		req.Header.Set("If-Match", etag.Format(version))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		c.t.Fatalf("%s %s: %v", method, path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= http.StatusBadRequest {
+		return &statusError{status: resp.StatusCode}
+	}
+	if res != nil {
+		if err = json.NewDecoder(resp.Body).Decode(res); err != nil {
+			c.t.Fatalf("decode the response of %s %s: %v", method, path, err)
+		}
+	}
+
+	return nil
+}
+
+func (c *httpClient) create(item model.Item) (string, error) {
+	var res struct {
+		ID string `json:"id"`
+	}
+	err := c.do(http.MethodPost, "/todo/", 0, item, &res)
+
+	return res.ID, err
+}
+
+func (c *httpClient) get(id string) (model.Item, error) {
+	var item model.Item
+	err := c.do(http.MethodGet, "/todo/"+id, 0, nil, &item)
+
+	return item, err
+}
+
+func (c *httpClient) update(item model.Item) error {
+	return c.do(http.MethodPut, "/todo/"+item.ID, item.Version, item, nil)
+}
+
+func (c *httpClient) delete(id string, version int64) error {
+	return c.do(http.MethodDelete, "/todo/"+id, version, nil, nil)
+}
+
+type grpcClient struct {
+	client pb.TodoServiceClient
+	ctx    context.Context
+}
+
+// httpStatuses are the statuses of the gRPC codes the scenario meets.
+var httpStatuses = map[codes.Code]int{
+	codes.NotFound:        http.StatusNotFound,
+	codes.Aborted:         http.StatusPreconditionFailed,
+	codes.InvalidArgument: http.StatusBadRequest,
+}
+
+func grpcError(err error) error {
+	if err == nil {
+		return nil
+	}
+	code, ok := httpStatuses[status.Code(err)]
+	if !ok {
+		return fmt.Errorf("unexpected status: %w", err)
+	}
+
+	return &statusError{status: code}
+}
+
+func (c *grpcClient) create(item model.Item) (string, error) {
+	res, err := c.client.CreateItem(c.ctx, &pb.CreateItemRequest{Item: &pb.Item{
+		ListId:   item.ListID,
+		Title:    item.Title,
+		Priority: pb.Priority(item.Priority),
+		Tags:     item.Tags,
+	}})
+
+	return res.GetId(), grpcError(err)
+}
+
+func (c *grpcClient) get(id string) (model.Item, error) {
+	res, err := c.client.GetItem(c.ctx, &pb.ItemID{Id: id})
+	if err != nil {
+		return model.Item{}, grpcError(err)
+	}
+
+	return model.Item{
+		Title:     res.GetTitle(),
+		Completed: res.GetCompleted(),
+		Priority:  model.Priority(res.GetPriority()),
+		Tags:      res.GetTags(),
+		Version:   res.GetVersion(),
+	}, nil
+}
+
+func (c *grpcClient) update(item model.Item) error {
+	_, err := c.client.UpdateItem(c.ctx, &pb.UpdateItemRequest{Item: &pb.Item{
+		Id:        item.ID,
+		Title:     item.Title,
+		Completed: item.Completed,
+		Priority:  pb.Priority(item.Priority),
+		Tags:      item.Tags,
+		Version:   item.Version,
+	}})
+
+	return grpcError(err)
+}
+
+func (c *grpcClient) delete(id string, version int64) error {
+	_, err := c.client.DeleteItem(c.ctx, &pb.DeleteItemRequest{Id: id, Version: version})
+
+	return grpcError(err)
+}
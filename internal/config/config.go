@@ -2,17 +2,40 @@ package config
 
 import (
 	"log"
+	"time"
 
 	"github.com/caarlos0/env/v6"
 )
 
 type Config struct {
 	Port       string `env:"SERVER_PORT" default:"8000"`
+	GRPCPort   string `env:"GRPC_PORT" envDefault:"9000"`
 	LogLevel   string `env:"LOG_LEVEL" default:"INFO"`
 	Repository repo   `env:"REPOSITORY" required:"true"`
 	Username   string `env:"POSTGRES_USER"`
 	Password   string `env:"POSTGRES_PASSWORD"`
 	DB         string `env:"POSTGRES_DB"`
+	SQLitePath string `env:"SQLITE_PATH" envDefault:"todo.db"`
+
+	SessionTTL time.Duration `env:"SESSION_TTL" envDefault:"24h"`
+	// While there is no admin, the user of the email is promoted, or created with these credentials,
+	// unless the email is empty.
+	BootstrapAdminName     string `env:"BOOTSTRAP_ADMIN_NAME" envDefault:"admin"`
+	BootstrapAdminEmail    string `env:"BOOTSTRAP_ADMIN_EMAIL"`
+	BootstrapAdminPassword string `env:"BOOTSTRAP_ADMIN_PASSWORD"`
+	// SubtaskCompletion is what completing an item with open subtasks does: refuse or cascade.
+	SubtaskCompletion string `env:"SUBTASK_COMPLETION" envDefault:"refuse"`
+	// A failed webhook delivery is retried after WebhookBackoff, doubled for every next
+	// failure, until WebhookMaxAttempts are made.
+	WebhookMaxAttempts int           `env:"WEBHOOK_MAX_ATTEMPTS" envDefault:"8"`
+	WebhookBackoff     time.Duration `env:"WEBHOOK_BACKOFF" envDefault:"30s"`
+	// TrashRetention is how long the deleted items are kept in the trash, 0 keeps them forever.
+	TrashRetention time.Duration `env:"TRASH_RETENTION" envDefault:"720h"`
+	// AllowedOrigins are the origins of the pages besides the service's own one which may open
+	// the WebSocket event stream, comma separated.
+	AllowedOrigins []string `env:"ALLOWED_ORIGINS" envSeparator:","`
+	// MaxBodyBytes limits the size of the HTTP request bodies.
+	MaxBodyBytes int64 `env:"MAX_BODY_BYTES" envDefault:"1048576"`
 }
 
 type repo string
@@ -20,8 +43,14 @@ type repo string
 const (
 	MemoryRepo   repo = "memory"
 	PostgresRepo repo = "postgres"
+	SQLiteRepo   repo = "sqlite"
 )
 
+// SQL tells whether the repository is kept in a SQL database.
+func (r repo) SQL() bool {
+	return r == PostgresRepo || r == SQLiteRepo
+}
+
 func Init() (cfg Config) {
 	var err error
 	if err = env.Parse(&cfg); err != nil {
@@ -0,0 +1,36 @@
+// Package cursor turns keyset pagination positions into opaque tokens.
+package cursor
+
+import (
+	"encoding/base64"
+	"encoding/json"
+
+	"github.com/silverspase/todo/internal/errs"
+)
+
+// ErrInvalid is returned for tokens which weren't produced by Encode.
+var ErrInvalid = errs.New(errs.Validation, "invalid cursor")
+
+// Encode serializes the position, it's JSON in URL-safe base64 so clients don't rely on its content.
+func Encode(position interface{}) (string, error) {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// Decode parses a token produced by Encode into position.
+func Decode(token string, position interface{}) error {
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return ErrInvalid
+	}
+
+	if err = json.Unmarshal(data, position); err != nil {
+		return ErrInvalid
+	}
+
+	return nil
+}
@@ -0,0 +1,60 @@
+// Package errs classifies the domain errors, so every transport can report them consistently.
+package errs
+
+import "errors"
+
+// Kind is the class of a domain error. It's an error itself,
+// so errors.Is(err, errs.NotFound) tells whether err is of the kind.
+type Kind string
+
+const (
+	Internal     Kind = "internal"
+	NotFound     Kind = "not_found"
+	Conflict     Kind = "conflict"
+	Validation   Kind = "validation"
+	Unauthorized Kind = "unauthorized"
+	Forbidden    Kind = "forbidden"
+	// Gone is returned for data which existed but is no longer retained.
+	Gone Kind = "gone"
+	// PreconditionFailed is returned when the data changed since the client has read it.
+	PreconditionFailed Kind = "precondition_failed"
+	// TooLarge is returned for input over the size limits.
+	TooLarge Kind = "too_large"
+	// UnsupportedMediaType is returned for input of a format the server can't read.
+	UnsupportedMediaType Kind = "unsupported_media_type"
+)
+
+func (k Kind) Error() string {
+	return string(k)
+}
+
+// Error is a domain error of a kind. The modules declare their sentinel errors with New
+// and wrap them with fmt.Errorf("%w: ...") to add the details.
+type Error struct {
+	Kind    Kind
+	Message string
+}
+
+func New(kind Kind, message string) *Error {
+	return &Error{Kind: kind, Message: message}
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Is matches the error's kind, other errors are compared by identity.
+func (e *Error) Is(target error) bool {
+	kind, ok := target.(Kind)
+	return ok && kind == e.Kind
+}
+
+// KindOf returns the kind of the first Error in err's chain, errors of no kind are Internal.
+func KindOf(err error) Kind {
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Kind
+	}
+
+	return Internal
+}
@@ -0,0 +1,63 @@
+// Package etag maps the version counters of the resources to HTTP entity tags
+// and evaluates the If-Match and If-None-Match preconditions (RFC 7232).
+package etag
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+)
+
+// Format returns the strong entity tag of the version.
+func Format(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+// IfMatch returns the version the If-Match header requires: 0 when any version will do,
+// i.e. the header is absent or "*". A header which matches no version, like a weak tag,
+// gives -1, which fails the precondition. Only a single tag is supported.
+func IfMatch(r *http.Request) int64 {
+	header := strings.TrimSpace(r.Header.Get("If-Match"))
+	if header == "" || header == "*" {
+		return 0
+	}
+
+	version, ok := parse(header)
+	if !ok {
+		return -1
+	}
+
+	return version
+}
+
+// NoneMatch tells whether the If-None-Match header holds the tag of the version, so a GET
+// can be answered with 304 Not Modified. Tags are compared weakly.
+func NoneMatch(r *http.Request, version int64) bool {
+	header := strings.TrimSpace(r.Header.Get("If-None-Match"))
+	if header == "*" {
+		return true
+	}
+
+	for _, tag := range strings.Split(header, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if v, ok := parse(tag); ok && v == version {
+			return true
+		}
+	}
+
+	return false
+}
+
+// parse reads the version of a strong tag made by Format.
+func parse(tag string) (int64, bool) {
+	if len(tag) < 2 || tag[0] != '"' || tag[len(tag)-1] != '"' {
+		return 0, false
+	}
+
+	version, err := strconv.ParseInt(tag[1:len(tag)-1], 10, 64)
+	if err != nil || version < 1 {
+		return 0, false
+	}
+
+	return version, true
+}
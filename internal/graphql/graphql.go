@@ -0,0 +1,157 @@
+// Package graphql serves the todo, list and auth use cases as a single GraphQL schema.
+package graphql
+
+import (
+	"context"
+	_ "embed"
+	"errors"
+	"net/http"
+	"time"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"github.com/graph-gophers/graphql-go/relay"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/list"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+//go:embed schema.graphql
+var schema string
+
+type resolver struct {
+	items  todo.UseCase
+	lists  list.UseCase
+	users  auth.UseCase
+	logger *zap.Logger
+}
+
+// NewHandler returns the handler of POST requests with a {"query", "operationName", "variables"} body.
+// It expects the user to be authenticated in the request context.
+func NewHandler(logger *zap.Logger, items todo.UseCase, lists list.UseCase, users auth.UseCase) http.Handler {
+	r := &resolver{
+		items:  items,
+		lists:  lists,
+		users:  users,
+		logger: logger,
+	}
+	handler := &relay.Handler{Schema: graphql.MustParseSchema(schema, r)}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		// the loader caches users for a single request only
+		ctx := withLoader(req.Context(), newUserLoader(users))
+		handler.ServeHTTP(w, req.WithContext(ctx))
+	})
+}
+
+func (r *resolver) Me(ctx context.Context) (*userResolver, error) {
+	user, ok := auth.FromContext(ctx)
+	if !ok {
+		return nil, resolverError(r.logger, auth.ErrUnauthorized)
+	}
+
+	return &userResolver{user}, nil
+}
+
+var codes = map[errs.Kind]string{
+	errs.NotFound:             "NOT_FOUND",
+	errs.Validation:           "BAD_REQUEST",
+	errs.Conflict:             "CONFLICT",
+	errs.Unauthorized:         "UNAUTHENTICATED",
+	errs.Forbidden:            "FORBIDDEN",
+	errs.Gone:                 "GONE",
+	errs.PreconditionFailed:   "PRECONDITION_FAILED",
+	errs.TooLarge:             "TOO_LARGE",
+	errs.UnsupportedMediaType: "UNSUPPORTED_MEDIA_TYPE",
+}
+
+// resolverError adds the error kind as the "code" extension, so clients don't parse messages.
+// Internal errors are logged and not detailed.
+func resolverError(logger *zap.Logger, err error) error {
+	if err == nil {
+		return nil
+	}
+
+	code, ok := codes[errs.KindOf(err)]
+	if !ok {
+		logger.Error("resolver failed", zap.Error(err))
+		return codedError{err: errors.New("internal error"), code: "INTERNAL"}
+	}
+
+	return codedError{err: err, code: code}
+}
+
+type codedError struct {
+	err  error
+	code string
+}
+
+func (e codedError) Error() string {
+	return e.err.Error()
+}
+
+func (e codedError) Unwrap() error {
+	return e.err
+}
+
+func (e codedError) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": e.code}
+	var fields *validate.Errors
+	if errors.As(e.err, &fields) {
+		extensions["fields"] = fields.Fields
+	}
+
+	return extensions
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+
+	return &s
+}
+
+func value(s *string) string {
+	if s == nil {
+		return ""
+	}
+
+	return *s
+}
+
+// version reads an optional expected version, 0 skips the check.
+func version(v *int32) int64 {
+	if v == nil {
+		return 0
+	}
+
+	return int64(*v)
+}
+
+func id(v *graphql.ID) string {
+	if v == nil {
+		return ""
+	}
+
+	return string(*v)
+}
+
+func timestamp(t *time.Time) *graphql.Time {
+	if t == nil {
+		return nil
+	}
+
+	return &graphql.Time{Time: *t}
+}
+
+func timeOf(t *graphql.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+
+	return &t.Time
+}
@@ -0,0 +1,298 @@
+package graphql
+
+import (
+	"context"
+	"strings"
+
+	graphql "github.com/graph-gophers/graphql-go"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+type itemFilter struct {
+	ListID     *graphql.ID
+	Completed  *bool
+	DueBefore  *graphql.Time
+	DueAfter   *graphql.Time
+	Priorities *[]string
+	Tags       *[]string
+	TagMode    *string
+	Search     *string
+}
+
+type itemsArgs struct {
+	Filter *itemFilter
+	Sort   *string
+	Desc   *bool
+	First  *int32
+	After  *string
+}
+
+type itemInput struct {
+	ListID      *graphql.ID
+	ParentID    *graphql.ID
+	Title       string
+	Description *string
+	Completed   *bool
+	DueAt       *graphql.Time
+	Priority    *string
+	Tags        *[]string
+	Recurrence  *string
+}
+
+func (r *resolver) Item(ctx context.Context, args struct{ ID graphql.ID }) (*itemResolver, error) {
+	item, err := r.items.GetItem(ctx, string(args.ID))
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return &itemResolver{item, r.logger}, nil
+}
+
+func (r *resolver) Items(ctx context.Context, args itemsArgs) (*itemConnectionResolver, error) {
+	return r.itemConnection(ctx, args, "")
+}
+
+// itemConnection runs the query of the args, listID overrides the list filter when set.
+func (r *resolver) itemConnection(ctx context.Context, args itemsArgs, listID string) (*itemConnectionResolver, error) {
+	query, err := args.query()
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+	if listID != "" {
+		query.ListID = listID
+	}
+
+	page, err := r.items.GetAllItems(ctx, query)
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	owners := make([]string, 0, len(page.Items))
+	for _, item := range page.Items {
+		owners = append(owners, item.OwnerID)
+	}
+	loaderFrom(ctx).prime(owners...)
+
+	return &itemConnectionResolver{page, r.logger}, nil
+}
+
+func (r *resolver) CreateItem(ctx context.Context, args struct{ Input itemInput }) (*itemResolver, error) {
+	item, err := args.Input.item()
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	id, err := r.items.CreateItem(ctx, item)
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.Item(ctx, struct{ ID graphql.ID }{graphql.ID(id)})
+}
+
+func (r *resolver) UpdateItem(ctx context.Context, args struct {
+	ID      graphql.ID
+	Input   itemInput
+	Version *int32
+}) (*itemResolver, error) {
+	item, err := args.Input.item()
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	item.ID = string(args.ID)
+	item.Version = version(args.Version)
+	if _, err = r.items.UpdateItem(ctx, item); err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.Item(ctx, struct{ ID graphql.ID }{args.ID})
+}
+
+func (r *resolver) DeleteItem(ctx context.Context, args struct {
+	ID      graphql.ID
+	Version *int32
+}) (graphql.ID, error) {
+	if _, err := r.items.DeleteItem(ctx, string(args.ID), version(args.Version)); err != nil {
+		return "", resolverError(r.logger, err)
+	}
+
+	return args.ID, nil
+}
+
+func (r *resolver) MoveItem(ctx context.Context, args struct {
+	ID       graphql.ID
+	ListID   *graphql.ID
+	ParentID *graphql.ID
+}) (*itemResolver, error) {
+	if _, err := r.items.MoveItem(ctx, string(args.ID), id(args.ListID), id(args.ParentID)); err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.Item(ctx, struct{ ID graphql.ID }{args.ID})
+}
+
+func (a itemsArgs) query() (model.Query, error) {
+	query := model.Query{
+		Sort:   model.SortField(strings.ToLower(value(a.Sort))),
+		Cursor: value(a.After),
+	}
+	if a.Desc != nil {
+		query.Desc = *a.Desc
+	}
+	if a.First != nil {
+		query.PageSize = int(*a.First)
+	}
+
+	f := a.Filter
+	if f == nil {
+		return query, nil
+	}
+
+	query.ListID = id(f.ListID)
+	query.Completed = f.Completed
+	query.DueBefore = timeOf(f.DueBefore)
+	query.DueAfter = timeOf(f.DueAfter)
+	query.TagMode = model.TagMode(strings.ToLower(value(f.TagMode)))
+	query.Search = value(f.Search)
+	if f.Tags != nil {
+		query.Tags = *f.Tags
+	}
+	if f.Priorities != nil {
+		for _, s := range *f.Priorities {
+			priority, err := model.ParsePriority(strings.ToLower(s))
+			if err != nil {
+				return query, validate.Fail(todo.ErrInvalidQuery, "priorities", validate.CodeNotAllowed, err.Error())
+			}
+			query.Priorities = append(query.Priorities, priority)
+		}
+	}
+
+	return query, nil
+}
+
+func (in itemInput) item() (model.Item, error) {
+	item := model.Item{
+		ListID:      id(in.ListID),
+		ParentID:    id(in.ParentID),
+		Title:       in.Title,
+		Description: value(in.Description),
+		DueAt:       timeOf(in.DueAt),
+		Recurrence:  value(in.Recurrence),
+	}
+	if in.Completed != nil {
+		item.Completed = *in.Completed
+	}
+	if in.Tags != nil {
+		item.Tags = *in.Tags
+	}
+
+	var err error
+	item.Priority, err = model.ParsePriority(strings.ToLower(value(in.Priority)))
+	if err != nil {
+		return item, validate.Fail(todo.ErrInvalidItem, "priority", validate.CodeNotAllowed, err.Error())
+	}
+
+	return item, nil
+}
+
+type itemResolver struct {
+	item   model.Item
+	logger *zap.Logger
+}
+
+func (r *itemResolver) ID() graphql.ID {
+	return graphql.ID(r.item.ID)
+}
+
+func (r *itemResolver) Owner(ctx context.Context) (*userResolver, error) {
+	user, err := loaderFrom(ctx).load(ctx, r.item.OwnerID)
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return &userResolver{user}, nil
+}
+
+func (r *itemResolver) ListID() graphql.ID {
+	return graphql.ID(r.item.ListID)
+}
+
+func (r *itemResolver) ParentID() *graphql.ID {
+	if r.item.ParentID == "" {
+		return nil
+	}
+
+	parentID := graphql.ID(r.item.ParentID)
+	return &parentID
+}
+
+func (r *itemResolver) Title() string {
+	return r.item.Title
+}
+
+func (r *itemResolver) Description() string {
+	return r.item.Description
+}
+
+func (r *itemResolver) Completed() bool {
+	return r.item.Completed
+}
+
+func (r *itemResolver) CompletedAt() *graphql.Time {
+	return timestamp(r.item.CompletedAt)
+}
+
+func (r *itemResolver) DueAt() *graphql.Time {
+	return timestamp(r.item.DueAt)
+}
+
+func (r *itemResolver) Priority() string {
+	return strings.ToUpper(r.item.Priority.String())
+}
+
+func (r *itemResolver) Tags() []string {
+	if r.item.Tags == nil {
+		return []string{}
+	}
+
+	return r.item.Tags
+}
+
+func (r *itemResolver) Recurrence() *string {
+	return optional(r.item.Recurrence)
+}
+
+func (r *itemResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.item.CreatedAt}
+}
+
+func (r *itemResolver) Version() int32 {
+	return int32(r.item.Version)
+}
+
+func (r *itemResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.item.UpdatedAt}
+}
+
+type itemConnectionResolver struct {
+	page   model.Page
+	logger *zap.Logger
+}
+
+func (r *itemConnectionResolver) Items() []*itemResolver {
+	res := make([]*itemResolver, 0, len(r.page.Items))
+	for _, item := range r.page.Items {
+		res = append(res, &itemResolver{item, r.logger})
+	}
+
+	return res
+}
+
+func (r *itemConnectionResolver) NextCursor() *string {
+	return optional(r.page.NextCursor)
+}
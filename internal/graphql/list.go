@@ -0,0 +1,89 @@
+package graphql
+
+import (
+	"context"
+
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/silverspase/todo/internal/modules/list/model"
+)
+
+func (r *resolver) List(ctx context.Context, args struct{ ID graphql.ID }) (*listResolver, error) {
+	l, err := r.lists.GetList(ctx, string(args.ID))
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return &listResolver{r, l}, nil
+}
+
+func (r *resolver) Lists(ctx context.Context) ([]*listResolver, error) {
+	lists, err := r.lists.GetAllLists(ctx)
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	res := make([]*listResolver, 0, len(lists))
+	for _, l := range lists {
+		res = append(res, &listResolver{r, l})
+	}
+
+	return res, nil
+}
+
+func (r *resolver) CreateList(ctx context.Context, args struct{ Name string }) (*listResolver, error) {
+	id, err := r.lists.CreateList(ctx, model.List{Name: args.Name})
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.List(ctx, struct{ ID graphql.ID }{graphql.ID(id)})
+}
+
+func (r *resolver) UpdateList(ctx context.Context, args struct {
+	ID   graphql.ID
+	Name string
+}) (*listResolver, error) {
+	if _, err := r.lists.UpdateList(ctx, model.List{ID: string(args.ID), Name: args.Name}); err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.List(ctx, struct{ ID graphql.ID }{args.ID})
+}
+
+func (r *resolver) DeleteList(ctx context.Context, args struct {
+	ID      graphql.ID
+	Cascade *bool
+}) (graphql.ID, error) {
+	cascade := args.Cascade != nil && *args.Cascade
+	if _, err := r.lists.DeleteList(ctx, string(args.ID), cascade); err != nil {
+		return "", resolverError(r.logger, err)
+	}
+
+	return args.ID, nil
+}
+
+type listResolver struct {
+	root *resolver
+	list model.List
+}
+
+func (r *listResolver) ID() graphql.ID {
+	return graphql.ID(r.list.ID)
+}
+
+func (r *listResolver) Name() string {
+	return r.list.Name
+}
+
+func (r *listResolver) Items(ctx context.Context, args itemsArgs) (*itemConnectionResolver, error) {
+	return r.root.itemConnection(ctx, args, r.list.ID)
+}
+
+func (r *listResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.list.CreatedAt}
+}
+
+func (r *listResolver) UpdatedAt() graphql.Time {
+	return graphql.Time{Time: r.list.UpdatedAt}
+}
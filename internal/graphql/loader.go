@@ -0,0 +1,127 @@
+package graphql
+
+import (
+	"context"
+	"sync"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+type loaderCtxKey struct{}
+
+// userLoader batches the user lookups of a request. Resolvers returning a page of
+// items prime it with their owners, and the first load fetches all of them at once.
+// graphql-go resolves the list elements concurrently: the loads of the users being
+// fetched wait for their batch instead of fetching them again.
+type userLoader struct {
+	users auth.UseCase
+
+	mu      sync.Mutex
+	pending map[string]struct{}
+	loading map[string]*batch      // the batch fetching the user
+	loaded  map[string]*model.User // nil for unknown users
+}
+
+// batch is a fetch in flight, done is closed once loaded is filled or err is set.
+type batch struct {
+	done chan struct{}
+	err  error
+}
+
+func newUserLoader(users auth.UseCase) *userLoader {
+	return &userLoader{
+		users:   users,
+		pending: make(map[string]struct{}),
+		loading: make(map[string]*batch),
+		loaded:  make(map[string]*model.User),
+	}
+}
+
+func withLoader(ctx context.Context, l *userLoader) context.Context {
+	return context.WithValue(ctx, loaderCtxKey{}, l)
+}
+
+func loaderFrom(ctx context.Context) *userLoader {
+	return ctx.Value(loaderCtxKey{}).(*userLoader)
+}
+
+// prime registers users which are likely to be loaded soon.
+func (l *userLoader) prime(ids ...string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, id := range ids {
+		_, loaded := l.loaded[id]
+		_, loading := l.loading[id]
+		if !loaded && !loading {
+			l.pending[id] = struct{}{}
+		}
+	}
+}
+
+func (l *userLoader) load(ctx context.Context, id string) (model.User, error) {
+	l.mu.Lock()
+	if user, ok := l.loaded[id]; ok {
+		l.mu.Unlock()
+		return found(user)
+	}
+	if b, ok := l.loading[id]; ok {
+		l.mu.Unlock()
+		return l.wait(ctx, b, id)
+	}
+
+	l.pending[id] = struct{}{}
+	b := &batch{done: make(chan struct{})}
+	ids := make([]string, 0, len(l.pending))
+	for pending := range l.pending {
+		ids = append(ids, pending)
+		l.loading[pending] = b
+	}
+	l.pending = make(map[string]struct{})
+	l.mu.Unlock()
+
+	// the lock isn't held meanwhile, so the resolvers of the loaded users go on
+	users, err := l.users.GetUsersByIDs(ctx, ids)
+
+	l.mu.Lock()
+	for _, pending := range ids {
+		delete(l.loading, pending)
+		if _, ok := l.loaded[pending]; !ok && err == nil {
+			l.loaded[pending] = nil
+		}
+	}
+	for i := range users {
+		l.loaded[users[i].ID] = &users[i]
+	}
+	b.err = err
+	l.mu.Unlock()
+	close(b.done)
+
+	return l.wait(ctx, b, id)
+}
+
+// wait returns the user once the batch fetching it is done.
+func (l *userLoader) wait(ctx context.Context, b *batch, id string) (model.User, error) {
+	select {
+	case <-b.done:
+	case <-ctx.Done():
+		return model.User{}, ctx.Err()
+	}
+	if b.err != nil {
+		return model.User{}, b.err
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return found(l.loaded[id])
+}
+
+func found(user *model.User) (model.User, error) {
+	if user == nil {
+		return model.User{}, auth.ErrNotFound
+	}
+
+	return *user, nil
+}
@@ -0,0 +1,73 @@
+package graphql
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+// countingUsers counts the lookups, which take a while so the concurrent loads overlap.
+type countingUsers struct {
+	auth.UseCase
+	calls int32
+}
+
+func (c *countingUsers) GetUsersByIDs(ctx context.Context, ids []string) ([]model.User, error) {
+	atomic.AddInt32(&c.calls, 1)
+	time.Sleep(10 * time.Millisecond)
+
+	users := make([]model.User, 0, len(ids))
+	for _, id := range ids {
+		if id != "unknown" {
+			users = append(users, model.User{ID: id, Name: "user " + id})
+		}
+	}
+
+	return users, nil
+}
+
+func TestLoaderBatchesConcurrentLoads(t *testing.T) {
+	users := &countingUsers{}
+	l := newUserLoader(users)
+	l.prime("ann", "bob", "unknown")
+
+	var wg sync.WaitGroup
+	errs := make(chan error, 30)
+	for i := 0; i < 30; i++ {
+		id := []string{"ann", "bob", "unknown"}[i%3]
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			user, err := l.load(context.Background(), id)
+			switch {
+			case id == "unknown" && !errors.Is(err, auth.ErrNotFound):
+				errs <- err
+			case id != "unknown" && (err != nil || user.ID != id):
+				errs <- errors.New("got " + user.ID + " instead of " + id)
+			}
+		}()
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		t.Errorf("load: %v", err)
+	}
+	if calls := atomic.LoadInt32(&users.calls); calls != 1 {
+		t.Errorf("got %d lookups, want 1", calls)
+	}
+
+	if _, err := l.load(context.Background(), "ann"); err != nil {
+		t.Errorf("load of a loaded user: %v", err)
+	}
+	if calls := atomic.LoadInt32(&users.calls); calls != 1 {
+		t.Errorf("got %d lookups after loading a loaded user, want 1", calls)
+	}
+}
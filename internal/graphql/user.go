@@ -0,0 +1,133 @@
+package graphql
+
+import (
+	"context"
+
+	graphql "github.com/graph-gophers/graphql-go"
+
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+type userInput struct {
+	Name     *string
+	Email    *string
+	Gender   *string
+	Password *string
+}
+
+type userUpdate struct {
+	Name     *string
+	Password *string
+}
+
+// User is checked by the use case as GET /user/{id} is, the loader serves the owners
+// of the items the user has been authorized to see.
+func (r *resolver) User(ctx context.Context, args struct{ ID graphql.ID }) (*userResolver, error) {
+	return r.getUser(ctx, string(args.ID))
+}
+
+func (r *resolver) Users(ctx context.Context, args struct {
+	First *int32
+	After *string
+}) (*userConnectionResolver, error) {
+	var pageSize int
+	if args.First != nil {
+		pageSize = int(*args.First)
+	}
+
+	page, err := r.users.GetAllUsers(ctx, value(args.After), pageSize)
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return &userConnectionResolver{page}, nil
+}
+
+func (r *resolver) CreateUser(ctx context.Context, args struct{ Input userInput }) (*userResolver, error) {
+	id, err := r.users.CreateUser(ctx, args.Input.user())
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.getUser(ctx, id)
+}
+
+func (r *resolver) UpdateUser(ctx context.Context, args struct {
+	ID    graphql.ID
+	Input userUpdate
+}) (*userResolver, error) {
+	user := model.User{ID: string(args.ID), Name: value(args.Input.Name), Password: value(args.Input.Password)}
+	if _, err := r.users.UpdateUser(ctx, user); err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return r.getUser(ctx, user.ID)
+}
+
+func (r *resolver) DeleteUser(ctx context.Context, args struct{ ID graphql.ID }) (graphql.ID, error) {
+	if _, err := r.users.DeleteUser(ctx, string(args.ID), 0); err != nil {
+		return "", resolverError(r.logger, err)
+	}
+
+	return args.ID, nil
+}
+
+// getUser bypasses the loader, which may have cached the user before the mutation.
+func (r *resolver) getUser(ctx context.Context, id string) (*userResolver, error) {
+	user, err := r.users.GetUser(ctx, id)
+	if err != nil {
+		return nil, resolverError(r.logger, err)
+	}
+
+	return &userResolver{user}, nil
+}
+
+func (in userInput) user() model.User {
+	return model.User{
+		Name:     value(in.Name),
+		Email:    value(in.Email),
+		Gender:   value(in.Gender),
+		Password: value(in.Password),
+	}
+}
+
+type userResolver struct {
+	user model.User
+}
+
+func (r *userResolver) ID() graphql.ID {
+	return graphql.ID(r.user.ID)
+}
+
+func (r *userResolver) Name() string {
+	return r.user.Name
+}
+
+func (r *userResolver) Email() string {
+	return r.user.Email
+}
+
+func (r *userResolver) Gender() string {
+	return r.user.Gender
+}
+
+func (r *userResolver) CreatedAt() graphql.Time {
+	return graphql.Time{Time: r.user.CreatedAt}
+}
+
+type userConnectionResolver struct {
+	page model.Page
+}
+
+func (r *userConnectionResolver) Users() []*userResolver {
+	res := make([]*userResolver, 0, len(r.page.Items))
+	for _, user := range r.page.Items {
+		res = append(res, &userResolver{user})
+	}
+
+	return res
+}
+
+func (r *userConnectionResolver) NextCursor() *string {
+	return optional(r.page.NextCursor)
+}
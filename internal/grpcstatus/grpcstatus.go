@@ -0,0 +1,50 @@
+// Package grpcstatus converts the domain errors to gRPC statuses.
+package grpcstatus
+
+import (
+	"errors"
+
+	"go.uber.org/zap"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+var grpcCodes = map[errs.Kind]codes.Code{
+	errs.NotFound:             codes.NotFound,
+	errs.Conflict:             codes.FailedPrecondition,
+	errs.Validation:           codes.InvalidArgument,
+	errs.Unauthorized:         codes.Unauthenticated,
+	errs.Forbidden:            codes.PermissionDenied,
+	errs.Gone:                 codes.OutOfRange,
+	errs.PreconditionFailed:   codes.Aborted,
+	errs.TooLarge:             codes.ResourceExhausted,
+	errs.UnsupportedMediaType: codes.InvalidArgument,
+}
+
+// FromError returns the status of the error's kind. Invalid fields are attached as BadRequest details,
+// internal errors are logged with logger and not detailed.
+func FromError(logger *zap.Logger, err error) error {
+	code, ok := grpcCodes[errs.KindOf(err)]
+	if !ok {
+		logger.Error("call failed", zap.Error(err))
+		return status.Error(codes.Internal, "internal error")
+	}
+
+	st := status.New(code, err.Error())
+	var fields *validate.Errors
+	if errors.As(err, &fields) {
+		violations := make([]*errdetails.BadRequest_FieldViolation, 0, len(fields.Fields))
+		for _, f := range fields.Fields {
+			violations = append(violations, &errdetails.BadRequest_FieldViolation{Field: f.Field, Description: f.Message})
+		}
+		if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			st = detailed
+		}
+	}
+
+	return st.Err()
+}
@@ -0,0 +1,100 @@
+// Package httpjson decodes JSON request bodies strictly and limits their size.
+package httpjson
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"mime"
+	"net/http"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/jsonpatch"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// ErrTooLarge is returned by the reads past the LimitBody limit.
+var ErrTooLarge = errs.New(errs.TooLarge, "request body is too large")
+
+// LimitBody makes the request bodies fail with ErrTooLarge after limit bytes.
+func LimitBody(limit int64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.Body != nil && r.Body != http.NoBody {
+				r.Body = &limitedBody{ReadCloser: r.Body, left: limit}
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+type limitedBody struct {
+	io.ReadCloser
+	left int64 // negative once the limit is exceeded
+}
+
+func (b *limitedBody) Read(p []byte) (int, error) {
+	if b.left < 0 {
+		return 0, ErrTooLarge
+	}
+
+	// one byte over the limit tells a body of exactly limit bytes from a larger one
+	if int64(len(p)) > b.left+1 {
+		p = p[:b.left+1]
+	}
+	n, err := b.ReadCloser.Read(p)
+	if int64(n) > b.left {
+		n = int(b.left)
+		b.left = -1
+		return n, ErrTooLarge
+	}
+	b.left -= int64(n)
+
+	return n, err
+}
+
+// Decode reads the single JSON value of the request body into v, rejecting unknown fields.
+// The errors wrap invalid, which is the transport's error of a bad payload,
+// and name the offending field when it's known.
+func Decode(r *http.Request, v interface{}, invalid error) error {
+	decoder := json.NewDecoder(r.Body)
+	decoder.DisallowUnknownFields()
+
+	err := decoder.Decode(v)
+	if err == nil {
+		// anything but the end of the body after the value
+		if err = decoder.Decode(&struct{}{}); err == io.EOF {
+			return nil
+		}
+		if err == nil || !errors.Is(err, ErrTooLarge) {
+			return validate.Fail(invalid, "", validate.CodeFormat, "body must hold a single JSON value")
+		}
+	}
+
+	if errors.Is(err, ErrTooLarge) {
+		return err
+	}
+
+	return validate.JSONError(err, invalid)
+}
+
+// DecodePatch reads the patch document of a PATCH request, its format is told by the Content-Type.
+// The document is applied, and thus checked, by the use case.
+func DecodePatch(r *http.Request) (jsonpatch.Patch, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if err != nil {
+		return jsonpatch.Patch{}, jsonpatch.ErrUnsupported
+	}
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		return jsonpatch.Patch{}, err
+	}
+
+	return jsonpatch.New(mediaType, body)
+}
+
+// AcceptPatch advertises the supported patch formats (RFC 5789). The PATCH handlers send it
+// with every response, so the client refused with 415 learns them.
+func AcceptPatch(w http.ResponseWriter) {
+	w.Header().Set("Accept-Patch", jsonpatch.MergePatchType+", "+jsonpatch.JSONPatchType)
+}
@@ -0,0 +1,374 @@
+// Package jsonpatch applies JSON Merge Patch (RFC 7396) and JSON Patch (RFC 6902) documents
+// to the JSON representation of a resource.
+package jsonpatch
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/silverspase/todo/internal/errs"
+)
+
+// The media types of the supported patch formats.
+const (
+	MergePatchType = "application/merge-patch+json"
+	JSONPatchType  = "application/json-patch+json"
+)
+
+var (
+	// ErrUnsupported is returned for patches of other media types.
+	ErrUnsupported = errs.New(errs.UnsupportedMediaType, "unsupported patch media type, use "+
+		MergePatchType+" or "+JSONPatchType)
+	// ErrInvalid is wrapped by the errors of malformed patches and of operations
+	// the document doesn't allow, like removing a missing member.
+	ErrInvalid = errs.New(errs.Validation, "invalid patch")
+	// ErrTestFailed is returned when a JSON Patch test operation doesn't hold.
+	ErrTestFailed = errs.New(errs.Conflict, "patch test failed")
+)
+
+// Patch is a patch document of one of the supported media types.
+type Patch struct {
+	Type string
+	Body []byte
+}
+
+// New checks the media type of the patch.
+func New(mediaType string, body []byte) (Patch, error) {
+	if mediaType != MergePatchType && mediaType != JSONPatchType {
+		return Patch{}, ErrUnsupported
+	}
+
+	return Patch{Type: mediaType, Body: body}, nil
+}
+
+// Apply returns the patched JSON document.
+func (p Patch) Apply(doc []byte) ([]byte, error) {
+	var target interface{}
+	if err := json.Unmarshal(doc, &target); err != nil {
+		return nil, err
+	}
+
+	var err error
+	switch p.Type {
+	case MergePatchType:
+		var patch interface{}
+		if err = json.Unmarshal(p.Body, &patch); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		target = merge(target, patch)
+	case JSONPatchType:
+		var ops []operation
+		if err = json.Unmarshal(p.Body, &ops); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+		for i, op := range ops {
+			if target, err = op.apply(target); err != nil {
+				return nil, fmt.Errorf("operation %d: %w", i, err)
+			}
+		}
+	default:
+		return nil, ErrUnsupported
+	}
+
+	return json.Marshal(target)
+}
+
+// Changed returns the listed top-level members of the JSON objects which differ,
+// including the ones added or removed, so the read-only fields can be protected.
+func Changed(before, after []byte, members ...string) ([]string, error) {
+	var old, patched map[string]interface{}
+	if err := json.Unmarshal(before, &old); err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(after, &patched); err != nil {
+		return nil, fmt.Errorf("%w: the patched document is not an object", ErrInvalid)
+	}
+
+	var changed []string
+	for _, name := range members {
+		if !reflect.DeepEqual(old[name], patched[name]) {
+			changed = append(changed, name)
+		}
+	}
+
+	return changed, nil
+}
+
+// merge is the MergePatch function of RFC 7396.
+func merge(target, patch interface{}) interface{} {
+	members, ok := patch.(map[string]interface{})
+	if !ok {
+		return patch
+	}
+
+	object, ok := target.(map[string]interface{})
+	if !ok {
+		object = make(map[string]interface{})
+	}
+	for name, value := range members {
+		if value == nil {
+			delete(object, name)
+			continue
+		}
+		object[name] = merge(object[name], value)
+	}
+
+	return object
+}
+
+type operation struct {
+	Op    string          `json:"op"`
+	Path  *string         `json:"path"`
+	From  *string         `json:"from"`
+	Value json.RawMessage `json:"value"`
+	// hasValue tells a missing value apart from null, which is a value like any other
+	hasValue bool
+}
+
+func (o *operation) UnmarshalJSON(data []byte) error {
+	type members operation
+	if err := json.Unmarshal(data, (*members)(o)); err != nil {
+		return err
+	}
+
+	var present map[string]json.RawMessage
+	if err := json.Unmarshal(data, &present); err != nil {
+		return err
+	}
+	_, o.hasValue = present["value"]
+
+	return nil
+}
+
+func (o operation) apply(doc interface{}) (interface{}, error) {
+	if o.Path == nil {
+		return nil, fmt.Errorf("%w: path is required", ErrInvalid)
+	}
+	path, err := parsePointer(*o.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	switch o.Op {
+	case "add", "replace", "test":
+		value, err := o.value()
+		if err != nil {
+			return nil, err
+		}
+		switch o.Op {
+		case "add":
+			return add(doc, path, value)
+		case "replace":
+			if len(path) == 0 {
+				return value, nil
+			}
+			if doc, err = remove(doc, path); err != nil {
+				return nil, err
+			}
+			return add(doc, path, value)
+		default:
+			current, err := get(doc, path)
+			if err != nil {
+				return nil, err
+			}
+			if !reflect.DeepEqual(current, value) {
+				return nil, fmt.Errorf("%w: %s isn't the expected value", ErrTestFailed, *o.Path)
+			}
+			return doc, nil
+		}
+	case "remove":
+		return remove(doc, path)
+	case "move", "copy":
+		if o.From == nil {
+			return nil, fmt.Errorf("%w: from is required", ErrInvalid)
+		}
+		from, err := parsePointer(*o.From)
+		if err != nil {
+			return nil, err
+		}
+		value, err := get(doc, from)
+		if err != nil {
+			return nil, err
+		}
+		if o.Op == "copy" {
+			return add(doc, path, deepCopy(value))
+		}
+		if len(path) > len(from) && reflect.DeepEqual(path[:len(from)], from) {
+			return nil, fmt.Errorf("%w: %s can't be moved into itself", ErrInvalid, *o.From)
+		}
+		if doc, err = remove(doc, from); err != nil {
+			return nil, err
+		}
+		return add(doc, path, value)
+	default:
+		return nil, fmt.Errorf("%w: unknown op %q", ErrInvalid, o.Op)
+	}
+}
+
+func (o operation) value() (interface{}, error) {
+	if !o.hasValue {
+		return nil, fmt.Errorf("%w: value is required", ErrInvalid)
+	}
+	if len(o.Value) == 0 {
+		return nil, nil
+	}
+
+	var value interface{}
+	err := json.Unmarshal(o.Value, &value)
+	return value, err
+}
+
+// parsePointer splits a JSON Pointer (RFC 6901) into the unescaped reference tokens.
+func parsePointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if pointer[0] != '/' {
+		return nil, fmt.Errorf("%w: path %q doesn't start with /", ErrInvalid, pointer)
+	}
+
+	tokens := strings.Split(pointer[1:], "/")
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	for i, token := range tokens {
+		tokens[i] = unescape.Replace(token)
+	}
+
+	return tokens, nil
+}
+
+func get(doc interface{}, path []string) (interface{}, error) {
+	for _, token := range path {
+		switch node := doc.(type) {
+		case map[string]interface{}:
+			value, ok := node[token]
+			if !ok {
+				return nil, fmt.Errorf("%w: member %q doesn't exist", ErrInvalid, token)
+			}
+			doc = value
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			doc = node[i]
+		default:
+			return nil, fmt.Errorf("%w: %q can't be looked up in a scalar", ErrInvalid, token)
+		}
+	}
+
+	return doc, nil
+}
+
+// update replaces the parent of the path's last token with the result of fn.
+// The path must not be empty.
+func update(doc interface{}, path []string, fn func(parent interface{}, token string) (interface{}, error)) (interface{}, error) {
+	if len(path) == 1 {
+		return fn(doc, path[0])
+	}
+
+	child, err := get(doc, path[:1])
+	if err != nil {
+		return nil, err
+	}
+	if child, err = update(child, path[1:], fn); err != nil {
+		return nil, err
+	}
+
+	switch node := doc.(type) {
+	case map[string]interface{}:
+		node[path[0]] = child
+	case []interface{}:
+		i, _ := index(path[0], len(node)-1)
+		node[i] = child
+	}
+
+	return doc, nil
+}
+
+func add(doc interface{}, path []string, value interface{}) (interface{}, error) {
+	if len(path) == 0 {
+		return value, nil
+	}
+
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			node[token] = value
+			return node, nil
+		case []interface{}:
+			i := len(node)
+			if token != "-" {
+				var err error
+				if i, err = index(token, len(node)); err != nil {
+					return nil, err
+				}
+			}
+			node = append(node, nil)
+			copy(node[i+1:], node[i:])
+			node[i] = value
+			return node, nil
+		default:
+			return nil, fmt.Errorf("%w: %q can't be added to a scalar", ErrInvalid, token)
+		}
+	})
+}
+
+func remove(doc interface{}, path []string) (interface{}, error) {
+	if len(path) == 0 {
+		return nil, fmt.Errorf("%w: the whole document can't be removed", ErrInvalid)
+	}
+
+	return update(doc, path, func(parent interface{}, token string) (interface{}, error) {
+		switch node := parent.(type) {
+		case map[string]interface{}:
+			if _, ok := node[token]; !ok {
+				return nil, fmt.Errorf("%w: member %q doesn't exist", ErrInvalid, token)
+			}
+			delete(node, token)
+			return node, nil
+		case []interface{}:
+			i, err := index(token, len(node)-1)
+			if err != nil {
+				return nil, err
+			}
+			return append(node[:i], node[i+1:]...), nil
+		default:
+			return nil, fmt.Errorf("%w: %q can't be removed from a scalar", ErrInvalid, token)
+		}
+	})
+}
+
+// index parses an array index token, which must not exceed max.
+func index(token string, max int) (int, error) {
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("%w: %q is not an array index", ErrInvalid, token)
+	}
+	if i > max {
+		return 0, fmt.Errorf("%w: index %d is out of range", ErrInvalid, i)
+	}
+
+	return i, nil
+}
+
+func deepCopy(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		res := make(map[string]interface{}, len(v))
+		for name, member := range v {
+			res[name] = deepCopy(member)
+		}
+		return res
+	case []interface{}:
+		res := make([]interface{}, len(v))
+		for i, element := range v {
+			res[i] = deepCopy(element)
+		}
+		return res
+	default:
+		return v
+	}
+}
@@ -0,0 +1,166 @@
+package jsonpatch_test
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/silverspase/todo/internal/jsonpatch"
+)
+
+const doc = `{"title":"Milk","due_at":"2030-01-01T00:00:00Z","tags":["home","shop"],"meta":{"n":1}}`
+
+func TestApply(t *testing.T) {
+	tests := []struct {
+		name  string
+		typ   string
+		patch string
+		want  string
+	}{
+		{
+			name:  "merge",
+			typ:   jsonpatch.MergePatchType,
+			patch: `{"title":"Oat milk","due_at":null,"meta":{"m":2}}`,
+			want:  `{"title":"Oat milk","tags":["home","shop"],"meta":{"n":1,"m":2}}`,
+		},
+		{
+			name:  "merge of a non-object",
+			typ:   jsonpatch.MergePatchType,
+			patch: `{"meta":["x"]}`,
+			want:  `{"title":"Milk","due_at":"2030-01-01T00:00:00Z","tags":["home","shop"],"meta":["x"]}`,
+		},
+		{
+			name:  "replace",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"replace","path":"/title","value":"Oat milk"}]`,
+			want:  `{"title":"Oat milk","due_at":"2030-01-01T00:00:00Z","tags":["home","shop"],"meta":{"n":1}}`,
+		},
+		{
+			name:  "replace with null",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"replace","path":"/due_at","value":null}]`,
+			want:  `{"title":"Milk","due_at":null,"tags":["home","shop"],"meta":{"n":1}}`,
+		},
+		{
+			name:  "add null",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"add","path":"/parent_id","value":null}]`,
+			want:  `{"title":"Milk","due_at":"2030-01-01T00:00:00Z","tags":["home","shop"],"meta":{"n":1},"parent_id":null}`,
+		},
+		{
+			name:  "test null",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"replace","path":"/due_at","value":null},{"op":"test","path":"/due_at","value":null}]`,
+			want:  `{"title":"Milk","due_at":null,"tags":["home","shop"],"meta":{"n":1}}`,
+		},
+		{
+			name:  "add to an array",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"add","path":"/tags/1","value":"urgent"},{"op":"add","path":"/tags/-","value":"last"}]`,
+			want:  `{"title":"Milk","due_at":"2030-01-01T00:00:00Z","tags":["home","urgent","shop","last"],"meta":{"n":1}}`,
+		},
+		{
+			name:  "remove",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"remove","path":"/tags/0"},{"op":"remove","path":"/due_at"}]`,
+			want:  `{"title":"Milk","tags":["shop"],"meta":{"n":1}}`,
+		},
+		{
+			name:  "move and copy",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"move","from":"/meta/n","path":"/n"},{"op":"copy","from":"/tags","path":"/labels"}]`,
+			want:  `{"title":"Milk","due_at":"2030-01-01T00:00:00Z","tags":["home","shop"],"meta":{},"n":1,"labels":["home","shop"]}`,
+		},
+		{
+			name:  "escaped pointer",
+			typ:   jsonpatch.JSONPatchType,
+			patch: `[{"op":"add","path":"/a~1b~0c","value":true}]`,
+			want:  `{"title":"Milk","due_at":"2030-01-01T00:00:00Z","tags":["home","shop"],"meta":{"n":1},"a/b~c":true}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.New(tt.typ, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			got, err := patch.Apply([]byte(doc))
+			if err != nil {
+				t.Fatalf("Apply: %v", err)
+			}
+			if !equalJSON(t, got, []byte(tt.want)) {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestApplyInvalid(t *testing.T) {
+	tests := []struct {
+		name  string
+		patch string
+		want  error
+	}{
+		{name: "not an array", patch: `{"op":"remove","path":"/title"}`, want: jsonpatch.ErrInvalid},
+		{name: "unknown op", patch: `[{"op":"swap","path":"/title"}]`, want: jsonpatch.ErrInvalid},
+		{name: "no path", patch: `[{"op":"remove"}]`, want: jsonpatch.ErrInvalid},
+		{name: "no value", patch: `[{"op":"replace","path":"/title"}]`, want: jsonpatch.ErrInvalid},
+		{name: "no from", patch: `[{"op":"copy","path":"/title"}]`, want: jsonpatch.ErrInvalid},
+		{name: "relative path", patch: `[{"op":"remove","path":"title"}]`, want: jsonpatch.ErrInvalid},
+		{name: "missing member", patch: `[{"op":"remove","path":"/priority"}]`, want: jsonpatch.ErrInvalid},
+		{name: "index out of range", patch: `[{"op":"replace","path":"/tags/2","value":"x"}]`, want: jsonpatch.ErrInvalid},
+		{name: "leading zero", patch: `[{"op":"remove","path":"/tags/01"}]`, want: jsonpatch.ErrInvalid},
+		{name: "into itself", patch: `[{"op":"move","from":"/meta","path":"/meta/child"}]`, want: jsonpatch.ErrInvalid},
+		{name: "whole document", patch: `[{"op":"remove","path":""}]`, want: jsonpatch.ErrInvalid},
+		{name: "test failed", patch: `[{"op":"test","path":"/title","value":"Bread"}]`, want: jsonpatch.ErrTestFailed},
+		{name: "test of null failed", patch: `[{"op":"test","path":"/title","value":null}]`, want: jsonpatch.ErrTestFailed},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			patch, err := jsonpatch.New(jsonpatch.JSONPatchType, []byte(tt.patch))
+			if err != nil {
+				t.Fatalf("New: %v", err)
+			}
+			if _, err = patch.Apply([]byte(doc)); !errors.Is(err, tt.want) {
+				t.Errorf("got %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewUnsupported(t *testing.T) {
+	if _, err := jsonpatch.New("application/json", []byte(`{}`)); !errors.Is(err, jsonpatch.ErrUnsupported) {
+		t.Errorf("got %v, want %v", err, jsonpatch.ErrUnsupported)
+	}
+}
+
+func TestChanged(t *testing.T) {
+	after := `{"title":"Milk","due_at":null,"tags":["home","shop"],"meta":{"n":2}}`
+
+	got, err := jsonpatch.Changed([]byte(doc), []byte(after), "title", "due_at", "meta", "list_id")
+	if err != nil {
+		t.Fatalf("Changed: %v", err)
+	}
+	if want := []string{"due_at", "meta"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %q, want %q", got, want)
+	}
+
+	if _, err = jsonpatch.Changed([]byte(doc), []byte(`[]`), "title"); !errors.Is(err, jsonpatch.ErrInvalid) {
+		t.Errorf("Changed to an array: got %v, want %v", err, jsonpatch.ErrInvalid)
+	}
+}
+
+func equalJSON(t *testing.T, a, b []byte) bool {
+	t.Helper()
+
+	var x, y interface{}
+	if err := json.Unmarshal(a, &x); err != nil {
+		t.Fatalf("bad JSON %s: %v", a, err)
+	}
+	if err := json.Unmarshal(b, &y); err != nil {
+		t.Fatalf("bad JSON %s: %v", b, err)
+	}
+
+	return reflect.DeepEqual(x, y)
+}
@@ -9,6 +9,8 @@ import (
 	"github.com/silverspase/todo/internal/config"
 )
 
+// Init builds the logger of the application, which is passed to the modules and
+// to the shared packages (problem, grpcstatus) to log internal errors.
 func Init(appCfg config.Config) *zap.Logger {
 	var logLevel zapcore.Level
 	err := logLevel.UnmarshalText([]byte(appCfg.LogLevel))
@@ -0,0 +1,34 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+type userCtxKey struct{}
+
+// NewContext returns a copy of ctx carrying the authenticated user.
+func NewContext(ctx context.Context, user model.User) context.Context {
+	return context.WithValue(ctx, userCtxKey{}, user)
+}
+
+// FromContext returns the authenticated user stored in ctx by NewContext.
+func FromContext(ctx context.Context) (model.User, bool) {
+	user, ok := ctx.Value(userCtxKey{}).(model.User)
+	return user, ok
+}
+
+type clientCtxKey struct{}
+
+// NewClientContext returns a copy of ctx carrying the client making the request.
+func NewClientContext(ctx context.Context, client model.Client) context.Context {
+	return context.WithValue(ctx, clientCtxKey{}, client)
+}
+
+// ClientFromContext returns the client stored in ctx by NewClientContext,
+// the zero Client when there is none.
+func ClientFromContext(ctx context.Context) model.Client {
+	client, _ := ctx.Value(clientCtxKey{}).(model.Client)
+	return client
+}
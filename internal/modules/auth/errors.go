@@ -0,0 +1,15 @@
+package auth
+
+import "github.com/silverspase/todo/internal/errs"
+
+var (
+	ErrNotFound           = errs.New(errs.NotFound, "not found")
+	ErrInvalidUser        = errs.New(errs.Validation, "invalid user")
+	ErrEmailTaken         = errs.New(errs.Conflict, "email is already registered")
+	ErrInvalidCredentials = errs.New(errs.Unauthorized, "invalid email or password")
+	ErrUnauthorized       = errs.New(errs.Unauthorized, "unauthorized")
+	ErrInvalidPage        = errs.New(errs.Validation, "invalid page")
+	ErrInvalidQuery       = errs.New(errs.Validation, "invalid query")
+	ErrForbidden          = errs.New(errs.Forbidden, "forbidden")
+	ErrVersionMismatch    = errs.New(errs.PreconditionFailed, "user was changed, its version doesn't match")
+)
@@ -0,0 +1,83 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+type AuditAction string
+
+const (
+	AuditUserCreated     AuditAction = "user_created"
+	AuditUserUpdated     AuditAction = "user_updated"
+	AuditUserDeleted     AuditAction = "user_deleted"
+	AuditPasswordChanged AuditAction = "password_changed"
+	AuditRoleChanged     AuditAction = "role_changed"
+	AuditLogin           AuditAction = "login"
+)
+
+type AuditOutcome string
+
+const (
+	AuditSuccess AuditOutcome = "success"
+	AuditFailure AuditOutcome = "failure"
+)
+
+// AuditEntry is an append-only record of an attempt to change a user or to log in.
+// A failed login is a login entry with the failure outcome.
+type AuditEntry struct {
+	ID string `json:"id" gorm:"primaryKey"`
+	// ActorID is the authenticated user who made the attempt, empty for anonymous requests
+	ActorID string `json:"actor_id,omitempty" gorm:"index"`
+	// TargetID is the user the action is applied to, empty when it's unknown,
+	// like for a login with an unregistered email
+	TargetID string      `json:"target_id,omitempty"`
+	Action   AuditAction `json:"action"`
+	// Email is the one logged in with
+	Email string `json:"email,omitempty"`
+	// Role is the one granted
+	Role      Role         `json:"role,omitempty"`
+	IP        string       `json:"ip,omitempty"`
+	UserAgent string       `json:"user_agent,omitempty"`
+	Outcome   AuditOutcome `json:"outcome"`
+	// Reason is the error kind of a failure
+	Reason    string    `json:"reason,omitempty"`
+	CreatedAt time.Time `json:"created_at" gorm:"index"`
+}
+
+func (AuditEntry) TableName() string {
+	return "audit_log"
+}
+
+func (e *AuditEntry) BeforeCreate(tx *gorm.DB) error {
+	if e.ID == "" {
+		e.ID = uuid.New().String()
+	}
+	return nil
+}
+
+// AuditQuery filters the audit log. The zero values match any entry.
+type AuditQuery struct {
+	ActorID string
+	// From and To bound the creation time of the entries, the former inclusive
+	From *time.Time
+	To   *time.Time
+	// Cursor is the next_cursor of the previous page, decoded into After
+	Cursor   string
+	After    *Cursor
+	PageSize int
+}
+
+// AuditPage is a chunk of the audit log, the latest entries first.
+type AuditPage struct {
+	Items      []AuditEntry `json:"items"`
+	NextCursor string       `json:"next_cursor,omitempty"`
+}
+
+// Client is where a request comes from, recorded in the audit log.
+type Client struct {
+	IP        string
+	UserAgent string
+}
@@ -7,12 +7,28 @@ import (
 	"gorm.io/gorm"
 )
 
+// Role is what a user is allowed to do, see auth.Can.
+type Role string
+
+const (
+	RoleAdmin    Role = "admin"
+	RoleMember   Role = "member"
+	RoleReadOnly Role = "readonly"
+)
+
+// Valid tells whether the role is a known one.
+func (r Role) Valid() bool {
+	return r == RoleAdmin || r == RoleMember || r == RoleReadOnly
+}
+
 type User struct {
-	ID        string         `json:"-" gorm:"primaryKey"`
+	ID        string         `json:"id" gorm:"primaryKey"`
 	Name      string         `json:"name,omitempty"`
 	Email     string         `json:"email,omitempty" gorm:"type:varchar(100);unique_index"`
 	Gender    string         `json:"gender"`
-	Password  string         `json:"-"`
+	Role      Role           `json:"role"` // granted by the admins, new users are members
+	Password  string         `json:"-"`    // bcrypt hash, never the plain text password
+	Version   int64          `json:"-"`    // incremented by every update, sent as the ETag
 	CreatedAt time.Time      `json:"-"`
 	UpdatedAt time.Time      `json:"-"`
 	DeletedAt gorm.DeletedAt `json:"-" sql:"index"`
@@ -22,3 +38,31 @@ func (i *User) BeforeCreate(tx *gorm.DB) error {
 	i.ID = uuid.New().String()
 	return nil
 }
+
+// Session is an issued access token. Only the SHA-256 hash of the token is stored,
+// so a leaked sessions table can't be used to authenticate.
+type Session struct {
+	TokenHash string `gorm:"primaryKey"`
+	UserID    string `gorm:"index"`
+	ExpiresAt time.Time
+	CreatedAt time.Time
+}
+
+// Token is returned to the client on successful login.
+type Token struct {
+	AccessToken string    `json:"access_token"`
+	TokenType   string    `json:"token_type"`
+	ExpiresAt   time.Time `json:"expires_at"`
+}
+
+// Cursor is the (created_at, id) of the last user on a page, users are listed in this order.
+type Cursor struct {
+	CreatedAt time.Time `json:"c"`
+	ID        string    `json:"i"`
+}
+
+// Page is a chunk of GetAllUsers results.
+type Page struct {
+	Items      []User `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
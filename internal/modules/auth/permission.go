@@ -0,0 +1,52 @@
+package auth
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+// Permission is an operation the role of a user may grant.
+type Permission string
+
+const (
+	// PermissionReadTodo allows reading the user's own items, lists and tags.
+	PermissionReadTodo Permission = "todo:read"
+	// PermissionWriteTodo allows changing them.
+	PermissionWriteTodo Permission = "todo:write"
+	// PermissionManageUsers allows listing, changing and deleting other users and granting roles.
+	PermissionManageUsers Permission = "users:manage"
+	// PermissionReadAudit allows reading the audit log.
+	PermissionReadAudit Permission = "audit:read"
+)
+
+var rolePermissions = map[model.Role][]Permission{
+	model.RoleAdmin:    {PermissionReadTodo, PermissionWriteTodo, PermissionManageUsers, PermissionReadAudit},
+	model.RoleMember:   {PermissionReadTodo, PermissionWriteTodo},
+	model.RoleReadOnly: {PermissionReadTodo},
+}
+
+// Can tells whether the role of the user grants the permission.
+func Can(user model.User, permission Permission) bool {
+	for _, p := range rolePermissions[user.Role] {
+		if p == permission {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Authorize returns the user authenticated in ctx (see NewContext) if their role grants
+// the permission. ErrUnauthorized is returned without a user, ErrForbidden without the permission.
+func Authorize(ctx context.Context, permission Permission) (model.User, error) {
+	user, ok := FromContext(ctx)
+	if !ok {
+		return model.User{}, ErrUnauthorized
+	}
+	if !Can(user, permission) {
+		return model.User{}, ErrForbidden
+	}
+
+	return user, nil
+}
@@ -6,10 +6,40 @@ import (
 	"github.com/silverspase/todo/internal/modules/auth/model"
 )
 
+// Repository stores users and their sessions. Unknown users and sessions are reported as ErrNotFound.
 type Repository interface {
+	// CreateUser returns ErrEmailTaken when another user has the email, in any case.
 	CreateUser(ctx context.Context, items model.User) (string, error)
-	GetAllUsers(ctx context.Context, page int) ([]model.User, error)
+	// GetAllUsers returns at most limit users following the cursor, ordered by (created_at, id).
+	GetAllUsers(ctx context.Context, after *model.Cursor, limit int) ([]model.User, error)
 	GetUser(ctx context.Context, id string) (model.User, error)
+	// GetUsersByIDs returns the users found, in no particular order. Unknown IDs are skipped.
+	GetUsersByIDs(ctx context.Context, ids []string) ([]model.User, error)
+	// GetUserByEmail matches the email case-insensitively.
+	GetUserByEmail(ctx context.Context, email string) (model.User, error)
+	// UpdateUser changes the name and, when set, the password hash. Other fields are kept.
+	// The version is incremented, and the stored one must be item.Version,
+	// ErrVersionMismatch is returned otherwise; 0 skips the check.
 	UpdateUser(ctx context.Context, item model.User) (string, error)
-	DeleteUser(ctx context.Context, id string) (string, error)
+	// UpdateRole grants the user the role, the version is incremented.
+	UpdateRole(ctx context.Context, id string, role model.Role) (string, error)
+	// HasRole tells whether any user has the role.
+	HasRole(ctx context.Context, role model.Role) (bool, error)
+	// DeleteUser checks the version the same way UpdateUser does.
+	DeleteUser(ctx context.Context, id string, version int64) (string, error)
+
+	CreateSession(ctx context.Context, session model.Session) error
+	GetSession(ctx context.Context, tokenHash string) (model.Session, error)
+	DeleteSession(ctx context.Context, tokenHash string) error
+	// DeleteUserSessions deletes every session of the user.
+	DeleteUserSessions(ctx context.Context, userID string) error
+}
+
+// AuditRepository is the append-only store of the audit log.
+type AuditRepository interface {
+	// AppendAudit stores the entry, a missing ID and creation time are filled in.
+	AppendAudit(ctx context.Context, entry model.AuditEntry) error
+	// GetAudit returns at most query.PageSize entries matching the query following the cursor,
+	// ordered by (created_at, id) descending.
+	GetAudit(ctx context.Context, query model.AuditQuery) ([]model.AuditEntry, error)
 }
@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+type auditStorage struct {
+	mu      sync.RWMutex
+	entries []model.AuditEntry
+	logger  *zap.Logger
+}
+
+func NewAuditStorage(logger *zap.Logger) auth.AuditRepository {
+	return &auditStorage{logger: logger}
+}
+
+func (m *auditStorage) AppendAudit(ctx context.Context, entry model.AuditEntry) error {
+	m.logger.Debug("AppendAudit", zap.String("action", string(entry.Action)))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if entry.ID == "" {
+		entry.ID = uuid.New().String()
+	}
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+	m.entries = append(m.entries, entry)
+
+	return nil
+}
+
+func (m *auditStorage) GetAudit(ctx context.Context, query model.AuditQuery) (res []model.AuditEntry, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entry := range m.entries {
+		if matchesAudit(entry, query) {
+			res = append(res, entry)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return auditLess(res[j], res[i])
+	})
+
+	if len(res) > query.PageSize {
+		res = res[:query.PageSize]
+	}
+
+	return res, nil
+}
+
+func matchesAudit(entry model.AuditEntry, q model.AuditQuery) bool {
+	switch {
+	case q.ActorID != "" && entry.ActorID != q.ActorID:
+		return false
+	case q.From != nil && entry.CreatedAt.Before(*q.From):
+		return false
+	case q.To != nil && !entry.CreatedAt.Before(*q.To):
+		return false
+	case q.After != nil && !auditLess(entry, model.AuditEntry{ID: q.After.ID, CreatedAt: q.After.CreatedAt}):
+		return false
+	default:
+		return true
+	}
+}
+
+// auditLess orders the entries by (created_at, id).
+func auditLess(a, b model.AuditEntry) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	return a.ID < b.ID
+}
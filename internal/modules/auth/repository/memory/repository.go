@@ -2,8 +2,12 @@ package memory
 
 import (
 	"context"
-	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
 
 	"github.com/silverspase/todo/internal/modules/auth"
@@ -11,61 +15,224 @@ import (
 )
 
 type memoryStorage struct {
-	users map[string]model.User // TODO change to sync.Map
-	// usersArray []model.User // TODO use this for pagination in GetAllUsers
-	logger *zap.Logger
+	mu       sync.RWMutex
+	users    map[string]model.User
+	sessions map[string]model.Session
+	logger   *zap.Logger
 }
 
 func NewMemoryStorage(logger *zap.Logger) auth.Repository {
 	return &memoryStorage{
-		users:  make(map[string]model.User),
-		logger: logger,
+		users:    make(map[string]model.User),
+		sessions: make(map[string]model.Session),
+		logger:   logger,
 	}
 }
 
-func (m memoryStorage) CreateUser(ctx context.Context, entry model.User) (string, error) {
+func (m *memoryStorage) CreateUser(ctx context.Context, entry model.User) (string, error) {
 	m.logger.Debug("CreateUser")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, user := range m.users {
+		if strings.EqualFold(user.Email, entry.Email) {
+			return "", auth.ErrEmailTaken
+		}
+	}
+
+	now := time.Now()
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	entry.Version = 1
 	m.users[entry.ID] = entry
 
 	return entry.ID, nil
 }
 
-func (m memoryStorage) GetAllUsers(ctx context.Context, page int) (res []model.User, err error) {
+func (m *memoryStorage) GetAllUsers(ctx context.Context, after *model.Cursor, limit int) (res []model.User, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for _, user := range m.users {
-		res = append(res, user)
+		if after == nil || less(model.User{ID: after.ID, CreatedAt: after.CreatedAt}, user) {
+			res = append(res, user)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		return less(res[i], res[j])
+	})
+
+	if len(res) > limit {
+		res = res[:limit]
 	}
 
 	return res, nil
 }
 
-func (m memoryStorage) GetUser(ctx context.Context, id string) (model.User, error) {
+func (m *memoryStorage) GetUser(ctx context.Context, id string) (model.User, error) {
 	m.logger.Debug("GetItem")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	item, ok := m.users[id]
 	if !ok {
-		return item, errors.New("not found")
+		return item, auth.ErrNotFound
 	}
 
 	return item, nil
 }
 
-func (m memoryStorage) UpdateUser(ctx context.Context, item model.User) (string, error) {
-	_, ok := m.users[item.ID]
+func (m *memoryStorage) GetUsersByIDs(ctx context.Context, ids []string) (res []model.User, err error) {
+	m.logger.Debug("GetUsersByIDs", zap.Int("count", len(ids)))
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, id := range ids {
+		if user, ok := m.users[id]; ok {
+			res = append(res, user)
+		}
+	}
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	m.logger.Debug("GetUserByEmail")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if strings.EqualFold(user.Email, email) {
+			return user, nil
+		}
+	}
+
+	return model.User{}, auth.ErrNotFound
+}
+
+func (m *memoryStorage) UpdateUser(ctx context.Context, item model.User) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.users[item.ID]
 	if !ok {
-		return "", errors.New("entry with given id not found, nothing to update")
+		return "", auth.ErrNotFound
+	}
+	if item.Version != 0 && item.Version != current.Version {
+		return "", auth.ErrVersionMismatch
 	}
 
-	m.users[item.ID] = item
+	current.Name = item.Name
+	if item.Password != "" {
+		current.Password = item.Password
+	}
+	current.UpdatedAt = time.Now()
+	current.Version++
+	m.users[item.ID] = current
 
 	return item.ID, nil
 }
 
-func (m memoryStorage) DeleteUser(ctx context.Context, id string) (string, error) {
-	_, ok := m.users[id]
+func (m *memoryStorage) UpdateRole(ctx context.Context, id string, role model.Role) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.users[id]
 	if !ok {
-		return "", errors.New("item with given id not found, nothing to delete")
+		return "", auth.ErrNotFound
+	}
+
+	current.Role = role
+	current.UpdatedAt = time.Now()
+	current.Version++
+	m.users[id] = current
+
+	return id, nil
+}
+
+func (m *memoryStorage) HasRole(ctx context.Context, role model.Role) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, user := range m.users {
+		if user.Role == role {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func (m *memoryStorage) DeleteUser(ctx context.Context, id string, version int64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	user, ok := m.users[id]
+	if !ok {
+		return "", auth.ErrNotFound
+	}
+	if version != 0 && version != user.Version {
+		return "", auth.ErrVersionMismatch
 	}
 
 	delete(m.users, id)
 
 	return id, nil
 }
+
+func (m *memoryStorage) CreateSession(ctx context.Context, session model.Session) error {
+	m.logger.Debug("CreateSession")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.sessions[session.TokenHash] = session
+
+	return nil
+}
+
+func (m *memoryStorage) GetSession(ctx context.Context, tokenHash string) (model.Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, ok := m.sessions[tokenHash]
+	if !ok {
+		return session, auth.ErrNotFound
+	}
+
+	return session, nil
+}
+
+func (m *memoryStorage) DeleteSession(ctx context.Context, tokenHash string) error {
+	m.logger.Debug("DeleteSession")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	delete(m.sessions, tokenHash)
+
+	return nil
+}
+
+func (m *memoryStorage) DeleteUserSessions(ctx context.Context, userID string) error {
+	m.logger.Debug("DeleteUserSessions")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for hash, session := range m.sessions {
+		if session.UserID == userID {
+			delete(m.sessions, hash)
+		}
+	}
+
+	return nil
+}
+
+// less orders users by (created_at, id) like the postgres repository does.
+func less(a, b model.User) bool {
+	if !a.CreatedAt.Equal(b.CreatedAt) {
+		return a.CreatedAt.Before(b.CreatedAt)
+	}
+
+	return a.ID < b.ID
+}
@@ -0,0 +1,23 @@
+package memory_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/repository/memory"
+	"github.com/silverspase/todo/internal/modules/auth/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) auth.Repository {
+		return memory.NewMemoryStorage(zap.NewNop())
+	})
+}
+
+func TestAuditRepository(t *testing.T) {
+	repositorytest.RunAudit(t, func(t *testing.T) auth.AuditRepository {
+		return memory.NewAuditStorage(zap.NewNop())
+	})
+}
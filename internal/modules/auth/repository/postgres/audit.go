@@ -0,0 +1,60 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+type auditRepository struct {
+	conn   *gorm.DB
+	logger *zap.Logger
+}
+
+func NewAuditRepository(conn *gorm.DB, logger *zap.Logger) auth.AuditRepository {
+	return auditRepository{
+		conn:   conn,
+		logger: logger,
+	}
+}
+
+func (p auditRepository) AppendAudit(ctx context.Context, entry model.AuditEntry) error {
+	p.logger.Debug("AppendAudit", zap.String("action", string(entry.Action)))
+
+	if entry.CreatedAt.IsZero() {
+		entry.CreatedAt = time.Now()
+	}
+
+	return p.conn.WithContext(ctx).Create(&entry).Error
+}
+
+func (p auditRepository) GetAudit(ctx context.Context, query model.AuditQuery) (entries []model.AuditEntry, err error) {
+	p.logger.Debug("GetAudit", zap.Any("query", query))
+
+	db := p.conn.WithContext(ctx)
+	if query.ActorID != "" {
+		db = db.Where("actor_id = ?", query.ActorID)
+	}
+	if query.From != nil {
+		db = db.Where("created_at >= ?", *query.From)
+	}
+	if query.To != nil {
+		db = db.Where("created_at < ?", *query.To)
+	}
+	if query.After != nil {
+		db = db.Where("created_at < @created OR (created_at = @created AND id < @id)",
+			map[string]interface{}{"created": query.After.CreatedAt, "id": query.After.ID})
+	}
+
+	res := db.Order("created_at DESC, id DESC").Limit(query.PageSize).Find(&entries)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return entries, nil
+}
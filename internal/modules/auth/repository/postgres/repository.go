@@ -2,16 +2,19 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"time"
 
+	"github.com/glebarez/go-sqlite"
+	"github.com/jackc/pgconn"
 	"go.uber.org/zap"
 	"gorm.io/gorm"
+	sqlite3 "modernc.org/sqlite/lib"
 
 	"github.com/silverspase/todo/internal/modules/auth"
 	"github.com/silverspase/todo/internal/modules/auth/model"
 )
 
-const pageSize = 2
-
 type postgres struct {
 	conn   *gorm.DB
 	logger *zap.Logger
@@ -27,7 +30,11 @@ func NewRepository(conn *gorm.DB, logger *zap.Logger) auth.Repository {
 func (p postgres) CreateUser(ctx context.Context, entry model.User) (string, error) {
 	p.logger.Debug("CreateItem")
 
-	res := p.conn.Create(&entry)
+	entry.Version = 1
+	res := p.conn.WithContext(ctx).Create(&entry)
+	if uniqueViolation(res.Error) {
+		return "", auth.ErrEmailTaken
+	}
 	if res.Error != nil {
 		return "", res.Error
 	}
@@ -37,13 +44,16 @@ func (p postgres) CreateUser(ctx context.Context, entry model.User) (string, err
 	return entry.ID, nil
 }
 
-func (p postgres) GetAllUsers(ctx context.Context, page int) (entries []model.User, err error) {
-	p.logger.Debug("GetAllUsers", zap.Int("page", page))
-	if page < 1 {
-		page = 1
+func (p postgres) GetAllUsers(ctx context.Context, after *model.Cursor, limit int) (entries []model.User, err error) {
+	p.logger.Debug("GetAllUsers", zap.Any("after", after))
+
+	db := p.conn.WithContext(ctx)
+	if after != nil {
+		db = db.Where("created_at > @created OR (created_at = @created AND id > @id)",
+			map[string]interface{}{"created": after.CreatedAt, "id": after.ID})
 	}
 
-	res := p.conn.Limit(pageSize).Offset((page - 1) * pageSize).Find(&entries)
+	res := db.Order("created_at, id").Limit(limit).Find(&entries)
 	if res.Error != nil {
 		return nil, res.Error
 	}
@@ -55,8 +65,10 @@ func (p postgres) GetUser(ctx context.Context, id string) (model.User, error) {
 	p.logger.Debug("GetUser", zap.String("id", id))
 
 	item := model.User{ID: id}
-	err := p.conn.First(&item).Error
-
+	err := p.conn.WithContext(ctx).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, auth.ErrNotFound
+	}
 	if err != nil {
 		return item, err
 	}
@@ -64,30 +76,155 @@ func (p postgres) GetUser(ctx context.Context, id string) (model.User, error) {
 	return item, nil
 }
 
-func (p postgres) UpdateUser(ctx context.Context, newEntry model.User) (string, error) {
-	p.logger.Debug("UpdateUser", zap.String("id", newEntry.ID))
+func (p postgres) GetUsersByIDs(ctx context.Context, ids []string) (users []model.User, err error) {
+	p.logger.Debug("GetUsersByIDs", zap.Int("count", len(ids)))
+
+	err = p.conn.WithContext(ctx).Where("id IN ?", ids).Find(&users).Error
+	return users, err
+}
+
+func (p postgres) GetUserByEmail(ctx context.Context, email string) (model.User, error) {
+	p.logger.Debug("GetUserByEmail")
 
-	entry, err := p.GetUser(ctx, newEntry.ID)
+	var user model.User
+	err := p.conn.WithContext(ctx).Where("lower(email) = lower(?)", email).First(&user).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return user, auth.ErrNotFound
+	}
 	if err != nil {
-		return "", err
+		return user, err
 	}
 
-	entry.Name = newEntry.Name
-	err = p.conn.Save(&entry).Error
-	if err != nil {
-		return "", err
+	return user, nil
+}
+
+func (p postgres) UpdateUser(ctx context.Context, entry model.User) (string, error) {
+	p.logger.Debug("UpdateUser", zap.String("id", entry.ID))
+
+	fields := map[string]interface{}{
+		"name":       entry.Name,
+		"updated_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
+	}
+	if entry.Password != "" {
+		fields["password"] = entry.Password
+	}
+
+	db := p.conn.WithContext(ctx).Model(&model.User{}).Where("id = ?", entry.ID)
+	if entry.Version != 0 {
+		db = db.Where("version = ?", entry.Version)
+	}
+	res := db.Updates(fields)
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", p.changedOrMissing(ctx, entry.ID)
 	}
 
 	return entry.ID, nil
 }
 
-func (p postgres) DeleteUser(ctx context.Context, id string) (string, error) {
-	p.logger.Info("DeleteUser", zap.String("id", id))
+func (p postgres) UpdateRole(ctx context.Context, id string, role model.Role) (string, error) {
+	p.logger.Debug("UpdateRole", zap.String("id", id), zap.String("role", string(role)))
+
+	res := p.conn.WithContext(ctx).Model(&model.User{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"role":       role,
+		"updated_at": time.Now(),
+		"version":    gorm.Expr("version + 1"),
+	})
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", auth.ErrNotFound
+	}
+
+	return id, nil
+}
+
+func (p postgres) HasRole(ctx context.Context, role model.Role) (bool, error) {
+	p.logger.Debug("HasRole", zap.String("role", string(role)))
 
-	err := p.conn.Delete(&model.User{ID: id}).Error
+	var ids []string
+	err := p.conn.WithContext(ctx).Model(&model.User{}).Where("role = ?", role).Limit(1).Pluck("id", &ids).Error
 	if err != nil {
-		return "", err
+		return false, err
+	}
+
+	return len(ids) > 0, nil
+}
+
+func (p postgres) DeleteUser(ctx context.Context, id string, version int64) (string, error) {
+	p.logger.Info("DeleteUser", zap.String("id", id))
+
+	db := p.conn.WithContext(ctx)
+	if version != 0 {
+		db = db.Where("version = ?", version)
+	}
+	res := db.Delete(&model.User{ID: id})
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", p.changedOrMissing(ctx, id)
 	}
 
 	return id, nil
 }
+
+// changedOrMissing tells why a conditional statement affected no rows:
+// the user doesn't exist or its version doesn't match.
+func (p postgres) changedOrMissing(ctx context.Context, id string) error {
+	if _, err := p.GetUser(ctx, id); err != nil {
+		return err
+	}
+
+	return auth.ErrVersionMismatch
+}
+
+func (p postgres) CreateSession(ctx context.Context, session model.Session) error {
+	p.logger.Debug("CreateSession")
+
+	return p.conn.WithContext(ctx).Create(&session).Error
+}
+
+func (p postgres) GetSession(ctx context.Context, tokenHash string) (model.Session, error) {
+	var session model.Session
+	err := p.conn.WithContext(ctx).Where("token_hash = ?", tokenHash).First(&session).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return session, auth.ErrNotFound
+	}
+	if err != nil {
+		return session, err
+	}
+
+	return session, nil
+}
+
+func (p postgres) DeleteSession(ctx context.Context, tokenHash string) error {
+	p.logger.Debug("DeleteSession")
+
+	return p.conn.WithContext(ctx).Where("token_hash = ?", tokenHash).Delete(&model.Session{}).Error
+}
+
+func (p postgres) DeleteUserSessions(ctx context.Context, userID string) error {
+	p.logger.Debug("DeleteUserSessions", zap.String("user_id", userID))
+
+	return p.conn.WithContext(ctx).Where("user_id = ?", userID).Delete(&model.Session{}).Error
+}
+
+// uniqueViolation tells whether the error is of a unique index, the only one of the users
+// is on their emails.
+func uniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	if errors.As(err, &pgErr) {
+		return pgErr.Code == "23505" // unique_violation
+	}
+	var sqliteErr *sqlite.Error
+	if errors.As(err, &sqliteErr) {
+		return sqliteErr.Code() == sqlite3.SQLITE_CONSTRAINT_UNIQUE
+	}
+
+	return false
+}
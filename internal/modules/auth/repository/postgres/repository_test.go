@@ -0,0 +1,34 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/app/repository/sql/sqltest"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/repository/postgres"
+	"github.com/silverspase/todo/internal/modules/auth/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	for _, db := range sqltest.Databases() {
+		db := db
+		t.Run(db.Name, func(t *testing.T) {
+			repositorytest.Run(t, func(t *testing.T) auth.Repository {
+				return postgres.NewRepository(db.Open(t), zap.NewNop())
+			})
+		})
+	}
+}
+
+func TestAuditRepository(t *testing.T) {
+	for _, db := range sqltest.Databases() {
+		db := db
+		t.Run(db.Name, func(t *testing.T) {
+			repositorytest.RunAudit(t, func(t *testing.T) auth.AuditRepository {
+				return postgres.NewAuditRepository(db.Open(t), zap.NewNop())
+			})
+		})
+	}
+}
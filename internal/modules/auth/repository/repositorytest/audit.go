@@ -0,0 +1,165 @@
+package repositorytest
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+// RunAudit checks that the repositories returned by newRepository behave like auth.AuditRepository
+// documents. Every subtest gets a new repository.
+func RunAudit(t *testing.T, newRepository func(t *testing.T) auth.AuditRepository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo auth.AuditRepository)
+	}{
+		{"AppendAndGet", testAuditAppendAndGet},
+		{"Filters", testAuditFilters},
+		{"Pages", testAuditPages},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+var epoch = time.Date(2030, time.January, 1, 9, 0, 0, 0, time.UTC)
+
+func appendAudit(t *testing.T, repo auth.AuditRepository, entry model.AuditEntry) {
+	t.Helper()
+
+	if err := repo.AppendAudit(ctx, entry); err != nil {
+		t.Fatalf("AppendAudit: %v", err)
+	}
+}
+
+func getAudit(t *testing.T, repo auth.AuditRepository, query model.AuditQuery) []model.AuditEntry {
+	t.Helper()
+
+	if query.PageSize == 0 {
+		query.PageSize = 100
+	}
+	entries, err := repo.GetAudit(ctx, query)
+	if err != nil {
+		t.Fatalf("GetAudit: %v", err)
+	}
+
+	return entries
+}
+
+func actions(entries []model.AuditEntry) []string {
+	res := []string{}
+	for _, entry := range entries {
+		res = append(res, string(entry.Action))
+	}
+
+	return res
+}
+
+func expectActions(t *testing.T, entries []model.AuditEntry, want ...string) {
+	t.Helper()
+
+	if got := actions(entries); !reflect.DeepEqual(got, want) {
+		t.Errorf("got actions %q, want %q", got, want)
+	}
+}
+
+func testAuditAppendAndGet(t *testing.T, repo auth.AuditRepository) {
+	want := model.AuditEntry{
+		ActorID:   "actor",
+		TargetID:  "target",
+		Action:    model.AuditLogin,
+		Email:     "ann@example.com",
+		IP:        "192.0.2.1",
+		UserAgent: "curl/8.0",
+		Outcome:   model.AuditFailure,
+		Reason:    "unauthorized",
+	}
+	appendAudit(t, repo, want)
+
+	got := getAudit(t, repo, model.AuditQuery{})
+	if len(got) != 1 {
+		t.Fatalf("got %d entries, want 1", len(got))
+	}
+	if got[0].ID == "" || got[0].CreatedAt.IsZero() {
+		t.Errorf("got entry %+v, want the ID and the creation time filled in", got[0])
+	}
+	want.ID, want.CreatedAt = got[0].ID, got[0].CreatedAt
+	if got[0] != want {
+		t.Errorf("got entry %+v, want %+v", got[0], want)
+	}
+}
+
+func testAuditFilters(t *testing.T, repo auth.AuditRepository) {
+	for i, action := range []model.AuditAction{
+		model.AuditUserCreated, model.AuditLogin, model.AuditUserUpdated, model.AuditUserDeleted,
+	} {
+		actor := "ann"
+		if i%2 == 1 {
+			actor = "bob"
+		}
+		appendAudit(t, repo, model.AuditEntry{
+			ActorID:   actor,
+			Action:    action,
+			Outcome:   model.AuditSuccess,
+			CreatedAt: epoch.Add(time.Duration(i) * time.Hour),
+		})
+	}
+	from, to := epoch.Add(time.Hour), epoch.Add(3*time.Hour)
+
+	tests := []struct {
+		name  string
+		query model.AuditQuery
+		want  []string
+	}{
+		{"all", model.AuditQuery{}, []string{"user_deleted", "user_updated", "login", "user_created"}},
+		{"actor", model.AuditQuery{ActorID: "ann"}, []string{"user_updated", "user_created"}},
+		{"from inclusive", model.AuditQuery{From: &from}, []string{"user_deleted", "user_updated", "login"}},
+		{"to exclusive", model.AuditQuery{To: &to}, []string{"user_updated", "login", "user_created"}},
+		{"range and actor", model.AuditQuery{ActorID: "bob", From: &from, To: &to}, []string{"login"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			expectActions(t, getAudit(t, repo, tt.query), tt.want...)
+		})
+	}
+}
+
+func testAuditPages(t *testing.T, repo auth.AuditRepository) {
+	// the entries made at the same time are ordered by ID
+	for i := 0; i < 5; i++ {
+		appendAudit(t, repo, model.AuditEntry{
+			ID:        fmt.Sprintf("entry-%d", i),
+			Action:    model.AuditLogin,
+			Outcome:   model.AuditSuccess,
+			CreatedAt: epoch.Add(time.Duration(i/2) * time.Minute),
+		})
+	}
+
+	var ids []string
+	query := model.AuditQuery{PageSize: 2}
+	for page := 0; page < 5; page++ {
+		entries := getAudit(t, repo, query)
+		if len(entries) == 0 {
+			break
+		}
+		for _, entry := range entries {
+			ids = append(ids, entry.ID)
+		}
+		last := entries[len(entries)-1]
+		query.After = &model.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	want := []string{"entry-4", "entry-3", "entry-2", "entry-1", "entry-0"}
+	if !reflect.DeepEqual(ids, want) {
+		t.Errorf("got pages of %q, want %q", ids, want)
+	}
+}
@@ -0,0 +1,376 @@
+// Package repositorytest is the conformance suite of the auth.Repository implementations.
+package repositorytest
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+)
+
+// Run checks that the repositories returned by newRepository behave like auth.Repository
+// documents. Every subtest gets a new repository.
+func Run(t *testing.T, newRepository func(t *testing.T) auth.Repository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo auth.Repository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"GetByEmail", testGetByEmail},
+		{"GetByIDs", testGetByIDs},
+		{"Pages", testPages},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"Versions", testVersions},
+		{"Roles", testRoles},
+		{"Sessions", testSessions},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+var ctx = context.Background()
+
+func create(t *testing.T, repo auth.Repository, name string) model.User {
+	t.Helper()
+
+	id, err := repo.CreateUser(ctx, model.User{
+		Name:     name,
+		Email:    name + "@example.com",
+		Gender:   "other",
+		Role:     model.RoleMember,
+		Password: "hash of " + name,
+	})
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	if id == "" {
+		t.Fatal("CreateUser returned an empty ID")
+	}
+
+	user, err := repo.GetUser(ctx, id)
+	if err != nil {
+		t.Fatalf("GetUser(%s): %v", id, err)
+	}
+
+	return user
+}
+
+func names(users []model.User) []string {
+	res := make([]string, 0, len(users))
+	for _, user := range users {
+		res = append(res, user.Name)
+	}
+
+	return res
+}
+
+func testCreateAndGet(t *testing.T, repo auth.Repository) {
+	before := time.Now().Add(-time.Second)
+	ann := create(t, repo, "ann")
+	bob := create(t, repo, "bob")
+
+	if ann.Name != "ann" || ann.Email != "ann@example.com" || ann.Gender != "other" || ann.Password != "hash of ann" {
+		t.Errorf("fields not stored: %+v", ann)
+	}
+	if ann.CreatedAt.Before(before) || ann.UpdatedAt.Before(before) {
+		t.Errorf("timestamps not set: created %v, updated %v", ann.CreatedAt, ann.UpdatedAt)
+	}
+	if ann.ID == bob.ID {
+		t.Error("users got the same ID")
+	}
+
+	if _, err := repo.GetUser(ctx, uuid.New().String()); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("GetUser of an unknown ID: got %v, want ErrNotFound", err)
+	}
+}
+
+func testGetByEmail(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+	create(t, repo, "bob")
+
+	user, err := repo.GetUserByEmail(ctx, "ann@example.com")
+	if err != nil || user.ID != ann.ID {
+		t.Errorf("GetUserByEmail: got %+v, %v, want ann", user, err)
+	}
+	if user, err = repo.GetUserByEmail(ctx, "Ann@Example.com"); err != nil || user.ID != ann.ID {
+		t.Errorf("GetUserByEmail in another case: got %+v, %v, want ann", user, err)
+	}
+	if _, err = repo.GetUserByEmail(ctx, "nobody@example.com"); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("GetUserByEmail of an unknown email: got %v, want ErrNotFound", err)
+	}
+
+	_, err = repo.CreateUser(ctx, model.User{Name: "Ann", Email: "ANN@example.com", Role: model.RoleMember})
+	if !errors.Is(err, auth.ErrEmailTaken) {
+		t.Errorf("CreateUser with a taken email: got %v, want ErrEmailTaken", err)
+	}
+
+	// the email of a deleted user is free again
+	if _, err = repo.DeleteUser(ctx, ann.ID, 0); err != nil {
+		t.Fatalf("DeleteUser: %v", err)
+	}
+	if _, err = repo.CreateUser(ctx, model.User{Name: "Ann", Email: "ann@example.com", Role: model.RoleMember}); err != nil {
+		t.Errorf("CreateUser with the email of a deleted user: %v", err)
+	}
+}
+
+func testGetByIDs(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+	bob := create(t, repo, "bob")
+	create(t, repo, "cid")
+
+	users, err := repo.GetUsersByIDs(ctx, []string{bob.ID, uuid.New().String(), ann.ID})
+	if err != nil {
+		t.Fatalf("GetUsersByIDs: %v", err)
+	}
+	got := names(users)
+	sort.Strings(got)
+	if !reflect.DeepEqual(got, []string{"ann", "bob"}) {
+		t.Errorf("got users %q, want [ann bob]", got)
+	}
+
+	if users, err = repo.GetUsersByIDs(ctx, nil); err != nil || len(users) != 0 {
+		t.Errorf("GetUsersByIDs without IDs: got %q, %v", names(users), err)
+	}
+}
+
+func testPages(t *testing.T, repo auth.Repository) {
+	var want []model.User
+	for i := 0; i < 5; i++ {
+		want = append(want, create(t, repo, fmt.Sprintf("user%d", i)))
+	}
+	sort.Slice(want, func(i, j int) bool {
+		if !want[i].CreatedAt.Equal(want[j].CreatedAt) {
+			return want[i].CreatedAt.Before(want[j].CreatedAt)
+		}
+		return want[i].ID < want[j].ID
+	})
+
+	var got []model.User
+	var after *model.Cursor
+	for i := 0; i < 5; i++ {
+		page, err := repo.GetAllUsers(ctx, after, 2)
+		if err != nil {
+			t.Fatalf("GetAllUsers: %v", err)
+		}
+		if len(page) > 2 {
+			t.Fatalf("got a page of %d users, want at most 2", len(page))
+		}
+		got = append(got, page...)
+		if len(page) < 2 {
+			break
+		}
+		last := page[len(page)-1]
+		after = &model.Cursor{CreatedAt: last.CreatedAt, ID: last.ID}
+	}
+
+	if !reflect.DeepEqual(names(got), names(want)) {
+		t.Errorf("paged %q, want %q ordered by (created_at, id)", names(got), names(want))
+	}
+}
+
+func testUpdate(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+
+	update := model.User{ID: ann.ID, Name: "Ann", Email: "changed@example.com", Gender: "changed"}
+	id, err := repo.UpdateUser(ctx, update)
+	if err != nil || id != ann.ID {
+		t.Fatalf("UpdateUser: got %q, %v", id, err)
+	}
+
+	got, err := repo.GetUser(ctx, ann.ID)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got.Name != "Ann" {
+		t.Errorf("got name %q, want Ann", got.Name)
+	}
+	if got.Email != ann.Email || got.Gender != ann.Gender || got.Password != ann.Password {
+		t.Errorf("UpdateUser changed more than the name: %+v", got)
+	}
+	if !got.CreatedAt.Equal(ann.CreatedAt) {
+		t.Errorf("created_at changed from %v to %v", ann.CreatedAt, got.CreatedAt)
+	}
+
+	update.Password = "new hash"
+	if _, err = repo.UpdateUser(ctx, update); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if got, _ = repo.GetUser(ctx, ann.ID); got.Password != "new hash" {
+		t.Errorf("password hash not updated: %q", got.Password)
+	}
+
+	update.ID = uuid.New().String()
+	if _, err = repo.UpdateUser(ctx, update); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("UpdateUser of an unknown ID: got %v, want ErrNotFound", err)
+	}
+}
+
+func testRoles(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+	if ann.Role != model.RoleMember {
+		t.Errorf("got role %q, want the created one", ann.Role)
+	}
+	if has, err := repo.HasRole(ctx, model.RoleAdmin); err != nil || has {
+		t.Fatalf("HasRole(admin) before granting it: got %v, %v", has, err)
+	}
+
+	id, err := repo.UpdateRole(ctx, ann.ID, model.RoleAdmin)
+	if err != nil || id != ann.ID {
+		t.Fatalf("UpdateRole: got %q, %v", id, err)
+	}
+	got, err := repo.GetUser(ctx, ann.ID)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	if got.Role != model.RoleAdmin || got.Version != ann.Version+1 {
+		t.Errorf("got role %q of version %d, want admin of version %d", got.Role, got.Version, ann.Version+1)
+	}
+	if has, err := repo.HasRole(ctx, model.RoleAdmin); err != nil || !has {
+		t.Errorf("HasRole(admin): got %v, %v", has, err)
+	}
+
+	// the role is granted by UpdateRole only
+	if _, err = repo.UpdateUser(ctx, model.User{ID: ann.ID, Name: "Ann", Role: model.RoleReadOnly}); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if got, _ = repo.GetUser(ctx, ann.ID); got.Role != model.RoleAdmin {
+		t.Errorf("UpdateUser changed the role to %q", got.Role)
+	}
+
+	if _, err = repo.UpdateRole(ctx, uuid.New().String(), model.RoleAdmin); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("UpdateRole of an unknown ID: got %v, want ErrNotFound", err)
+	}
+}
+
+func testDelete(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+	create(t, repo, "bob")
+
+	id, err := repo.DeleteUser(ctx, ann.ID, 0)
+	if err != nil || id != ann.ID {
+		t.Fatalf("DeleteUser: got %q, %v", id, err)
+	}
+
+	if _, err = repo.GetUser(ctx, ann.ID); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("GetUser of a deleted user: got %v, want ErrNotFound", err)
+	}
+	if _, err = repo.GetUserByEmail(ctx, ann.Email); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("GetUserByEmail of a deleted user: got %v, want ErrNotFound", err)
+	}
+	users, err := repo.GetAllUsers(ctx, nil, 10)
+	if err != nil || !reflect.DeepEqual(names(users), []string{"bob"}) {
+		t.Errorf("GetAllUsers: got %q, %v, want [bob]", names(users), err)
+	}
+	if _, err = repo.DeleteUser(ctx, ann.ID, 0); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("DeleteUser of a deleted user: got %v, want ErrNotFound", err)
+	}
+}
+
+func testVersions(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+	if ann.Version != 1 {
+		t.Errorf("created with version %d, want 1", ann.Version)
+	}
+
+	update := model.User{ID: ann.ID, Name: "Ann", Version: 1}
+	if _, err := repo.UpdateUser(ctx, update); err != nil {
+		t.Fatalf("UpdateUser: %v", err)
+	}
+	if got, _ := repo.GetUser(ctx, ann.ID); got.Version != 2 {
+		t.Errorf("got version %d after the update, want 2", got.Version)
+	}
+
+	update.Name = "Lost"
+	if _, err := repo.UpdateUser(ctx, update); !errors.Is(err, auth.ErrVersionMismatch) {
+		t.Errorf("UpdateUser of a stale version: got %v, want ErrVersionMismatch", err)
+	}
+	if got, _ := repo.GetUser(ctx, ann.ID); got.Name != "Ann" {
+		t.Errorf("stale update applied: %+v", got)
+	}
+
+	update.Version = 0
+	update.Name = "Forced"
+	if _, err := repo.UpdateUser(ctx, update); err != nil {
+		t.Fatalf("UpdateUser without a version: %v", err)
+	}
+	if got, _ := repo.GetUser(ctx, ann.ID); got.Name != "Forced" || got.Version != 3 {
+		t.Errorf("got %q of version %d, want Forced of version 3", got.Name, got.Version)
+	}
+
+	if _, err := repo.DeleteUser(ctx, ann.ID, 2); !errors.Is(err, auth.ErrVersionMismatch) {
+		t.Errorf("DeleteUser of a stale version: got %v, want ErrVersionMismatch", err)
+	}
+	if _, err := repo.DeleteUser(ctx, ann.ID, 3); err != nil {
+		t.Fatalf("DeleteUser of the current version: %v", err)
+	}
+	if _, err := repo.DeleteUser(ctx, ann.ID, 3); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("DeleteUser of a deleted user: got %v, want ErrNotFound", err)
+	}
+}
+
+func testSessions(t *testing.T, repo auth.Repository) {
+	ann := create(t, repo, "ann")
+	expiresAt := time.Date(2030, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+	err := repo.CreateSession(ctx, model.Session{TokenHash: "hash", UserID: ann.ID, ExpiresAt: expiresAt})
+	if err != nil {
+		t.Fatalf("CreateSession: %v", err)
+	}
+
+	session, err := repo.GetSession(ctx, "hash")
+	if err != nil {
+		t.Fatalf("GetSession: %v", err)
+	}
+	if session.UserID != ann.ID || !session.ExpiresAt.Equal(expiresAt) {
+		t.Errorf("session not stored: %+v", session)
+	}
+	if _, err = repo.GetSession(ctx, "other"); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("GetSession of an unknown hash: got %v, want ErrNotFound", err)
+	}
+
+	if err = repo.DeleteSession(ctx, "hash"); err != nil {
+		t.Fatalf("DeleteSession: %v", err)
+	}
+	if _, err = repo.GetSession(ctx, "hash"); !errors.Is(err, auth.ErrNotFound) {
+		t.Errorf("GetSession of a deleted session: got %v, want ErrNotFound", err)
+	}
+	if err = repo.DeleteSession(ctx, "hash"); err != nil {
+		t.Errorf("DeleteSession of a deleted session: %v", err)
+	}
+
+	bob := create(t, repo, "bob")
+	for _, session := range []model.Session{
+		{TokenHash: "ann1", UserID: ann.ID, ExpiresAt: expiresAt},
+		{TokenHash: "ann2", UserID: ann.ID, ExpiresAt: expiresAt},
+		{TokenHash: "bob", UserID: bob.ID, ExpiresAt: expiresAt},
+	} {
+		if err = repo.CreateSession(ctx, session); err != nil {
+			t.Fatalf("CreateSession: %v", err)
+		}
+	}
+	if err = repo.DeleteUserSessions(ctx, ann.ID); err != nil {
+		t.Fatalf("DeleteUserSessions: %v", err)
+	}
+	for _, hash := range []string{"ann1", "ann2"} {
+		if _, err = repo.GetSession(ctx, hash); !errors.Is(err, auth.ErrNotFound) {
+			t.Errorf("GetSession of %s after DeleteUserSessions: got %v, want ErrNotFound", hash, err)
+		}
+	}
+	if _, err = repo.GetSession(ctx, "bob"); err != nil {
+		t.Errorf("GetSession of another user's session: %v", err)
+	}
+}
@@ -9,5 +9,26 @@ type Transport interface {
 	GetAllUsers(w http.ResponseWriter, r *http.Request)
 	GetUser(w http.ResponseWriter, r *http.Request)
 	UpdateUser(w http.ResponseWriter, r *http.Request)
+	// PatchUser accepts JSON Merge Patch and JSON Patch documents, told by the Content-Type.
+	PatchUser(w http.ResponseWriter, r *http.Request)
 	DeleteUser(w http.ResponseWriter, r *http.Request)
+	// SetRole grants the user the role of the {"role": ...} body.
+	SetRole(w http.ResponseWriter, r *http.Request)
+
+	// GetAudit lists the audit log, filtered by the from, to and actor_id query params.
+	GetAudit(w http.ResponseWriter, r *http.Request)
+
+	Login(w http.ResponseWriter, r *http.Request)
+	Logout(w http.ResponseWriter, r *http.Request)
+	// Authenticate is a middleware rejecting requests without a valid bearer token.
+	Authenticate(next http.Handler) http.Handler
+	// AuthenticateStream is Authenticate of the event streams, which also accepts the token
+	// as the access_token query param.
+	AuthenticateStream(next http.Handler) http.Handler
+	// Identify is a middleware authenticating the requests with a bearer token like Authenticate,
+	// the requests without a valid one pass through anonymous.
+	Identify(next http.Handler) http.Handler
+	// Client is a middleware putting the address and the user agent of the client into
+	// the request context, for the audit log.
+	Client(next http.Handler) http.Handler
 }
@@ -1,19 +1,44 @@
 package gorilla_mux
 
 import (
-	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
 
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/etag"
+	"github.com/silverspase/todo/internal/httpjson"
 	"github.com/silverspase/todo/internal/modules/auth"
 	"github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/problem"
+	"github.com/silverspase/todo/internal/validate"
 )
 
+// errors of the requests which don't reach the use case
+var (
+	errInvalidPayload = errs.New(errs.Validation, "invalid request payload")
+	errMissingID      = errs.New(errs.Validation, "missed id path param")
+)
+
+// userRequest lets the client send a password, which model.User never serializes.
+type userRequest struct {
+	model.User
+	Password string `json:"password"`
+}
+
+type credentials struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
 type transport struct {
 	useCase auth.UseCase
 	logger  *zap.Logger
@@ -28,19 +53,20 @@ func NewTransport(logger *zap.Logger, useCase auth.UseCase) auth.Transport {
 
 func (t *transport) CreateUser(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("CreateUser")
-	ctx := context.Background()
+	ctx := r.Context()
 	defer r.Body.Close()
 
-	var user model.User
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&user); err != nil {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	var req userRequest
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
+	user := req.User
+	user.Password = req.Password
 	id, err := t.useCase.CreateUser(ctx, user)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
@@ -49,45 +75,48 @@ func (t *transport) CreateUser(w http.ResponseWriter, r *http.Request) {
 
 func (t *transport) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("GetAllUsers")
-	ctx := context.Background()
+	ctx := r.Context()
 
-	var page int
+	var pageSize int
 	var err error
 
-	pageStr := r.FormValue("page")
-	if pageStr == "" {
-		page = 1
-	} else {
-		page, err = strconv.Atoi(pageStr)
+	if s := r.FormValue("page_size"); s != "" {
+		pageSize, err = strconv.Atoi(s)
 		if err != nil {
-			respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "page param is not a number"})
+			problem.Write(t.logger, w, r, errs.New(errs.Validation, "page_size param is not a number"))
 			return
 		}
 	}
 
-	users, err := t.useCase.GetAllUsers(ctx, page)
+	page, err := t.useCase.GetAllUsers(ctx, r.FormValue("cursor"), pageSize)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, users)
+	respondWithJSON(w, http.StatusOK, page)
 }
 
 func (t *transport) GetUser(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("GetUser")
-	ctx := context.Background()
+	ctx := r.Context()
 
 	params := mux.Vars(r)
 	id := params["id"]
 	if id == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "missed id path param"})
+		problem.Write(t.logger, w, r, errMissingID)
 		return
 	}
 
 	item, err := t.useCase.GetUser(ctx, id)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("unable to get entry with id %v: %v", id, err)})
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to get user with id %v: %w", id, err))
+		return
+	}
+
+	w.Header().Set("ETag", etag.Format(item.Version))
+	if etag.NoneMatch(r, item.Version) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -96,52 +125,291 @@ func (t *transport) GetUser(w http.ResponseWriter, r *http.Request) {
 
 func (t *transport) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("UpdateUser")
-	ctx := context.Background()
+	ctx := r.Context()
 	defer r.Body.Close()
 
 	params := mux.Vars(r)
 	id := params["id"]
 	if id == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "missed id path param"})
+		problem.Write(t.logger, w, r, errMissingID)
 		return
 	}
 
-	var item model.User
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&item); err != nil {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"unmarshal error": "Invalid request payload"})
+	var req userRequest
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
+	item := req.User
+	item.Password = req.Password
 	item.ID = id
+	item.Version = etag.IfMatch(r)
 	id, err := t.useCase.UpdateUser(ctx, item)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		problem.Write(t.logger, w, r, err)
 		return
 	}
+	if item.Version > 0 {
+		// the precondition held, so this update made the next version
+		w.Header().Set("ETag", etag.Format(item.Version+1))
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+}
+
+func (t *transport) PatchUser(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("PatchUser")
+	ctx := r.Context()
+	defer r.Body.Close()
+	httpjson.AcceptPatch(w)
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	patch, err := httpjson.DecodePatch(r)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	version := etag.IfMatch(r)
+	id, err = t.useCase.PatchUser(ctx, id, patch, version)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+	if version > 0 {
+		w.Header().Set("ETag", etag.Format(version+1))
+	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
 }
 
 func (t *transport) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("DeleteUser")
-	ctx := context.Background()
+	ctx := r.Context()
 
 	params := mux.Vars(r)
 	id := params["id"]
 	if id == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "missed id path param"})
+		problem.Write(t.logger, w, r, errMissingID)
 		return
 	}
-	id, err := t.useCase.DeleteUser(ctx, id)
+	id, err := t.useCase.DeleteUser(ctx, id, etag.IfMatch(r))
 	if err != nil {
-		respondWithJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("item with id %v not found", id)})
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to delete user with id %v: %w", id, err))
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"status": "deleted", "id": id})
 }
 
+func (t *transport) SetRole(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("SetRole")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var req struct {
+		Role model.Role `json:"role"`
+	}
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	id, err := t.useCase.SetRole(ctx, id, req.Role)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+}
+
+func (t *transport) GetAudit(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetAudit")
+
+	query, err := parseAuditQuery(r)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	page, err := t.useCase.GetAudit(r.Context(), query)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, page)
+}
+
+// parseAuditQuery reads the GET /admin/audit params: actor_id, from, to (RFC 3339), cursor and page_size.
+func parseAuditQuery(r *http.Request) (model.AuditQuery, error) {
+	var v validate.Validator
+	values := r.URL.Query()
+
+	query := model.AuditQuery{
+		ActorID: values.Get("actor_id"),
+		From:    parseTime(&v, values.Get("from"), "from"),
+		To:      parseTime(&v, values.Get("to"), "to"),
+		Cursor:  values.Get("cursor"),
+	}
+	if s := values.Get("page_size"); s != "" {
+		var err error
+		query.PageSize, err = strconv.Atoi(s)
+		v.Check("page_size", err == nil, validate.CodeType, "page_size param is not a number")
+	}
+
+	return query, v.Err(auth.ErrInvalidQuery)
+}
+
+func parseTime(v *validate.Validator, s, param string) *time.Time {
+	if s == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		v.Add(param, validate.CodeFormat, param+" param is not an RFC 3339 timestamp")
+		return nil
+	}
+
+	return &t
+}
+
+func (t *transport) Login(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("Login")
+	defer r.Body.Close()
+
+	var creds credentials
+	if err := httpjson.Decode(r, &creds, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	token, err := t.useCase.Login(r.Context(), creds.Email, creds.Password)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, token)
+}
+
+func (t *transport) Logout(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("Logout")
+
+	token := bearerToken(r)
+	if token == "" {
+		t.unauthorized(w, r)
+		return
+	}
+
+	if err := t.useCase.Logout(r.Context(), token); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "logged out"})
+}
+
+func (t *transport) Authenticate(next http.Handler) http.Handler {
+	return t.authenticate(next, bearerToken, true)
+}
+
+func (t *transport) AuthenticateStream(next http.Handler) http.Handler {
+	return t.authenticate(next, streamToken, true)
+}
+
+func (t *transport) Identify(next http.Handler) http.Handler {
+	return t.authenticate(next, bearerToken, false)
+}
+
+// authenticate puts the user of the request's token into the request context.
+// The requests without a valid token are rejected when it's required.
+func (t *transport) authenticate(next http.Handler, tokenOf func(*http.Request) string, required bool) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		token := tokenOf(r)
+		if token == "" && !required {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if token == "" {
+			t.unauthorized(w, r)
+			return
+		}
+
+		user, err := t.useCase.Authenticate(r.Context(), token)
+		if errors.Is(err, auth.ErrUnauthorized) && !required {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if errors.Is(err, auth.ErrUnauthorized) {
+			t.unauthorized(w, r)
+			return
+		}
+		if err != nil {
+			problem.Write(t.logger, w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewContext(r.Context(), user)))
+	})
+}
+
+func (t *transport) Client(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// the address of the peer, the service trusts no proxy headers
+		ip, _, err := net.SplitHostPort(r.RemoteAddr)
+		if err != nil {
+			ip = r.RemoteAddr
+		}
+		client := model.Client{IP: ip, UserAgent: r.UserAgent()}
+
+		next.ServeHTTP(w, r.WithContext(auth.NewClientContext(r.Context(), client)))
+	})
+}
+
+// bearerToken extracts the token from the "Authorization: Bearer <token>" header.
+func bearerToken(r *http.Request) string {
+	const prefix = "bearer "
+	header := r.Header.Get("Authorization")
+	if len(header) < len(prefix) || !strings.EqualFold(header[:len(prefix)], prefix) {
+		return ""
+	}
+
+	return strings.TrimSpace(header[len(prefix):])
+}
+
+// streamToken is the bearer token, or the access_token query param (RFC 6750, section 2.3):
+// browsers can't set headers on EventSource and WebSocket connections. The URLs end up
+// in the logs, so the other routes don't accept it.
+func streamToken(r *http.Request) string {
+	if token := bearerToken(r); token != "" {
+		return token
+	}
+
+	return r.URL.Query().Get("access_token")
+}
+
+func (t *transport) unauthorized(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("WWW-Authenticate", `Bearer realm="todo"`)
+	problem.Write(t.logger, w, r, auth.ErrUnauthorized)
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 
@@ -0,0 +1,206 @@
+package grpc
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"go.uber.org/zap"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/silverspase/todo/internal/grpcstatus"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/modules/auth/transport/grpc/pb"
+)
+
+type transport struct {
+	pb.UnimplementedUserServiceServer
+
+	useCase auth.UseCase
+	logger  *zap.Logger
+}
+
+func NewTransport(logger *zap.Logger, useCase auth.UseCase) pb.UserServiceServer {
+	return &transport{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (t *transport) CreateUser(ctx context.Context, req *pb.UserRequest) (*pb.UserID, error) {
+	t.logger.Debug("grpc.CreateUser")
+
+	id, err := t.useCase.CreateUser(ctx, fromProto(req))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &pb.UserID{Id: id}, nil
+}
+
+func (t *transport) ListUsers(ctx context.Context, req *pb.ListUsersRequest) (*pb.ListUsersResponse, error) {
+	t.logger.Debug("grpc.ListUsers")
+
+	page, err := t.useCase.GetAllUsers(ctx, req.GetCursor(), int(req.GetPageSize()))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	res := &pb.ListUsersResponse{NextCursor: page.NextCursor}
+	for _, user := range page.Items {
+		res.Users = append(res.Users, toProto(user))
+	}
+
+	return res, nil
+}
+
+func (t *transport) GetUser(ctx context.Context, req *pb.UserID) (*pb.User, error) {
+	t.logger.Debug("grpc.GetUser")
+
+	user, err := t.useCase.GetUser(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return toProto(user), nil
+}
+
+func (t *transport) UpdateUser(ctx context.Context, req *pb.UserRequest) (*pb.UserID, error) {
+	t.logger.Debug("grpc.UpdateUser")
+
+	id, err := t.useCase.UpdateUser(ctx, fromProto(req))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &pb.UserID{Id: id}, nil
+}
+
+func (t *transport) DeleteUser(ctx context.Context, req *pb.UserID) (*emptypb.Empty, error) {
+	t.logger.Debug("grpc.DeleteUser")
+
+	if _, err := t.useCase.DeleteUser(ctx, req.GetId(), 0); err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (t *transport) SetRole(ctx context.Context, req *pb.SetRoleRequest) (*pb.UserID, error) {
+	t.logger.Debug("grpc.SetRole")
+
+	id, err := t.useCase.SetRole(ctx, req.GetId(), model.Role(req.GetRole()))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &pb.UserID{Id: id}, nil
+}
+
+func (t *transport) Login(ctx context.Context, req *pb.LoginRequest) (*pb.Token, error) {
+	t.logger.Debug("grpc.Login")
+
+	token, err := t.useCase.Login(ctx, req.GetEmail(), req.GetPassword())
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &pb.Token{
+		AccessToken: token.AccessToken,
+		TokenType:   token.TokenType,
+		ExpiresAt:   timestamppb.New(token.ExpiresAt),
+	}, nil
+}
+
+func (t *transport) Logout(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	t.logger.Debug("grpc.Logout")
+
+	token := bearerToken(ctx)
+	if token == "" {
+		return nil, grpcstatus.FromError(t.logger, auth.ErrUnauthorized)
+	}
+	if err := t.useCase.Logout(ctx, token); err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// UnaryInterceptor authenticates the "authorization: Bearer <token>" metadata and puts
+// the user into the context, like the Authenticate middleware of the HTTP transport.
+// Calls without the metadata pass through, the use cases which need a user refuse them.
+// The address and the user agent of the client are put into the context too.
+func UnaryInterceptor(logger *zap.Logger, useCase auth.UseCase) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = auth.NewClientContext(ctx, client(ctx))
+
+		token := bearerToken(ctx)
+		if token == "" {
+			return handler(ctx, req)
+		}
+
+		user, err := useCase.Authenticate(ctx, token)
+		if err != nil {
+			return nil, grpcstatus.FromError(logger, err)
+		}
+
+		return handler(auth.NewContext(ctx, user), req)
+	}
+}
+
+// client reads the address of the peer and the user-agent metadata.
+func client(ctx context.Context) model.Client {
+	var res model.Client
+	if p, ok := peer.FromContext(ctx); ok && p.Addr != nil {
+		res.IP = p.Addr.String()
+		if host, _, err := net.SplitHostPort(res.IP); err == nil {
+			res.IP = host
+		}
+	}
+	md, _ := metadata.FromIncomingContext(ctx)
+	if agents := md.Get("user-agent"); len(agents) > 0 {
+		res.UserAgent = agents[0]
+	}
+
+	return res
+}
+
+// bearerToken extracts the token from the "authorization: Bearer <token>" metadata.
+func bearerToken(ctx context.Context) string {
+	const prefix = "bearer "
+	md, _ := metadata.FromIncomingContext(ctx)
+	for _, header := range md.Get("authorization") {
+		if len(header) >= len(prefix) && strings.EqualFold(header[:len(prefix)], prefix) {
+			return strings.TrimSpace(header[len(prefix):])
+		}
+	}
+
+	return ""
+}
+
+func toProto(user model.User) *pb.User {
+	return &pb.User{
+		Id:        user.ID,
+		Name:      user.Name,
+		Email:     user.Email,
+		Gender:    user.Gender,
+		Role:      string(user.Role),
+		CreatedAt: timestamppb.New(user.CreatedAt),
+		UpdatedAt: timestamppb.New(user.UpdatedAt),
+	}
+}
+
+func fromProto(req *pb.UserRequest) model.User {
+	return model.User{
+		ID:       req.GetId(),
+		Name:     req.GetName(),
+		Email:    req.GetEmail(),
+		Gender:   req.GetGender(),
+		Password: req.GetPassword(),
+	}
+}
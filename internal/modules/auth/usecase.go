@@ -3,13 +3,39 @@ package auth
 import (
 	"context"
 
+	"github.com/silverspase/todo/internal/jsonpatch"
 	"github.com/silverspase/todo/internal/modules/auth/model"
 )
 
+// UseCase records the attempts to create, update and delete users and to log in
+// in the audit log, with the user and the client of ctx (see NewContext, NewClientContext).
+// The users read any user and change themselves, listing and changing the others requires
+// PermissionManageUsers, and reading the audit log PermissionReadAudit; ErrForbidden
+// is returned otherwise.
 type UseCase interface {
 	CreateUser(ctx context.Context, items model.User) (string, error)
-	GetAllUsers(ctx context.Context, page int) ([]model.User, error)
+	GetAllUsers(ctx context.Context, cursor string, pageSize int) (model.Page, error)
 	GetUser(ctx context.Context, id string) (model.User, error)
+	// GetUsersByIDs loads several users at once, unknown IDs are skipped.
+	GetUsersByIDs(ctx context.Context, ids []string) ([]model.User, error)
+	// UpdateUser and DeleteUser fail with ErrVersionMismatch when a non-zero version
+	// isn't the current version of the user. A new password revokes the user's sessions.
 	UpdateUser(ctx context.Context, item model.User) (string, error)
-	DeleteUser(ctx context.Context, id string) (string, error)
+	DeleteUser(ctx context.Context, id string, version int64) (string, error)
+	// PatchUser applies the patch to the user's name, email, gender and password, the email
+	// and the gender are read-only. The result is saved as UpdateUser does.
+	PatchUser(ctx context.Context, id string, patch jsonpatch.Patch, version int64) (string, error)
+	// SetRole grants the user the role.
+	SetRole(ctx context.Context, id string, role model.Role) (string, error)
+	// Bootstrap makes sure there is an admin who can grant the roles: when there is none, the
+	// user with the admin's email is promoted, or the admin is created. The users created with
+	// CreateUser are members.
+	Bootstrap(ctx context.Context, admin model.User) error
+
+	// GetAudit returns a page of the audit log, the latest entries first.
+	GetAudit(ctx context.Context, query model.AuditQuery) (model.AuditPage, error)
+
+	Login(ctx context.Context, email, password string) (model.Token, error)
+	Logout(ctx context.Context, token string) error
+	Authenticate(ctx context.Context, token string) (model.User, error)
 }
@@ -0,0 +1,81 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/cursor"
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+func (u useCase) GetAudit(ctx context.Context, query model.AuditQuery) (model.AuditPage, error) {
+	if _, err := auth.Authorize(ctx, auth.PermissionReadAudit); err != nil {
+		return model.AuditPage{}, err
+	}
+
+	var v validate.Validator
+	v.Check("to", query.From == nil || query.To == nil || query.From.Before(*query.To),
+		validate.CodeInvalid, "from must be before to")
+	if query.PageSize == 0 {
+		query.PageSize = defaultPageSize
+	}
+	v.Check("page_size", query.PageSize > 0 && query.PageSize <= maxPageSize, validate.CodeInvalid,
+		fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+	if query.Cursor != "" {
+		query.After = &model.Cursor{}
+		v.Check("cursor", cursor.Decode(query.Cursor, query.After) == nil, validate.CodeInvalid, "cursor is invalid")
+	}
+	if err := v.Err(auth.ErrInvalidQuery); err != nil {
+		return model.AuditPage{}, err
+	}
+
+	// one extra entry tells whether there is a next page
+	pageSize := query.PageSize
+	query.PageSize++
+	entries, err := u.auditLog.GetAudit(ctx, query)
+	if err != nil {
+		return model.AuditPage{}, err
+	}
+
+	page := model.AuditPage{Items: entries}
+	if len(entries) > pageSize {
+		page.Items = entries[:pageSize]
+		last := page.Items[pageSize-1]
+		page.NextCursor, err = cursor.Encode(model.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			return model.AuditPage{}, err
+		}
+	}
+	if page.Items == nil {
+		page.Items = []model.AuditEntry{}
+	}
+
+	return page, nil
+}
+
+// record appends the attempt of the action to the audit log, err is its outcome.
+// The actor and the client are taken from ctx unless the entry has them. An entry which
+// can't be stored is logged, the action it describes has been made anyway.
+func (u useCase) record(ctx context.Context, entry model.AuditEntry, err error) {
+	if user, ok := auth.FromContext(ctx); ok && entry.ActorID == "" {
+		entry.ActorID = user.ID
+	}
+	client := auth.ClientFromContext(ctx)
+	entry.IP = client.IP
+	entry.UserAgent = client.UserAgent
+	entry.Outcome = model.AuditSuccess
+	if err != nil {
+		entry.Outcome = model.AuditFailure
+		entry.Reason = string(errs.KindOf(err))
+	}
+
+	if err = u.auditLog.AppendAudit(ctx, entry); err != nil {
+		u.logger.Error("unable to append to the audit log", zap.String("action", string(entry.Action)),
+			zap.String("target", entry.TargetID), zap.Error(err))
+	}
+}
@@ -0,0 +1,72 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/silverspase/todo/internal/jsonpatch"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// userDocument is the JSON the user patches are applied to. The password is never shown,
+// a patch may add it to change the password.
+type userDocument struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Gender   string `json:"gender"`
+	Password string `json:"password,omitempty"`
+}
+
+// readOnlyFields are set on signup only, as for UpdateUser.
+var readOnlyFields = []string{"email", "gender"}
+
+func (u useCase) PatchUser(ctx context.Context, id string, patch jsonpatch.Patch, version int64) (_ string, err error) {
+	var user userDocument
+	defer func() {
+		u.record(ctx, model.AuditEntry{Action: updateAction(user.Password), TargetID: id}, err)
+	}()
+
+	if err = authorizeUser(ctx, id); err != nil {
+		return "", err
+	}
+
+	current, err := u.repo.GetUser(ctx, id)
+	if err != nil {
+		return "", err
+	}
+	if version != 0 && version != current.Version {
+		return "", auth.ErrVersionMismatch
+	}
+
+	doc, err := json.Marshal(userDocument{Name: current.Name, Email: current.Email, Gender: current.Gender})
+	if err != nil {
+		return "", err
+	}
+	patched, err := patch.Apply(doc)
+	if err != nil {
+		return "", err
+	}
+
+	changed, err := jsonpatch.Changed(doc, patched, readOnlyFields...)
+	if err != nil {
+		return "", err
+	}
+	var v validate.Validator
+	for _, field := range changed {
+		v.Add(field, validate.CodeReadOnly, field+" is read-only")
+	}
+	if err = v.Err(auth.ErrInvalidUser); err != nil {
+		return "", err
+	}
+
+	if err = validate.Unmarshal(patched, &user, auth.ErrInvalidUser); err != nil {
+		return "", err
+	}
+
+	entry := current
+	entry.Name = user.Name
+	entry.Password = user.Password
+	return u.updateUser(ctx, entry)
+}
@@ -2,41 +2,399 @@ package usecase
 
 import (
 	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 
+	"github.com/silverspase/todo/internal/cursor"
 	"github.com/silverspase/todo/internal/modules/auth"
 	"github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/validate"
 )
 
+const (
+	tokenSize = 32
+
+	defaultPageSize = 20
+	maxPageSize     = 100
+
+	maxNameLength     = 100
+	maxEmailLength    = 100 // the size of the email column
+	minPasswordLength = 8
+	maxPasswordLength = 72 // bcrypt ignores the bytes past 72
+
+	// dummyHash is compared to the password of an unknown email, so Login takes as long
+	// as for a known one and doesn't tell which emails are registered.
+	dummyHash = "$2a$10$087PNJo/ho1NwFeL5lWWN.WcWC5AQvMWq5arb0/pMiRyLHghAOrdW"
+)
+
+// genders are the accepted values of model.User.Gender, empty when not told.
+var genders = []string{"", "female", "male", "other"}
+
 type useCase struct {
-	repo   auth.Repository
-	logger *zap.Logger
+	repo       auth.Repository
+	auditLog   auth.AuditRepository
+	sessionTTL time.Duration
+	logger     *zap.Logger
 }
 
-func NewUseCase(logger *zap.Logger, repo auth.Repository) auth.UseCase {
+// NewUseCase returns the use case which records the changes of the users and the logins
+// in the audit log.
+func NewUseCase(logger *zap.Logger, repo auth.Repository, auditLog auth.AuditRepository,
+	sessionTTL time.Duration) auth.UseCase {
 	return &useCase{
-		repo:   repo,
-		logger: logger,
+		repo:       repo,
+		auditLog:   auditLog,
+		sessionTTL: sessionTTL,
+		logger:     logger,
 	}
 }
 
 func (u useCase) CreateUser(ctx context.Context, entry model.User) (string, error) {
-	return u.repo.CreateUser(ctx, entry)
+	// signing up makes a member, the other roles are granted by the admins
+	entry.Role = model.RoleMember
+	return u.createUser(ctx, entry)
 }
 
-func (u useCase) GetAllUsers(ctx context.Context, page int) ([]model.User, error) {
-	return u.repo.GetAllUsers(ctx, page)
+func (u useCase) Bootstrap(ctx context.Context, admin model.User) error {
+	hasAdmin, err := u.repo.HasRole(ctx, model.RoleAdmin)
+	if err != nil || hasAdmin {
+		return err
+	}
+
+	// the users signed up before the roles were introduced are members
+	user, err := u.repo.GetUserByEmail(ctx, admin.Email)
+	switch {
+	case err == nil:
+		_, err = u.repo.UpdateRole(ctx, user.ID, model.RoleAdmin)
+		u.record(ctx, model.AuditEntry{Action: model.AuditRoleChanged, TargetID: user.ID, Role: model.RoleAdmin}, err)
+		if err != nil {
+			return fmt.Errorf("unable to promote the first admin: %w", err)
+		}
+		u.logger.Info("promoted the first admin", zap.String("id", user.ID), zap.String("email", admin.Email))
+		return nil
+	case !errors.Is(err, auth.ErrNotFound):
+		return err
+	}
+
+	admin.Role = model.RoleAdmin
+	id, err := u.createUser(ctx, admin)
+	if err != nil {
+		return fmt.Errorf("unable to create the first admin: %w", err)
+	}
+	u.logger.Info("created the first admin", zap.String("id", id), zap.String("email", admin.Email))
+
+	return nil
+}
+
+// createUser signs the user up with the role of the entry.
+func (u useCase) createUser(ctx context.Context, entry model.User) (id string, err error) {
+	defer func() {
+		u.record(ctx, model.AuditEntry{Action: model.AuditUserCreated, TargetID: id}, err)
+	}()
+
+	// the emails are matched case-insensitively, see auth.Repository.GetUserByEmail
+	entry.Email = strings.ToLower(entry.Email)
+
+	var v validate.Validator
+	v.String("name", entry.Name, validate.Required, validate.MaxLength(maxNameLength))
+	v.String("email", entry.Email, validate.Required, validate.MaxLength(maxEmailLength), validate.Email)
+	v.String("gender", entry.Gender, validate.OneOf(genders...))
+	v.String("password", entry.Password, validate.Required)
+	validatePassword(&v, entry.Password)
+	if !v.Failed("email") {
+		_, err := u.repo.GetUserByEmail(ctx, entry.Email)
+		switch {
+		case err == nil:
+			v.Add("email", validate.CodeTaken, "email is already registered")
+		case !errors.Is(err, auth.ErrNotFound):
+			return "", err
+		}
+	}
+	if err := v.Err(auth.ErrInvalidUser); err != nil {
+		return "", err
+	}
+
+	hash, err := hashPassword(entry.Password)
+	if err != nil {
+		return "", err
+	}
+	entry.Password = hash
+
+	// another signup may have taken the email since it was checked
+	id, err = u.repo.CreateUser(ctx, entry)
+	if errors.Is(err, auth.ErrEmailTaken) {
+		return "", validate.Fail(auth.ErrInvalidUser, "email", validate.CodeTaken, "email is already registered")
+	}
+
+	return id, err
+}
+
+func (u useCase) GetAllUsers(ctx context.Context, token string, pageSize int) (model.Page, error) {
+	if _, err := auth.Authorize(ctx, auth.PermissionManageUsers); err != nil {
+		return model.Page{}, err
+	}
+
+	switch {
+	case pageSize == 0:
+		pageSize = defaultPageSize
+	case pageSize < 0 || pageSize > maxPageSize:
+		return model.Page{}, fmt.Errorf("%w: page_size must be between 1 and %d", auth.ErrInvalidPage, maxPageSize)
+	}
+
+	var after *model.Cursor
+	if token != "" {
+		after = &model.Cursor{}
+		if err := cursor.Decode(token, after); err != nil {
+			return model.Page{}, fmt.Errorf("%w: %v", auth.ErrInvalidPage, err)
+		}
+	}
+
+	// one extra user tells whether there is a next page
+	users, err := u.repo.GetAllUsers(ctx, after, pageSize+1)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	page := model.Page{Items: users}
+	if len(users) > pageSize {
+		page.Items = users[:pageSize]
+		last := page.Items[pageSize-1]
+		page.NextCursor, err = cursor.Encode(model.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		if err != nil {
+			return model.Page{}, err
+		}
+	}
+	if page.Items == nil {
+		page.Items = []model.User{}
+	}
+
+	return page, nil
 }
 
 func (u useCase) GetUser(ctx context.Context, id string) (model.User, error) {
+	if _, ok := auth.FromContext(ctx); !ok {
+		return model.User{}, auth.ErrUnauthorized
+	}
+
 	return u.repo.GetUser(ctx, id)
 }
 
-func (u useCase) UpdateUser(ctx context.Context, entry model.User) (string, error) {
-	return u.repo.UpdateUser(ctx, entry)
+func (u useCase) GetUsersByIDs(ctx context.Context, ids []string) ([]model.User, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return u.repo.GetUsersByIDs(ctx, ids)
+}
+
+func (u useCase) UpdateUser(ctx context.Context, entry model.User) (id string, err error) {
+	defer func() {
+		u.record(ctx, model.AuditEntry{Action: updateAction(entry.Password), TargetID: entry.ID}, err)
+	}()
+
+	if err = authorizeUser(ctx, entry.ID); err != nil {
+		return "", err
+	}
+
+	return u.updateUser(ctx, entry)
+}
+
+// updateUser is UpdateUser without the audit log entry.
+func (u useCase) updateUser(ctx context.Context, entry model.User) (string, error) {
+	// the email and the gender are set on signup only, see auth.Repository.UpdateUser
+	var v validate.Validator
+	v.String("name", entry.Name, validate.Required, validate.MaxLength(maxNameLength))
+	validatePassword(&v, entry.Password)
+	if err := v.Err(auth.ErrInvalidUser); err != nil {
+		return "", err
+	}
+
+	changed := entry.Password != ""
+	if !changed {
+		// keep the stored hash, the caller doesn't change the password
+		current, err := u.repo.GetUser(ctx, entry.ID)
+		if err != nil {
+			return "", err
+		}
+		entry.Password = current.Password
+	} else {
+		hash, err := hashPassword(entry.Password)
+		if err != nil {
+			return "", err
+		}
+		entry.Password = hash
+	}
+
+	id, err := u.repo.UpdateUser(ctx, entry)
+	if err != nil || !changed {
+		return id, err
+	}
+
+	// the tokens issued for the old password are revoked, so are the ones of the caller
+	if err = u.repo.DeleteUserSessions(ctx, id); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (u useCase) DeleteUser(ctx context.Context, id string, version int64) (_ string, err error) {
+	defer func() {
+		u.record(ctx, model.AuditEntry{Action: model.AuditUserDeleted, TargetID: id}, err)
+	}()
+
+	if err = authorizeUser(ctx, id); err != nil {
+		return "", err
+	}
+
+	return u.repo.DeleteUser(ctx, id, version)
+}
+
+func (u useCase) SetRole(ctx context.Context, id string, role model.Role) (_ string, err error) {
+	defer func() {
+		u.record(ctx, model.AuditEntry{Action: model.AuditRoleChanged, TargetID: id, Role: role}, err)
+	}()
+
+	if _, err = auth.Authorize(ctx, auth.PermissionManageUsers); err != nil {
+		return "", err
+	}
+	var v validate.Validator
+	v.String("role", string(role), validate.Required,
+		validate.OneOf(string(model.RoleAdmin), string(model.RoleMember), string(model.RoleReadOnly)))
+	if err = v.Err(auth.ErrInvalidUser); err != nil {
+		return "", err
+	}
+
+	return u.repo.UpdateRole(ctx, id, role)
+}
+
+func (u useCase) Login(ctx context.Context, email, password string) (_ model.Token, err error) {
+	var user model.User
+	defer func() {
+		entry := model.AuditEntry{Action: model.AuditLogin, TargetID: user.ID, Email: email}
+		if err == nil {
+			// the user is authenticated from now on
+			entry.ActorID = user.ID
+		}
+		u.record(ctx, entry, err)
+	}()
+
+	user, err = u.repo.GetUserByEmail(ctx, email)
+	if errors.Is(err, auth.ErrNotFound) {
+		_ = bcrypt.CompareHashAndPassword([]byte(dummyHash), []byte(password))
+		return model.Token{}, auth.ErrInvalidCredentials
+	}
+	if err != nil {
+		return model.Token{}, err
+	}
+
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(password))
+	if err != nil {
+		return model.Token{}, auth.ErrInvalidCredentials
+	}
+
+	raw := make([]byte, tokenSize)
+	if _, err = rand.Read(raw); err != nil {
+		return model.Token{}, err
+	}
+	token := base64.RawURLEncoding.EncodeToString(raw)
+
+	session := model.Session{
+		TokenHash: hashToken(token),
+		UserID:    user.ID,
+		ExpiresAt: time.Now().Add(u.sessionTTL),
+	}
+	if err = u.repo.CreateSession(ctx, session); err != nil {
+		return model.Token{}, err
+	}
+
+	return model.Token{
+		AccessToken: token,
+		TokenType:   "Bearer",
+		ExpiresAt:   session.ExpiresAt,
+	}, nil
+}
+
+func (u useCase) Logout(ctx context.Context, token string) error {
+	return u.repo.DeleteSession(ctx, hashToken(token))
+}
+
+func (u useCase) Authenticate(ctx context.Context, token string) (model.User, error) {
+	session, err := u.repo.GetSession(ctx, hashToken(token))
+	if errors.Is(err, auth.ErrNotFound) {
+		return model.User{}, auth.ErrUnauthorized
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	if time.Now().After(session.ExpiresAt) {
+		if err = u.repo.DeleteSession(ctx, session.TokenHash); err != nil {
+			u.logger.Warn("unable to delete expired session", zap.Error(err))
+		}
+		return model.User{}, auth.ErrUnauthorized
+	}
+
+	user, err := u.repo.GetUser(ctx, session.UserID)
+	if errors.Is(err, auth.ErrNotFound) {
+		return model.User{}, auth.ErrUnauthorized
+	}
+	if err != nil {
+		return model.User{}, err
+	}
+
+	return user, nil
+}
+
+// authorizeUser lets the users manage themselves, and the ones with PermissionManageUsers
+// any other user.
+func authorizeUser(ctx context.Context, id string) error {
+	user, ok := auth.FromContext(ctx)
+	if !ok {
+		return auth.ErrUnauthorized
+	}
+	if user.ID != id && !auth.Can(user, auth.PermissionManageUsers) {
+		return auth.ErrForbidden
+	}
+
+	return nil
+}
+
+// updateAction tells a password change from other updates of the user.
+func updateAction(password string) model.AuditAction {
+	if password != "" {
+		return model.AuditPasswordChanged
+	}
+
+	return model.AuditUserUpdated
+}
+
+// validatePassword checks the length of a non-empty password.
+func validatePassword(v *validate.Validator, password string) {
+	v.String("password", password, validate.MinLength(minPasswordLength))
+	v.Check("password", len(password) <= maxPasswordLength, validate.CodeTooLong,
+		fmt.Sprintf("password is longer than %d bytes", maxPasswordLength))
+}
+
+func hashPassword(password string) (string, error) {
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
 }
 
-func (u useCase) DeleteUser(ctx context.Context, id string) (string, error) {
-	return u.repo.DeleteUser(ctx, id)
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
 }
@@ -0,0 +1,256 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/jsonpatch"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/modules/auth/repository/memory"
+	"github.com/silverspase/todo/internal/modules/auth/usecase"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+var ctx = context.Background()
+
+func newUseCase() (auth.UseCase, auth.Repository) {
+	repo := memory.NewMemoryStorage(zap.NewNop())
+	return usecase.NewUseCase(zap.NewNop(), repo, memory.NewAuditStorage(zap.NewNop()), time.Hour), repo
+}
+
+func signUp(t *testing.T, u auth.UseCase, email string) string {
+	t.Helper()
+
+	id, err := u.CreateUser(ctx, model.User{Name: email, Email: email, Password: "password1"})
+	if err != nil {
+		t.Fatalf("CreateUser(%s): %v", email, err)
+	}
+
+	return id
+}
+
+func role(t *testing.T, repo auth.Repository, email string) model.Role {
+	t.Helper()
+
+	user, err := repo.GetUserByEmail(ctx, email)
+	if err != nil {
+		t.Fatalf("GetUserByEmail(%s): %v", email, err)
+	}
+
+	return user.Role
+}
+
+func TestBootstrap(t *testing.T) {
+	admin := model.User{Name: "admin", Email: "admin@example.com", Password: "password1"}
+
+	tests := []struct {
+		name   string
+		signUp []string
+		// promote is granted the admin role before bootstrapping
+		promote string
+		want    map[string]model.Role
+	}{
+		{
+			name: "no users",
+			want: map[string]model.Role{"admin@example.com": model.RoleAdmin},
+		},
+		{
+			name:   "upgraded member",
+			signUp: []string{"ann@example.com", "admin@example.com"},
+			want: map[string]model.Role{
+				"ann@example.com":   model.RoleMember,
+				"admin@example.com": model.RoleAdmin,
+			},
+		},
+		{
+			name:   "members only",
+			signUp: []string{"ann@example.com"},
+			want: map[string]model.Role{
+				"ann@example.com":   model.RoleMember,
+				"admin@example.com": model.RoleAdmin,
+			},
+		},
+		{
+			name:    "admin exists",
+			signUp:  []string{"ann@example.com", "admin@example.com"},
+			promote: "ann@example.com",
+			want: map[string]model.Role{
+				"ann@example.com":   model.RoleAdmin,
+				"admin@example.com": model.RoleMember,
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			u, repo := newUseCase()
+			for _, email := range tt.signUp {
+				id := signUp(t, u, email)
+				if email == tt.promote {
+					if _, err := repo.UpdateRole(ctx, id, model.RoleAdmin); err != nil {
+						t.Fatalf("UpdateRole: %v", err)
+					}
+				}
+			}
+
+			if err := u.Bootstrap(ctx, admin); err != nil {
+				t.Fatalf("Bootstrap: %v", err)
+			}
+			// bootstrapping on every start changes nothing more
+			if err := u.Bootstrap(ctx, admin); err != nil {
+				t.Fatalf("Bootstrap again: %v", err)
+			}
+
+			for email, want := range tt.want {
+				if got := role(t, repo, email); got != want {
+					t.Errorf("%s has role %q, want %q", email, got, want)
+				}
+			}
+		})
+	}
+}
+
+// taken tells whether the error is the one of a registered email.
+func taken(err error) bool {
+	var invalid *validate.Errors
+	if !errors.As(err, &invalid) || !errors.Is(err, auth.ErrInvalidUser) {
+		return false
+	}
+	for _, field := range invalid.Fields {
+		if field.Field == "email" && field.Code == validate.CodeTaken {
+			return true
+		}
+	}
+
+	return false
+}
+
+func TestCreateUserEmailTaken(t *testing.T) {
+	u, repo := newUseCase()
+
+	signUp(t, u, "Ann@Example.com")
+	if _, err := repo.GetUserByEmail(ctx, "ann@example.com"); err != nil {
+		t.Fatalf("the email isn't stored in lower case: %v", err)
+	}
+
+	_, err := u.CreateUser(ctx, model.User{Name: "ann", Email: "ann@example.com", Password: "password1"})
+	if !taken(err) {
+		t.Errorf("signing up in another case: got %v, want the email taken", err)
+	}
+}
+
+func TestCreateUserConcurrently(t *testing.T) {
+	u, _ := newUseCase()
+
+	const signups = 8
+	errs := make([]error, signups)
+	var wg sync.WaitGroup
+	for i := range errs {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = u.CreateUser(ctx, model.User{Name: "ann", Email: "ann@example.com", Password: "password1"})
+		}(i)
+	}
+	wg.Wait()
+
+	created := 0
+	for _, err := range errs {
+		switch {
+		case err == nil:
+			created++
+		case !taken(err):
+			t.Errorf("CreateUser: got %v, want the email taken", err)
+		}
+	}
+	if created != 1 {
+		t.Errorf("%d users were created, want 1", created)
+	}
+}
+
+func TestPasswordChangeRevokesSessions(t *testing.T) {
+	u, repo := newUseCase()
+	id := signUp(t, u, "ann@example.com")
+	ann, err := repo.GetUser(ctx, id)
+	if err != nil {
+		t.Fatalf("GetUser: %v", err)
+	}
+	annCtx := auth.NewContext(ctx, ann)
+
+	logIn := func(password string) string {
+		t.Helper()
+		token, err := u.Login(ctx, "ann@example.com", password)
+		if err != nil {
+			t.Fatalf("Login: %v", err)
+		}
+		return token.AccessToken
+	}
+	revoked := func(tokens ...string) bool {
+		t.Helper()
+		for _, token := range tokens {
+			if _, err := u.Authenticate(ctx, token); !errors.Is(err, auth.ErrUnauthorized) {
+				return false
+			}
+		}
+		return true
+	}
+
+	tokens := []string{logIn("password1"), logIn("password1")}
+	if _, err = u.UpdateUser(annCtx, model.User{ID: id, Name: "Ann"}); err != nil {
+		t.Fatalf("UpdateUser of the name: %v", err)
+	}
+	for _, token := range tokens {
+		if _, err = u.Authenticate(ctx, token); err != nil {
+			t.Errorf("Authenticate after changing the name: %v", err)
+		}
+	}
+
+	if _, err = u.UpdateUser(annCtx, model.User{ID: id, Name: "Ann", Password: "password2"}); err != nil {
+		t.Fatalf("UpdateUser of the password: %v", err)
+	}
+	if !revoked(tokens...) {
+		t.Error("the sessions outlived the password changed by UpdateUser")
+	}
+
+	token := logIn("password2")
+	patch, err := jsonpatch.New(jsonpatch.JSONPatchType, []byte(`[{"op":"add","path":"/password","value":"password3"}]`))
+	if err != nil {
+		t.Fatalf("jsonpatch.New: %v", err)
+	}
+	if _, err = u.PatchUser(annCtx, id, patch, 0); err != nil {
+		t.Fatalf("PatchUser of the password: %v", err)
+	}
+	if !revoked(token) {
+		t.Error("the sessions outlived the password changed by PatchUser")
+	}
+	logIn("password3")
+}
+
+func TestLoginUnknownEmail(t *testing.T) {
+	u, _ := newUseCase()
+	signUp(t, u, "ann@example.com")
+
+	start := time.Now()
+	if _, err := u.Login(ctx, "bob@example.com", "password1"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Login of an unknown email: got %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	unknown := time.Since(start)
+
+	start = time.Now()
+	if _, err := u.Login(ctx, "ann@example.com", "password2"); !errors.Is(err, auth.ErrInvalidCredentials) {
+		t.Errorf("Login with a wrong password: got %v, want %v", err, auth.ErrInvalidCredentials)
+	}
+	wrong := time.Since(start)
+
+	// both compare a bcrypt hash, skipping it is orders of magnitude faster
+	if unknown < wrong/4 {
+		t.Errorf("Login of an unknown email took %v, of a wrong password %v", unknown, wrong)
+	}
+}
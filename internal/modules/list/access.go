@@ -0,0 +1,67 @@
+package list
+
+import (
+	"context"
+	"errors"
+
+	"github.com/silverspase/todo/internal/modules/list/model"
+	"github.com/silverspase/todo/internal/modules/todo"
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// GetList returns the list of the user, or the one shared with them with the access.
+// A list shared with a lesser access is ErrForbidden.
+func GetList(ctx context.Context, lists Repository, grants todo.Repository, userID, id string,
+	access todoModel.Access) (model.List, error) {
+	entry, err := lists.GetList(ctx, userID, id)
+	if !errors.Is(err, ErrNotFound) {
+		return entry, err
+	}
+
+	userGrants, err := grants.GetUserGrants(ctx, userID)
+	if err != nil {
+		return model.List{}, err
+	}
+	var granted todoModel.Access
+	for _, grant := range userGrants {
+		if grant.ListID == id {
+			granted = grant.Access
+		}
+	}
+	if granted == "" {
+		return model.List{}, ErrNotFound
+	}
+	if !granted.Allows(access) {
+		return model.List{}, ErrForbidden
+	}
+
+	shared, err := lists.GetListsByIDs(ctx, []string{id})
+	if err != nil {
+		return model.List{}, err
+	}
+	if len(shared) == 0 {
+		return model.List{}, ErrNotFound
+	}
+
+	return shared[0], nil
+}
+
+// GetSharedLists returns the lists shared with the user, ordered by (created_at, id).
+func GetSharedLists(ctx context.Context, lists Repository, grants todo.Repository, userID string) ([]model.List, error) {
+	userGrants, err := grants.GetUserGrants(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var ids []string
+	for _, grant := range userGrants {
+		if grant.ListID != "" {
+			ids = append(ids, grant.ListID)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	return lists.GetListsByIDs(ctx, ids)
+}
@@ -0,0 +1,14 @@
+package list
+
+import "github.com/silverspase/todo/internal/errs"
+
+var (
+	// ErrNotFound is returned when a list doesn't exist or belongs to another user.
+	ErrNotFound = errs.New(errs.NotFound, "list not found")
+	// ErrInvalidList is wrapped by the use case validation errors.
+	ErrInvalidList = errs.New(errs.Validation, "invalid list")
+	// ErrForbidden is returned when the user lacks the access to a list shared with them.
+	ErrForbidden = errs.New(errs.Forbidden, "list is shared with you without the access")
+	// ErrNotEmpty is returned when a list with items is deleted without cascade.
+	ErrNotEmpty = errs.New(errs.Conflict, "list is not empty")
+)
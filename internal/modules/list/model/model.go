@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// List is a named group of items, e.g. "Sprint 42" or "Groceries".
+type List struct {
+	ID        string         `json:"id" gorm:"primaryKey"`
+	OwnerID   string         `json:"owner_id" gorm:"index"`
+	Name      string         `json:"name"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" sql:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (l *List) BeforeCreate(tx *gorm.DB) error {
+	l.ID = uuid.New().String()
+	return nil
+}
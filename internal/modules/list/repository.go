@@ -0,0 +1,24 @@
+package list
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/list/model"
+)
+
+// Repository stores lists. Every method but GetListsByIDs is scoped to the owner: lists
+// of other users are reported as ErrNotFound.
+type Repository interface {
+	CreateList(ctx context.Context, list model.List) (string, error)
+	// GetAllLists returns the lists ordered by (created_at, id).
+	GetAllLists(ctx context.Context, ownerID string) ([]model.List, error)
+	GetList(ctx context.Context, ownerID, id string) (model.List, error)
+	// GetListsByIDs returns the lists of any owner found, ordered by (created_at, id).
+	// Unknown IDs are skipped, the caller makes sure the user may see them.
+	GetListsByIDs(ctx context.Context, ids []string) ([]model.List, error)
+	UpdateList(ctx context.Context, list model.List) (string, error)
+	DeleteList(ctx context.Context, ownerID, id string) (string, error)
+	// Transaction runs fn in a transaction, the repositories of the same database given
+	// the context of fn take part in it. The memory storage applies the changes right away.
+	Transaction(ctx context.Context, fn func(ctx context.Context) error) error
+}
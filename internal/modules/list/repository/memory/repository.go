@@ -0,0 +1,138 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/list"
+	"github.com/silverspase/todo/internal/modules/list/model"
+	"github.com/silverspase/todo/internal/txn"
+)
+
+type memoryStorage struct {
+	mu     sync.RWMutex
+	lists  map[string]model.List
+	logger *zap.Logger
+}
+
+func NewMemoryStorage(logger *zap.Logger) list.Repository {
+	return &memoryStorage{
+		lists:  make(map[string]model.List),
+		logger: logger,
+	}
+}
+
+func (m *memoryStorage) CreateList(ctx context.Context, entry model.List) (string, error) {
+	m.logger.Debug("CreateList")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	entry.ID = uuid.New().String()
+	entry.CreatedAt = now
+	entry.UpdatedAt = now
+	m.lists[entry.ID] = entry
+
+	return entry.ID, nil
+}
+
+func (m *memoryStorage) GetAllLists(ctx context.Context, ownerID string) (res []model.List, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, entry := range m.lists {
+		if entry.OwnerID == ownerID {
+			res = append(res, entry)
+		}
+	}
+
+	sortLists(res)
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetList(ctx context.Context, ownerID, id string) (model.List, error) {
+	m.logger.Debug("GetList")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entry, ok := m.lists[id]
+	if !ok || entry.OwnerID != ownerID {
+		return model.List{}, list.ErrNotFound
+	}
+
+	return entry, nil
+}
+
+func (m *memoryStorage) GetListsByIDs(ctx context.Context, ids []string) (res []model.List, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, id := range ids {
+		if entry, ok := m.lists[id]; ok {
+			res = append(res, entry)
+		}
+	}
+	sortLists(res)
+
+	return res, nil
+}
+
+func (m *memoryStorage) UpdateList(ctx context.Context, entry model.List) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.lists[entry.ID]
+	if !ok || current.OwnerID != entry.OwnerID {
+		return "", list.ErrNotFound
+	}
+
+	current.Name = entry.Name
+	current.UpdatedAt = time.Now()
+	m.lists[entry.ID] = current
+
+	return entry.ID, nil
+}
+
+func (m *memoryStorage) DeleteList(ctx context.Context, ownerID, id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entry, ok := m.lists[id]
+	if !ok || entry.OwnerID != ownerID {
+		return "", list.ErrNotFound
+	}
+
+	delete(m.lists, id)
+
+	return id, nil
+}
+
+func (m *memoryStorage) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if txn.Conn(ctx) != nil {
+		return fn(ctx)
+	}
+
+	ctx = txn.Begin(ctx, m)
+	if err := fn(ctx); err != nil {
+		return err
+	}
+	txn.Committed(ctx)
+
+	return nil
+}
+
+// sortLists orders the lists by (created_at, id).
+func sortLists(lists []model.List) {
+	sort.Slice(lists, func(i, j int) bool {
+		if !lists[i].CreatedAt.Equal(lists[j].CreatedAt) {
+			return lists[i].CreatedAt.Before(lists[j].CreatedAt)
+		}
+		return lists[i].ID < lists[j].ID
+	})
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/app/repository/sql"
+	"github.com/silverspase/todo/internal/modules/list"
+	"github.com/silverspase/todo/internal/modules/list/model"
+)
+
+type postgres struct {
+	conn   *gorm.DB
+	logger *zap.Logger
+}
+
+func NewRepository(conn *gorm.DB, logger *zap.Logger) list.Repository {
+	return postgres{
+		conn:   conn,
+		logger: logger,
+	}
+}
+
+func (p postgres) CreateList(ctx context.Context, entry model.List) (string, error) {
+	p.logger.Debug("CreateList")
+
+	res := p.conn.WithContext(ctx).Create(&entry)
+	if res.Error != nil {
+		return "", res.Error
+	}
+
+	return entry.ID, nil
+}
+
+func (p postgres) GetAllLists(ctx context.Context, ownerID string) (entries []model.List, err error) {
+	p.logger.Debug("GetAllLists")
+
+	res := p.conn.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at, id").Find(&entries)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+
+	return entries, nil
+}
+
+func (p postgres) GetList(ctx context.Context, ownerID, id string) (model.List, error) {
+	p.logger.Debug("GetList", zap.String("id", id))
+
+	var entry model.List
+	err := p.conn.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).First(&entry).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return entry, list.ErrNotFound
+	}
+	if err != nil {
+		return entry, err
+	}
+
+	return entry, nil
+}
+
+func (p postgres) GetListsByIDs(ctx context.Context, ids []string) (entries []model.List, err error) {
+	p.logger.Debug("GetListsByIDs", zap.Int("count", len(ids)))
+
+	err = p.conn.WithContext(ctx).Where("id IN ?", ids).Order("created_at, id").Find(&entries).Error
+	return entries, err
+}
+
+func (p postgres) UpdateList(ctx context.Context, newEntry model.List) (string, error) {
+	p.logger.Debug("UpdateList", zap.String("id", newEntry.ID))
+
+	entry, err := p.GetList(ctx, newEntry.OwnerID, newEntry.ID)
+	if err != nil {
+		return "", err
+	}
+
+	entry.Name = newEntry.Name
+	err = p.conn.WithContext(ctx).Save(&entry).Error
+	if err != nil {
+		return "", err
+	}
+
+	return entry.ID, nil
+}
+
+func (p postgres) DeleteList(ctx context.Context, ownerID, id string) (string, error) {
+	p.logger.Info("DeleteList", zap.String("id", id))
+
+	res := sql.Conn(ctx, p.conn).Where("owner_id = ?", ownerID).Delete(&model.List{ID: id})
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", list.ErrNotFound
+	}
+
+	return id, nil
+}
+
+func (p postgres) Transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	return sql.Transaction(ctx, p.conn, fn)
+}
@@ -0,0 +1,17 @@
+package list
+
+import (
+	"net/http"
+)
+
+type Transport interface {
+	CreateList(w http.ResponseWriter, r *http.Request)
+	GetAllLists(w http.ResponseWriter, r *http.Request)
+	GetList(w http.ResponseWriter, r *http.Request)
+	UpdateList(w http.ResponseWriter, r *http.Request)
+	DeleteList(w http.ResponseWriter, r *http.Request)
+	GetListGrants(w http.ResponseWriter, r *http.Request)
+	// ShareList takes the user from the user_id path param and the access in the body.
+	ShareList(w http.ResponseWriter, r *http.Request)
+	UnshareList(w http.ResponseWriter, r *http.Request)
+}
@@ -0,0 +1,232 @@
+package gorilla_mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/httpjson"
+	"github.com/silverspase/todo/internal/modules/list"
+	"github.com/silverspase/todo/internal/modules/list/model"
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/problem"
+)
+
+// errors of the requests which don't reach the use case
+var (
+	errInvalidPayload = errs.New(errs.Validation, "invalid request payload")
+	errMissingID      = errs.New(errs.Validation, "missed id path param")
+	errMissingUserID  = errs.New(errs.Validation, "missed user_id path param")
+)
+
+type transport struct {
+	useCase list.UseCase
+	logger  *zap.Logger
+}
+
+func NewTransport(logger *zap.Logger, useCase list.UseCase) list.Transport {
+	return &transport{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (t *transport) CreateList(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("CreateList")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var entry model.List
+	if err := httpjson.Decode(r, &entry, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	id, err := t.useCase.CreateList(ctx, entry)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]string{"status": "created", "id": id})
+}
+
+func (t *transport) GetAllLists(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetAllLists")
+
+	lists, err := t.useCase.GetAllLists(r.Context())
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, lists)
+}
+
+func (t *transport) GetList(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetList")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	entry, err := t.useCase.GetList(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to get list with id %v: %w", id, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, entry)
+}
+
+func (t *transport) UpdateList(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("UpdateList")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var entry model.List
+	if err := httpjson.Decode(r, &entry, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	entry.ID = id
+	id, err := t.useCase.UpdateList(ctx, entry)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+}
+
+func (t *transport) DeleteList(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("DeleteList")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var cascade bool
+	if s := r.FormValue("cascade"); s != "" {
+		var err error
+		cascade, err = strconv.ParseBool(s)
+		if err != nil {
+			problem.Write(t.logger, w, r, errs.New(errs.Validation, "cascade param is not a boolean"))
+			return
+		}
+	}
+
+	_, err := t.useCase.DeleteList(ctx, id, cascade)
+	if err != nil {
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to delete list with id %v: %w", id, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "deleted", "id": id})
+}
+
+func (t *transport) GetListGrants(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetListGrants")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	grants, err := t.useCase.GetListGrants(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, grants)
+}
+
+func (t *transport) ShareList(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("ShareList")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id, userID := params["id"], params["user_id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+	if userID == "" {
+		problem.Write(t.logger, w, r, errMissingUserID)
+		return
+	}
+
+	var req struct {
+		Access todoModel.Access `json:"access"`
+	}
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	id, err := t.useCase.ShareList(ctx, id, userID, req.Access)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "shared", "id": id})
+}
+
+func (t *transport) UnshareList(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("UnshareList")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id, userID := params["id"], params["user_id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+	if userID == "" {
+		problem.Write(t.logger, w, r, errMissingUserID)
+		return
+	}
+
+	id, err := t.useCase.UnshareList(ctx, id, userID)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "unshared", "id": id})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
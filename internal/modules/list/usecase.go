@@ -0,0 +1,29 @@
+package list
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/list/model"
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// UseCase operates on the lists of the user authenticated in ctx (see auth.NewContext).
+// The lists shared with them are read too.
+type UseCase interface {
+	CreateList(ctx context.Context, list model.List) (string, error)
+	// GetAllLists returns the lists of the user, then the ones shared with them.
+	GetAllLists(ctx context.Context) ([]model.List, error)
+	GetList(ctx context.Context, id string) (model.List, error)
+	UpdateList(ctx context.Context, list model.List) (string, error)
+	// DeleteList refuses to delete a list with items unless cascade is set, in which case
+	// the items are deleted too, in the same transaction, and their deletion is reported.
+	DeleteList(ctx context.Context, id string, cascade bool) (string, error)
+
+	// GetListGrants returns who the items of the list are shared with by the list.
+	GetListGrants(ctx context.Context, id string) ([]todoModel.Grant, error)
+	// ShareList grants the user the access to every item of the list, or changes the access they have.
+	// The user is given by their ID or email, see todo.Grantee, and so is the one UnshareList revokes.
+	ShareList(ctx context.Context, id, userID string, access todoModel.Access) (string, error)
+	// UnshareList revokes the access of the user to the list, todo.ErrGrantNotFound when it isn't shared with them.
+	UnshareList(ctx context.Context, id, userID string) (string, error)
+}
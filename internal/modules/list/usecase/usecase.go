@@ -0,0 +1,207 @@
+package usecase
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/list"
+	"github.com/silverspase/todo/internal/modules/list/model"
+	"github.com/silverspase/todo/internal/modules/todo"
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+const maxNameLength = 100
+
+type listUseCase struct {
+	repo     list.Repository
+	items    todo.Repository
+	itemCase todo.UseCase
+	users    auth.Repository
+	logger   *zap.Logger
+}
+
+func NewListUseCase(logger *zap.Logger, repo list.Repository, items todo.Repository, itemCase todo.UseCase,
+	users auth.Repository) list.UseCase {
+	return &listUseCase{
+		repo:     repo,
+		items:    items,
+		itemCase: itemCase,
+		users:    users,
+		logger:   logger,
+	}
+}
+
+func (l listUseCase) CreateList(ctx context.Context, entry model.List) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateList(entry); err != nil {
+		return "", err
+	}
+
+	entry.OwnerID = user.ID
+	return l.repo.CreateList(ctx, entry)
+}
+
+func (l listUseCase) GetAllLists(ctx context.Context) ([]model.List, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	lists, err := l.repo.GetAllLists(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	shared, err := list.GetSharedLists(ctx, l.repo, l.items, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	lists = append(lists, shared...)
+	if lists == nil {
+		lists = []model.List{}
+	}
+
+	return lists, nil
+}
+
+func (l listUseCase) GetList(ctx context.Context, id string) (model.List, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return model.List{}, err
+	}
+
+	return list.GetList(ctx, l.repo, l.items, user.ID, id, todoModel.AccessView)
+}
+
+func (l listUseCase) UpdateList(ctx context.Context, entry model.List) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateList(entry); err != nil {
+		return "", err
+	}
+
+	entry.OwnerID = user.ID
+	return l.repo.UpdateList(ctx, entry)
+}
+
+func (l listUseCase) DeleteList(ctx context.Context, id string, cascade bool) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err := l.repo.GetList(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+
+	if !cascade {
+		items, err := l.items.GetAllItems(ctx, todoModel.Query{
+			OwnerID:  user.ID,
+			ListID:   id,
+			Sort:     todoModel.SortCreated,
+			PageSize: 1,
+		})
+		if err != nil {
+			return "", err
+		}
+		if len(items) > 0 {
+			return "", list.ErrNotEmpty
+		}
+	}
+
+	// the items go through their use case, which reports their deletion
+	err = l.repo.Transaction(ctx, func(ctx context.Context) error {
+		if cascade {
+			if err := l.itemCase.DeleteListItems(ctx, id); err != nil {
+				return err
+			}
+		}
+		_, err := l.repo.DeleteList(ctx, user.ID, id)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (l listUseCase) GetListGrants(ctx context.Context, id string) ([]todoModel.Grant, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = l.repo.GetList(ctx, user.ID, id); err != nil {
+		return nil, err
+	}
+
+	grants, err := l.items.GetGrants(ctx, "", id)
+	if err != nil {
+		return nil, err
+	}
+	if grants == nil {
+		grants = []todoModel.Grant{}
+	}
+
+	return grants, nil
+}
+
+func (l listUseCase) ShareList(ctx context.Context, id, userID string, access todoModel.Access) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = l.repo.GetList(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+	if userID, err = todo.Grantee(ctx, l.users, userID); err != nil {
+		return "", err
+	}
+
+	grant := todoModel.Grant{ListID: id, UserID: userID, Access: access}
+	if err = todo.ValidateGrant(ctx, l.users, user.ID, grant); err != nil {
+		return "", err
+	}
+	if err = l.items.SaveGrant(ctx, grant); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (l listUseCase) UnshareList(ctx context.Context, id, userID string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = l.repo.GetList(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+	if userID, err = todo.Grantee(ctx, l.users, userID); err != nil {
+		return "", err
+	}
+	if err = l.items.DeleteGrant(ctx, todoModel.Grant{ListID: id, UserID: userID}); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func validateList(entry model.List) error {
+	var v validate.Validator
+	v.String("name", entry.Name, validate.Required, validate.MaxLength(maxNameLength))
+
+	return v.Err(list.ErrInvalidList)
+}
@@ -0,0 +1,33 @@
+package todo
+
+import "github.com/silverspase/todo/internal/errs"
+
+var (
+	// ErrNotFound is returned when an item doesn't exist or belongs to another user.
+	ErrNotFound = errs.New(errs.NotFound, "item not found")
+	// ErrRevisionNotFound is returned for an unknown revision of an existing item.
+	ErrRevisionNotFound = errs.New(errs.NotFound, "revision not found")
+	// ErrGrantNotFound is returned when the item or the list isn't shared with the user.
+	ErrGrantNotFound = errs.New(errs.NotFound, "grant not found")
+	// ErrForbidden is returned when the user lacks the access to an item shared with them,
+	// or does what only its owner can.
+	ErrForbidden = errs.New(errs.Forbidden, "item is shared with you without the access")
+	// ErrTagNotFound is returned when a tag doesn't exist or belongs to another user.
+	ErrTagNotFound = errs.New(errs.NotFound, "tag not found")
+	// ErrOpenSubtasks is returned when an item is completed before its subtasks.
+	ErrOpenSubtasks = errs.New(errs.Conflict, "item has open subtasks")
+	// ErrInvalidItem is wrapped by the use case validation errors.
+	ErrInvalidItem = errs.New(errs.Validation, "invalid item")
+	// ErrInvalidGrant is wrapped by the validation errors of sharing an item or a list.
+	ErrInvalidGrant = errs.New(errs.Validation, "invalid grant")
+	// ErrInvalidQuery is wrapped by the GetAllItems query validation errors.
+	ErrInvalidQuery = errs.New(errs.Validation, "invalid query")
+	// ErrVersionMismatch is returned when the item was changed since the version the caller expects.
+	ErrVersionMismatch = errs.New(errs.PreconditionFailed, "item was changed, its version doesn't match")
+	// ErrParentDeleted is returned when a subtask is restored while its parent is deleted.
+	ErrParentDeleted = errs.New(errs.Conflict, "parent item is deleted, restore it first")
+	// ErrListDeleted is returned when an item of a deleted list is restored.
+	ErrListDeleted = errs.New(errs.Conflict, "list of the item is deleted")
+	// ErrEventsExpired is returned when the events following the requested one are no longer retained.
+	ErrEventsExpired = errs.New(errs.Gone, "events are no longer available")
+)
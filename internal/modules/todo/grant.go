@@ -0,0 +1,48 @@
+package todo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// ValidateGrant checks the grant of an item or a list the owner makes: the access is known
+// and it's made to another existing user. The fields are named after the request params.
+func ValidateGrant(ctx context.Context, users auth.Repository, ownerID string, grant model.Grant) error {
+	var v validate.Validator
+	v.String("access", string(grant.Access), validate.Required,
+		validate.OneOf(string(model.AccessView), string(model.AccessEdit)))
+	v.Check("user_id", grant.UserID != ownerID, validate.CodeInvalid, "items can't be shared with their owner")
+	if err := v.Err(ErrInvalidGrant); err != nil {
+		return err
+	}
+
+	_, err := users.GetUser(ctx, grant.UserID)
+	if errors.Is(err, auth.ErrNotFound) {
+		return validate.Fail(ErrInvalidGrant, "user_id", validate.CodeNotFound,
+			fmt.Sprintf("user %v not found", grant.UserID))
+	}
+
+	return err
+}
+
+// Grantee returns the ID of the user a grant is made to or revoked from, who is given by
+// their ID or their email, since the users can't list the others to learn their IDs.
+func Grantee(ctx context.Context, users auth.Repository, user string) (string, error) {
+	if !strings.Contains(user, "@") {
+		return user, nil
+	}
+
+	grantee, err := users.GetUserByEmail(ctx, user)
+	if errors.Is(err, auth.ErrNotFound) {
+		return "", validate.Fail(ErrInvalidGrant, "user_id", validate.CodeNotFound,
+			fmt.Sprintf("user %v not found", user))
+	}
+
+	return grantee.ID, err
+}
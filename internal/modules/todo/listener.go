@@ -0,0 +1,12 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// Listener is notified of the item changes made through the UseCase, after they are stored.
+type Listener interface {
+	ItemChanged(ctx context.Context, event model.Event) error
+}
@@ -0,0 +1,40 @@
+package model
+
+import "time"
+
+type EventType string
+
+const (
+	EventCreated   EventType = "created"
+	EventUpdated   EventType = "updated"
+	EventCompleted EventType = "completed"
+	EventDeleted   EventType = "deleted"
+	EventRestored  EventType = "restored"
+)
+
+// Event is a change of an item. IDs are opaque, they only identify the position
+// in the stream to resume from.
+type Event struct {
+	ID      string    `json:"id"`
+	Type    EventType `json:"type"`
+	OwnerID string    `json:"-"`
+	// SharedWith are the users the item is shared with, they see its changes too
+	SharedWith []string  `json:"-"`
+	ItemID     string    `json:"item_id"`
+	Item       *Item     `json:"item,omitempty"` // nil for deleted items
+	At         time.Time `json:"at"`
+}
+
+// VisibleTo tells whether the user owns the changed item or it is shared with them.
+func (e Event) VisibleTo(userID string) bool {
+	if e.OwnerID == userID {
+		return true
+	}
+	for _, id := range e.SharedWith {
+		if id == userID {
+			return true
+		}
+	}
+
+	return false
+}
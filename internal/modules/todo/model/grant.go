@@ -0,0 +1,42 @@
+package model
+
+import "time"
+
+// Access is what a user can do with the items shared with them.
+type Access string
+
+const (
+	// AccessView lets the user read the items and their history.
+	AccessView Access = "view"
+	// AccessEdit lets the user change them too, like the owner, except for deleting and moving them.
+	AccessEdit Access = "edit"
+)
+
+func (a Access) Valid() bool {
+	return a == AccessView || a == AccessEdit
+}
+
+// Allows tells whether the access includes the other one, the edit access includes the view one.
+func (a Access) Allows(other Access) bool {
+	return a == AccessEdit || a == other && a.Valid()
+}
+
+// Wider returns the access allowing the other one, either is empty when there is none.
+func (a Access) Wider(other Access) Access {
+	if other == "" || a.Allows(other) {
+		return a
+	}
+
+	return other
+}
+
+// Grant shares an item, or every item of a list, with a user. Exactly one of ItemID and ListID is set.
+// A user has a single grant per item and list, the item of a shared list may be shared with them too:
+// the wider access applies.
+type Grant struct {
+	ItemID    string    `json:"item_id,omitempty" gorm:"primaryKey"`
+	ListID    string    `json:"list_id,omitempty" gorm:"primaryKey"`
+	UserID    string    `json:"user_id" gorm:"primaryKey;index"`
+	Access    Access    `json:"access"`
+	CreatedAt time.Time `json:"created_at"`
+}
@@ -8,11 +8,23 @@ import (
 )
 
 type Item struct {
-	ID        string         `json:"-" gorm:"primaryKey"`
-	Title     string         `json:"title,omitempty"`
-	CreatedAt time.Time      `json:"-"`
-	UpdatedAt time.Time      `json:"-"`
-	DeletedAt gorm.DeletedAt `json:"-" sql:"index"`
+	ID          string         `json:"id" gorm:"primaryKey"`
+	OwnerID     string         `json:"owner_id" gorm:"index"`
+	ListID      string         `json:"list_id" gorm:"index"`
+	ParentID    string         `json:"parent_id,omitempty" gorm:"index"` // empty for top level items
+	Title       string         `json:"title,omitempty"`
+	Description string         `json:"description,omitempty"`
+	Completed   bool           `json:"completed"`
+	CompletedAt *time.Time     `json:"completed_at,omitempty"`
+	DueAt       *time.Time     `json:"due_at,omitempty"`
+	Priority    Priority       `json:"priority"`
+	Tags        []string       `json:"tags" gorm:"-"`          // names, stored in the item_tags join table
+	Recurrence  string         `json:"recurrence,omitempty"`   // RRULE, e.g. FREQ=WEEKLY;BYDAY=MO
+	SeriesStart *time.Time     `json:"series_start,omitempty"` // due date of the first occurrence
+	Version     int64          `json:"version"`                // incremented by every update
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `json:"-" sql:"index"`
 }
 
 // BeforeCreate will set a UUID rather than numeric ID.
@@ -0,0 +1,74 @@
+package model
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Priority of an item. It's stored as a number so items can be ordered by it,
+// and serialized to JSON as a string.
+type Priority int
+
+const (
+	PriorityNone Priority = iota
+	PriorityLow
+	PriorityMedium
+	PriorityHigh
+)
+
+var priorityNames = map[Priority]string{
+	PriorityNone:   "none",
+	PriorityLow:    "low",
+	PriorityMedium: "medium",
+	PriorityHigh:   "high",
+}
+
+// ParsePriority converts the string form back to Priority. Empty string means PriorityNone.
+func ParsePriority(s string) (Priority, error) {
+	if s == "" {
+		return PriorityNone, nil
+	}
+	for p, name := range priorityNames {
+		if name == s {
+			return p, nil
+		}
+	}
+
+	return PriorityNone, fmt.Errorf("unknown priority %q", s)
+}
+
+func (p Priority) Valid() bool {
+	_, ok := priorityNames[p]
+	return ok
+}
+
+func (p Priority) String() string {
+	if name, ok := priorityNames[p]; ok {
+		return name
+	}
+
+	return fmt.Sprintf("Priority(%d)", int(p))
+}
+
+func (p Priority) MarshalJSON() ([]byte, error) {
+	if !p.Valid() {
+		return nil, fmt.Errorf("unknown priority %d", int(p))
+	}
+
+	return json.Marshal(p.String())
+}
+
+func (p *Priority) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return err
+	}
+
+	parsed, err := ParsePriority(s)
+	if err != nil {
+		return err
+	}
+	*p = parsed
+
+	return nil
+}
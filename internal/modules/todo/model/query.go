@@ -0,0 +1,96 @@
+package model
+
+import "time"
+
+// SortField is the item attribute GetAllItems orders by.
+type SortField string
+
+const (
+	SortCreated  SortField = "created"
+	SortUpdated  SortField = "updated"
+	SortDue      SortField = "due"
+	SortPriority SortField = "priority"
+)
+
+func (f SortField) Valid() bool {
+	switch f {
+	case SortCreated, SortUpdated, SortDue, SortPriority:
+		return true
+	}
+
+	return false
+}
+
+// Query describes which items GetAllItems returns and in which order.
+// Nil and empty fields don't filter anything.
+type Query struct {
+	OwnerID string
+	// Shared selects the items other users shared with OwnerID instead of the ones it owns
+	Shared     bool
+	ListID     string
+	Completed  *bool
+	DueBefore  *time.Time // exclusive
+	DueAfter   *time.Time // exclusive
+	Priorities []Priority
+	Search     string // case-insensitive substring of the title or description
+	Tags       []string
+	TagMode    TagMode
+
+	Sort SortField
+	Desc bool
+
+	// Cursor is the opaque next_cursor of the previous page, the use case decodes it into After.
+	Cursor   string
+	After    *Cursor
+	PageSize int
+}
+
+// Cursor is the sort key of the last item on a page: items strictly after it form the next page.
+// The sort field value is followed by (created_at, id), which makes the order total.
+type Cursor struct {
+	Sort      SortField  `json:"s"`
+	Desc      bool       `json:"d,omitempty"`
+	UpdatedAt time.Time  `json:"u"`
+	DueAt     *time.Time `json:"due,omitempty"`
+	Priority  Priority   `json:"p,omitempty"`
+	CreatedAt time.Time  `json:"c"`
+	ID        string     `json:"i"`
+}
+
+// CursorOf returns the position of the item in the given order.
+func CursorOf(item Item, sort SortField, desc bool) Cursor {
+	c := Cursor{
+		Sort:      sort,
+		Desc:      desc,
+		CreatedAt: item.CreatedAt,
+		ID:        item.ID,
+	}
+
+	switch sort {
+	case SortUpdated:
+		c.UpdatedAt = item.UpdatedAt
+	case SortDue:
+		c.DueAt = item.DueAt
+	case SortPriority:
+		c.Priority = item.Priority
+	}
+
+	return c
+}
+
+// Item returns an item with the cursor's sort key, handy to compare items against the cursor.
+func (c Cursor) Item() Item {
+	return Item{
+		ID:        c.ID,
+		CreatedAt: c.CreatedAt,
+		UpdatedAt: c.UpdatedAt,
+		DueAt:     c.DueAt,
+		Priority:  c.Priority,
+	}
+}
+
+// Page is a chunk of GetAllItems results.
+type Page struct {
+	Items      []Item `json:"items"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
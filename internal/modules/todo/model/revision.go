@@ -0,0 +1,142 @@
+package model
+
+import (
+	"bytes"
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+type RevisionAction string
+
+const (
+	RevisionCreated  RevisionAction = "created"
+	RevisionUpdated  RevisionAction = "updated"
+	RevisionDeleted  RevisionAction = "deleted"
+	RevisionRestored RevisionAction = "restored"
+)
+
+// Revision is an immutable record of a change of an item.
+type Revision struct {
+	ItemID string `json:"item_id" gorm:"primaryKey"`
+	// Rev numbers the changes of the item from 1
+	Rev     int64          `json:"rev" gorm:"primaryKey;autoIncrement:false"`
+	OwnerID string         `json:"-"`
+	Action  RevisionAction `json:"action"`
+	// ActorID is the user who made the change, empty for the changes the service makes on its own
+	ActorID string  `json:"actor_id,omitempty"`
+	Changes Changes `json:"changes"`
+	// Item is the JSON of the item after the change, it's left out of the history lists
+	Item      json.RawMessage `json:"item,omitempty"`
+	CreatedAt time.Time       `json:"created_at"`
+}
+
+func (Revision) TableName() string {
+	return "item_revisions"
+}
+
+// Change is a changed field of the item: its JSON values before and after the change,
+// null when the field was absent.
+type Change struct {
+	Field string          `json:"field"`
+	From  json.RawMessage `json:"from"`
+	To    json.RawMessage `json:"to"`
+}
+
+// Changes are stored as JSON, so every backend keeps them in a text column.
+type Changes []Change
+
+func (c Changes) Value() (driver.Value, error) {
+	data, err := json.Marshal(c)
+	return string(data), err
+}
+
+func (c *Changes) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*c = nil
+		return nil
+	case string:
+		return json.Unmarshal([]byte(v), c)
+	case []byte:
+		return json.Unmarshal(v, c)
+	default:
+		return fmt.Errorf("unsupported changes type %T", src)
+	}
+}
+
+// unversionedFields are the item fields maintained along every change, they aren't reported by Diff.
+var unversionedFields = map[string]bool{
+	"id": true, "owner_id": true, "version": true, "created_at": true, "updated_at": true,
+}
+
+// NewRevision describes the change of the item from before to after, before is the zero Item
+// for a created one. The revision number and the actor are left to the caller.
+func NewRevision(action RevisionAction, before, after Item) (Revision, error) {
+	changes, err := Diff(before, after)
+	if err != nil {
+		return Revision{}, err
+	}
+	snapshot, err := json.Marshal(after)
+	if err != nil {
+		return Revision{}, err
+	}
+
+	return Revision{
+		ItemID:    after.ID,
+		OwnerID:   after.OwnerID,
+		Action:    action,
+		Changes:   changes,
+		Item:      snapshot,
+		CreatedAt: time.Now(),
+	}, nil
+}
+
+// Diff compares the JSON fields of the items, ordered by name.
+func Diff(before, after Item) (Changes, error) {
+	old, err := fields(before)
+	if err != nil {
+		return nil, err
+	}
+	changed, err := fields(after)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make([]string, 0, len(changed))
+	for name := range changed {
+		names = append(names, name)
+	}
+	for name := range old {
+		if _, ok := changed[name]; !ok {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+
+	res := Changes{}
+	for _, name := range names {
+		if !unversionedFields[name] && !bytes.Equal(old[name], changed[name]) {
+			res = append(res, Change{Field: name, From: old[name], To: changed[name]})
+		}
+	}
+
+	return res, nil
+}
+
+func fields(item Item) (map[string]json.RawMessage, error) {
+	// no tags and an empty list of them are the same
+	if item.Tags == nil {
+		item.Tags = []string{}
+	}
+	data, err := json.Marshal(item)
+	if err != nil {
+		return nil, err
+	}
+
+	var res map[string]json.RawMessage
+	err = json.Unmarshal(data, &res)
+	return res, err
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+)
+
+// Tag is a label attached to items. Names are unique per owner.
+type Tag struct {
+	ID        string    `json:"id" gorm:"primaryKey"`
+	OwnerID   string    `json:"owner_id" gorm:"uniqueIndex:idx_tags_owner_name"`
+	Name      string    `json:"name" gorm:"uniqueIndex:idx_tags_owner_name"`
+	ItemCount int64     `json:"item_count" gorm:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (t *Tag) BeforeCreate(tx *gorm.DB) error {
+	t.ID = uuid.New().String()
+	return nil
+}
+
+// ItemTag is the many-to-many join of items and tags.
+type ItemTag struct {
+	ItemID string `gorm:"primaryKey"`
+	TagID  string `gorm:"primaryKey;index"`
+}
+
+// TagMode tells whether items must carry any or all of the queried tags.
+type TagMode string
+
+const (
+	TagModeAny TagMode = "any"
+	TagModeAll TagMode = "all"
+)
@@ -0,0 +1,9 @@
+package model
+
+import "time"
+
+// TrashedItem is a deleted item, it's kept in the trash until it's restored or purged.
+type TrashedItem struct {
+	Item
+	DeletedAt time.Time `json:"deleted_at"`
+}
@@ -0,0 +1,56 @@
+package model
+
+// Node is an item with its subtasks.
+type Node struct {
+	Item
+	// Progress is the percentage of completed subtasks at any depth.
+	// An item without subtasks is either 0 or 100 done.
+	Progress float64 `json:"progress"`
+	Children []Node  `json:"children"`
+}
+
+// NewTree assembles the subtree of the root from a flat list of the root and its descendants.
+func NewTree(root Item, descendants []Item) Node {
+	children := make(map[string][]Item)
+	for _, item := range descendants {
+		children[item.ParentID] = append(children[item.ParentID], item)
+	}
+
+	node, _, _ := newNode(root, children)
+	return node
+}
+
+// newNode returns the node with the number of its descendants and how many of them are completed.
+func newNode(item Item, children map[string][]Item) (node Node, total, completed int) {
+	node = Node{Item: item, Children: []Node{}}
+	for _, child := range children[item.ID] {
+		childNode, childTotal, childCompleted := newNode(child, children)
+		node.Children = append(node.Children, childNode)
+
+		total += childTotal + 1
+		completed += childCompleted
+		if child.Completed {
+			completed++
+		}
+	}
+
+	switch {
+	case total > 0:
+		node.Progress = float64(completed) * 100 / float64(total)
+	case item.Completed:
+		node.Progress = 100
+	}
+
+	return node, total, completed
+}
+
+// Open returns the items which aren't completed.
+func Open(items []Item) (res []Item) {
+	for _, item := range items {
+		if !item.Completed {
+			res = append(res, item)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,15 @@
+package todo
+
+// CompletionPolicy decides what completing an item with open subtasks does.
+type CompletionPolicy string
+
+const (
+	// RefuseCompletion fails with ErrOpenSubtasks.
+	RefuseCompletion CompletionPolicy = "refuse"
+	// CascadeCompletion completes the open subtasks too.
+	CascadeCompletion CompletionPolicy = "cascade"
+)
+
+func (p CompletionPolicy) Valid() bool {
+	return p == RefuseCompletion || p == CascadeCompletion
+}
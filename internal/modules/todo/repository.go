@@ -2,14 +2,74 @@ package todo
 
 import (
 	"context"
+	"time"
 
 	"github.com/silverspase/todo/internal/modules/todo/model"
 )
 
+// Repository stores items. Every method is scoped to the owner: items of other users
+// are reported as ErrNotFound. The methods reading the items of a user take the ones shared
+// with them too, as noted. Every change of an item is recorded as a revision by the Actor
+// of ctx, in the transaction of the change.
 type Repository interface {
 	CreateItem(ctx context.Context, items model.Item) (string, error)
-	GetAllItems(ctx context.Context, page int) ([]model.Item, error)
-	GetItem(ctx context.Context, id string) (model.Item, error)
+	// GetAllItems expects a normalized query: the owner, sort field and page size are always set.
+	// It returns at most query.PageSize items following query.After.
+	GetAllItems(ctx context.Context, query model.Query) ([]model.Item, error)
+	// GetItem returns the item of the user or the one shared with them.
+	GetItem(ctx context.Context, userID, id string) (model.Item, error)
+	// UpdateItem saves the item and increments its version. The stored version must be
+	// item.Version, ErrVersionMismatch is returned otherwise; 0 skips the check.
 	UpdateItem(ctx context.Context, item model.Item) (string, error)
-	DeleteItem(ctx context.Context, id string) (string, error)
+	// DeleteItem moves the item together with its subtasks to the trash, they share the deletion time.
+	// The version is checked the same way UpdateItem does.
+	DeleteItem(ctx context.Context, ownerID, id string, version int64) (string, error)
+	// GetSubtasks returns all descendants of the item of the user or the one shared with them,
+	// at any depth, ordered by (created_at, id).
+	GetSubtasks(ctx context.Context, userID, id string) ([]model.Item, error)
+	// DeleteListItems moves every item of the list to the trash and returns them. It takes part
+	// in the transaction of a list.Repository given in ctx.
+	DeleteListItems(ctx context.Context, ownerID, listID string) ([]model.Item, error)
+
+	// GetTrash returns the owner's deleted items, the latest deleted first, with DeletedAt set.
+	// The subtasks deleted together with their parent are left out: they are restored and purged with it.
+	GetTrash(ctx context.Context, ownerID string) ([]model.Item, error)
+	// GetTrashedItem returns a deleted item, the items which are not in the trash are ErrNotFound.
+	GetTrashedItem(ctx context.Context, ownerID, id string) (model.Item, error)
+	// RestoreItem takes the deleted item out of the trash, together with the subtasks deleted with it,
+	// and increments their versions.
+	RestoreItem(ctx context.Context, ownerID, id string) (string, error)
+	// PurgeItem permanently deletes the deleted item and its subtasks, with their revisions and grants.
+	PurgeItem(ctx context.Context, ownerID, id string) (string, error)
+	// PurgeTrash permanently deletes the items of every owner deleted before the time,
+	// with their revisions and grants, and returns their number.
+	PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error)
+
+	// GetRevisions returns the revisions of a live or deleted item of the user or shared with them,
+	// the oldest first, without the item JSON.
+	GetRevisions(ctx context.Context, userID, id string) ([]model.Revision, error)
+	// GetRevision returns a revision of a live or deleted item of the user or shared with them,
+	// ErrRevisionNotFound when the item has no such revision.
+	GetRevision(ctx context.Context, userID, id string, rev int64) (model.Revision, error)
+
+	// SaveGrant shares the item or the list of the grant with its user, or changes the access
+	// of the existing grant. The caller makes sure the owner shares them.
+	SaveGrant(ctx context.Context, grant model.Grant) error
+	// DeleteGrant revokes the grant of the item or the list given, ErrGrantNotFound when there is none.
+	DeleteGrant(ctx context.Context, grant model.Grant) error
+	// GetGrants returns the grants of the item and of the list, ordered by (created_at, user_id).
+	// An empty ID selects none.
+	GetGrants(ctx context.Context, itemID, listID string) ([]model.Grant, error)
+	// GetAccess returns the access to the item shared with the user, directly or by its list,
+	// the wider one when both are. It's empty when the item isn't shared with them.
+	GetAccess(ctx context.Context, userID string, item model.Item) (model.Access, error)
+	// GetUserGrants returns the grants made to the user, ordered by (created_at, item_id, list_id).
+	GetUserGrants(ctx context.Context, userID string) ([]model.Grant, error)
+
+	// GetAllTags returns the user's tags and the ones of the items shared with them, ordered by
+	// (name, owner_id), with the number of the items they see carrying each.
+	GetAllTags(ctx context.Context, userID string) ([]model.Tag, error)
+	// RenameTag renames the tag and returns its ID. When the owner already has a tag with
+	// the new name, the two are merged into the existing one and its ID is returned.
+	RenameTag(ctx context.Context, ownerID, id, name string) (string, error)
 }
@@ -0,0 +1,115 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (m *memoryStorage) SaveGrant(ctx context.Context, grant model.Grant) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	grants, id := m.grantsOf(grant)
+	if grants[id] == nil {
+		grants[id] = make(map[string]model.Grant)
+	}
+	if current, ok := grants[id][grant.UserID]; ok {
+		grant.CreatedAt = current.CreatedAt
+	} else {
+		grant.CreatedAt = time.Now()
+	}
+	grants[id][grant.UserID] = grant
+
+	return nil
+}
+
+func (m *memoryStorage) DeleteGrant(ctx context.Context, grant model.Grant) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	grants, id := m.grantsOf(grant)
+	if _, ok := grants[id][grant.UserID]; !ok {
+		return todo.ErrGrantNotFound
+	}
+	delete(grants[id], grant.UserID)
+
+	return nil
+}
+
+func (m *memoryStorage) GetGrants(ctx context.Context, itemID, listID string) ([]model.Grant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	res := make([]model.Grant, 0, len(m.itemGrants[itemID])+len(m.listGrants[listID]))
+	for _, grant := range m.itemGrants[itemID] {
+		res = append(res, grant)
+	}
+	for _, grant := range m.listGrants[listID] {
+		res = append(res, grant)
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.Before(res[j].CreatedAt)
+		}
+		return res[i].UserID < res[j].UserID
+	})
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetAccess(ctx context.Context, userID string, item model.Item) (model.Access, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.access(userID, item), nil
+}
+
+func (m *memoryStorage) GetUserGrants(ctx context.Context, userID string) ([]model.Grant, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var res []model.Grant
+	for _, grants := range []map[string]map[string]model.Grant{m.itemGrants, m.listGrants} {
+		for _, byUser := range grants {
+			if grant, ok := byUser[userID]; ok {
+				res = append(res, grant)
+			}
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.Before(res[j].CreatedAt)
+		}
+		if res[i].ItemID != res[j].ItemID {
+			return res[i].ItemID < res[j].ItemID
+		}
+		return res[i].ListID < res[j].ListID
+	})
+
+	return res, nil
+}
+
+// grantsOf returns the grants of the item or the list of the grant by the user, and its ID.
+func (m *memoryStorage) grantsOf(grant model.Grant) (map[string]map[string]model.Grant, string) {
+	if grant.ItemID != "" {
+		return m.itemGrants, grant.ItemID
+	}
+
+	return m.listGrants, grant.ListID
+}
+
+// access returns the wider access of the grants sharing the item with the user. Callers hold the lock.
+func (m *memoryStorage) access(userID string, item model.Item) model.Access {
+	return m.itemGrants[item.ID][userID].Access.Wider(m.listGrants[item.ListID][userID].Access)
+}
+
+// visible tells whether the item is the user's or is shared with them. Callers hold the lock.
+func (m *memoryStorage) visible(userID string, item model.Item) bool {
+	return item.OwnerID == userID || m.access(userID, item) != ""
+}
@@ -2,70 +2,318 @@ package memory
 
 import (
 	"context"
-	"errors"
+	"sort"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/google/uuid"
 	"go.uber.org/zap"
+	"gorm.io/gorm"
 
 	"github.com/silverspase/todo/internal/modules/todo"
 	"github.com/silverspase/todo/internal/modules/todo/model"
 )
 
 type memoryStorage struct {
-	items map[string]model.Item // TODO change to sync.Map
-	// itemsArray []model.Item // TODO use this for pagination in GetAllItems
-	logger *zap.Logger
+	mu    sync.RWMutex
+	items map[string]model.Item
+	// trash holds the deleted items with DeletedAt set, their tags are kept in itemTags
+	trash map[string]model.Item
+	// revisions of every item by its ID, the oldest first
+	revisions map[string][]model.Revision
+	// tags by ID and the tag IDs of every item, item.Tags isn't stored
+	tags     map[string]model.Tag
+	itemTags map[string]map[string]struct{}
+	// grants of the items and of the lists by their ID, then by the user ID
+	itemGrants map[string]map[string]model.Grant
+	listGrants map[string]map[string]model.Grant
+	logger     *zap.Logger
 }
 
 func NewMemoryStorage(logger *zap.Logger) todo.Repository {
 	return &memoryStorage{
-		items:  make(map[string]model.Item),
-		logger: logger,
+		items:      make(map[string]model.Item),
+		trash:      make(map[string]model.Item),
+		revisions:  make(map[string][]model.Revision),
+		tags:       make(map[string]model.Tag),
+		itemTags:   make(map[string]map[string]struct{}),
+		itemGrants: make(map[string]map[string]model.Grant),
+		listGrants: make(map[string]map[string]model.Grant),
+		logger:     logger,
 	}
 }
 
-func (m memoryStorage) CreateItem(ctx context.Context, item model.Item) (string, error) {
+func (m *memoryStorage) CreateItem(ctx context.Context, item model.Item) (string, error) {
 	m.logger.Debug("CreateItem")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	item.ID = uuid.New().String()
+	item.CreatedAt = now
+	item.UpdatedAt = now
+	item.Version = 1
+	m.setTags(item.OwnerID, item.ID, item.Tags)
+	item.Tags = nil
 	m.items[item.ID] = item
 
+	if err := m.record(ctx, model.RevisionCreated, model.Item{}, item); err != nil {
+		return "", err
+	}
+
 	return item.ID, nil
 }
 
-func (m memoryStorage) GetAllItems(ctx context.Context, page int) (res []model.Item, err error) {
+func (m *memoryStorage) GetAllItems(ctx context.Context, query model.Query) (res []model.Item, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	for _, item := range m.items {
+		if query.Shared && (item.OwnerID == query.OwnerID || m.access(query.OwnerID, item) == "") {
+			continue
+		}
+		if !query.Shared && item.OwnerID != query.OwnerID {
+			continue
+		}
+		item = m.withTags(item)
+		if !matches(item, query) {
+			continue
+		}
+		if query.After != nil && !less(query.After.Item(), item, query.Sort, query.Desc) {
+			continue
+		}
 		res = append(res, item)
 	}
 
+	sort.Slice(res, func(i, j int) bool {
+		return less(res[i], res[j], query.Sort, query.Desc)
+	})
+
+	if len(res) > query.PageSize {
+		res = res[:query.PageSize]
+	}
+
 	return res, nil
 }
 
-func (m memoryStorage) GetItem(ctx context.Context, id string) (model.Item, error) {
+func (m *memoryStorage) GetItem(ctx context.Context, userID, id string) (model.Item, error) {
 	m.logger.Debug("GetItem")
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
 	item, ok := m.items[id]
-	if !ok {
-		return item, errors.New("not found")
+	if !ok || !m.visible(userID, item) {
+		return model.Item{}, todo.ErrNotFound
 	}
 
-	return item, nil
+	return m.withTags(item), nil
 }
 
-func (m memoryStorage) UpdateItem(ctx context.Context, item model.Item) (string, error) {
-	_, ok := m.items[item.ID]
-	if !ok {
-		return "", errors.New("item with given id not found, nothing to update")
+func (m *memoryStorage) UpdateItem(ctx context.Context, item model.Item) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.items[item.ID]
+	if !ok || current.OwnerID != item.OwnerID {
+		return "", todo.ErrNotFound
+	}
+	if item.Version != 0 && item.Version != current.Version {
+		return "", todo.ErrVersionMismatch
 	}
 
-	m.items[item.ID] = item
+	before := m.withTags(current)
+	current.ListID = item.ListID
+	current.ParentID = item.ParentID
+	current.Title = item.Title
+	current.Description = item.Description
+	current.Completed = item.Completed
+	current.CompletedAt = item.CompletedAt
+	current.DueAt = item.DueAt
+	current.Priority = item.Priority
+	current.Recurrence = item.Recurrence
+	current.SeriesStart = item.SeriesStart
+	current.UpdatedAt = time.Now()
+	current.Version++
+	m.setTags(current.OwnerID, current.ID, item.Tags)
+	m.items[item.ID] = current
+
+	if err := m.record(ctx, model.RevisionUpdated, before, current); err != nil {
+		return "", err
+	}
 
 	return item.ID, nil
 }
 
-func (m memoryStorage) DeleteItem(ctx context.Context, id string) (string, error) {
-	_, ok := m.items[id]
-	if !ok {
-		return "", errors.New("item with given id not found, nothing to delete")
+func (m *memoryStorage) DeleteItem(ctx context.Context, ownerID, id string, version int64) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.items[id]
+	if !ok || item.OwnerID != ownerID {
+		return "", todo.ErrNotFound
+	}
+	if version != 0 && version != item.Version {
+		return "", todo.ErrVersionMismatch
 	}
 
-	delete(m.items, id)
+	now := time.Now()
+	for _, deleted := range append(m.subtasks(id), item) {
+		if err := m.moveToTrash(ctx, deleted, now); err != nil {
+			return "", err
+		}
+	}
 
 	return id, nil
 }
+
+func (m *memoryStorage) GetSubtasks(ctx context.Context, userID, id string) ([]model.Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.items[id]
+	if !ok || !m.visible(userID, item) {
+		return nil, todo.ErrNotFound
+	}
+
+	res := m.subtasks(id)
+	for i := range res {
+		res[i] = m.withTags(res[i])
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return less(res[i], res[j], model.SortCreated, false)
+	})
+
+	return res, nil
+}
+
+// subtasks collects the descendants of the item. Callers hold the lock.
+func (m *memoryStorage) subtasks(id string) (res []model.Item) {
+	children := make(map[string][]model.Item)
+	for _, item := range m.items {
+		if item.ParentID != "" {
+			children[item.ParentID] = append(children[item.ParentID], item)
+		}
+	}
+
+	queue := []string{id}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			res = append(res, child)
+			queue = append(queue, child.ID)
+		}
+		queue = queue[1:]
+	}
+
+	return res
+}
+
+func (m *memoryStorage) DeleteListItems(ctx context.Context, ownerID, listID string) ([]model.Item, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	var deleted []model.Item
+	for _, item := range m.items {
+		if item.OwnerID == ownerID && item.ListID == listID {
+			if err := m.moveToTrash(ctx, item, now); err != nil {
+				return deleted, err
+			}
+			deleted = append(deleted, item)
+		}
+	}
+
+	return deleted, nil
+}
+
+// moveToTrash deletes the item at the time. Callers hold the write lock.
+func (m *memoryStorage) moveToTrash(ctx context.Context, item model.Item, at time.Time) error {
+	before := m.withTags(item)
+	item.DeletedAt = gorm.DeletedAt{Time: at, Valid: true}
+	delete(m.items, item.ID)
+	m.trash[item.ID] = item
+
+	return m.record(ctx, model.RevisionDeleted, before, item)
+}
+
+// matches applies the filters of the query, the owner is checked by the caller.
+func matches(item model.Item, q model.Query) bool {
+	if q.ListID != "" && item.ListID != q.ListID {
+		return false
+	}
+	if q.Completed != nil && item.Completed != *q.Completed {
+		return false
+	}
+	if q.DueBefore != nil && (item.DueAt == nil || !item.DueAt.Before(*q.DueBefore)) {
+		return false
+	}
+	if q.DueAfter != nil && (item.DueAt == nil || !item.DueAt.After(*q.DueAfter)) {
+		return false
+	}
+	if len(q.Priorities) > 0 {
+		found := false
+		for _, p := range q.Priorities {
+			if item.Priority == p {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	if len(q.Tags) > 0 && !hasTags(item.Tags, q.Tags, q.TagMode) {
+		return false
+	}
+	if q.Search != "" {
+		search := strings.ToLower(q.Search)
+		if !strings.Contains(strings.ToLower(item.Title), search) &&
+			!strings.Contains(strings.ToLower(item.Description), search) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// less orders items the same way the postgres repository does:
+// by the sort field, then by creation time and ID, items without due date are always last.
+func less(a, b model.Item, field model.SortField, desc bool) bool {
+	if field == model.SortDue && (a.DueAt == nil) != (b.DueAt == nil) {
+		return a.DueAt != nil
+	}
+
+	var c int
+	switch field {
+	case model.SortUpdated:
+		c = compareTime(a.UpdatedAt, b.UpdatedAt)
+	case model.SortDue:
+		if a.DueAt != nil && b.DueAt != nil {
+			c = compareTime(*a.DueAt, *b.DueAt)
+		}
+	case model.SortPriority:
+		c = int(a.Priority) - int(b.Priority)
+	}
+	if c == 0 {
+		c = compareTime(a.CreatedAt, b.CreatedAt)
+	}
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+
+	if desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,17 @@
+package memory_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/repository/memory"
+	"github.com/silverspase/todo/internal/modules/todo/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	repositorytest.Run(t, func(t *testing.T) todo.Repository {
+		return memory.NewMemoryStorage(zap.NewNop())
+	})
+}
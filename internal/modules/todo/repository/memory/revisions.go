@@ -0,0 +1,66 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (m *memoryStorage) GetRevisions(ctx context.Context, userID, id string) ([]model.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.exists(userID, id) {
+		return nil, todo.ErrNotFound
+	}
+
+	res := make([]model.Revision, 0, len(m.revisions[id]))
+	for _, revision := range m.revisions[id] {
+		revision.Item = nil
+		res = append(res, revision)
+	}
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetRevision(ctx context.Context, userID, id string, rev int64) (model.Revision, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	if !m.exists(userID, id) {
+		return model.Revision{}, todo.ErrNotFound
+	}
+
+	revisions := m.revisions[id]
+	if rev < 1 || rev > int64(len(revisions)) {
+		return model.Revision{}, todo.ErrRevisionNotFound
+	}
+
+	return revisions[rev-1], nil
+}
+
+// exists tells whether the user has the item, live or deleted, or it's shared with them. Callers hold the lock.
+func (m *memoryStorage) exists(userID, id string) bool {
+	item, ok := m.items[id]
+	if !ok {
+		item, ok = m.trash[id]
+	}
+
+	return ok && m.visible(userID, item)
+}
+
+// record appends the revision of the item's change, before is its state prior to the change
+// with the tags loaded. Callers hold the write lock and call it once the change is stored.
+func (m *memoryStorage) record(ctx context.Context, action model.RevisionAction, before, after model.Item) error {
+	revision, err := model.NewRevision(action, before, m.withTags(after))
+	if err != nil {
+		return err
+	}
+
+	revision.Rev = int64(len(m.revisions[after.ID])) + 1
+	revision.ActorID = todo.Actor(ctx)
+	m.revisions[after.ID] = append(m.revisions[after.ID], revision)
+
+	return nil
+}
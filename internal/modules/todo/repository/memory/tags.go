@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (m *memoryStorage) GetAllTags(ctx context.Context, userID string) (res []model.Tag, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	// deleted items don't count
+	counts := make(map[string]int64)
+	for itemID, tagIDs := range m.itemTags {
+		if item, ok := m.items[itemID]; !ok || !m.visible(userID, item) {
+			continue
+		}
+		for tagID := range tagIDs {
+			counts[tagID]++
+		}
+	}
+
+	// the tags of the other owners are listed while they carry a shared item
+	for _, tag := range m.tags {
+		if tag.OwnerID == userID || counts[tag.ID] > 0 {
+			tag.ItemCount = counts[tag.ID]
+			res = append(res, tag)
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+		return res[i].OwnerID < res[j].OwnerID
+	})
+
+	return res, nil
+}
+
+func (m *memoryStorage) RenameTag(ctx context.Context, ownerID, id, name string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	tag, ok := m.tags[id]
+	if !ok || tag.OwnerID != ownerID {
+		return "", todo.ErrTagNotFound
+	}
+
+	target, exists := m.findTag(ownerID, name)
+	if exists && target.ID == id {
+		return id, nil
+	}
+	// the tag names are a part of the items, the deleted ones get a new version when restored
+	var before []model.Item
+	for itemID, tagIDs := range m.itemTags {
+		item, live := m.items[itemID]
+		if _, ok := tagIDs[id]; ok && live {
+			before = append(before, m.withTags(item))
+			item.Version++
+			m.items[itemID] = item
+		}
+	}
+	resultID := id
+	if !exists {
+		tag.Name = name
+		m.tags[id] = tag
+	} else {
+		// merge: the items of the renamed tag get the existing one
+		m.mergeTag(id, target.ID)
+		resultID = target.ID
+	}
+
+	for _, item := range before {
+		if err := m.record(ctx, model.RevisionUpdated, item, m.items[item.ID]); err != nil {
+			return "", err
+		}
+	}
+
+	return resultID, nil
+}
+
+// mergeTag gives the items of the tag the target one instead and deletes it. Callers hold the write lock.
+func (m *memoryStorage) mergeTag(id, targetID string) {
+	for _, tagIDs := range m.itemTags {
+		if _, ok := tagIDs[id]; ok {
+			delete(tagIDs, id)
+			tagIDs[targetID] = struct{}{}
+		}
+	}
+	delete(m.tags, id)
+}
+
+// setTags replaces the item's tags, creating the missing ones. Callers hold the write lock.
+func (m *memoryStorage) setTags(ownerID, itemID string, names []string) {
+	tagIDs := make(map[string]struct{}, len(names))
+	for _, name := range names {
+		tag, ok := m.findTag(ownerID, name)
+		if !ok {
+			tag = model.Tag{
+				ID:        uuid.New().String(),
+				OwnerID:   ownerID,
+				Name:      name,
+				CreatedAt: time.Now(),
+			}
+			m.tags[tag.ID] = tag
+		}
+		tagIDs[tag.ID] = struct{}{}
+	}
+
+	m.itemTags[itemID] = tagIDs
+}
+
+// withTags fills item.Tags from the index, sorted by name.
+func (m *memoryStorage) withTags(item model.Item) model.Item {
+	item.Tags = make([]string, 0, len(m.itemTags[item.ID]))
+	for tagID := range m.itemTags[item.ID] {
+		item.Tags = append(item.Tags, m.tags[tagID].Name)
+	}
+	sort.Strings(item.Tags)
+
+	return item
+}
+
+func (m *memoryStorage) findTag(ownerID, name string) (model.Tag, bool) {
+	for _, tag := range m.tags {
+		if tag.OwnerID == ownerID && tag.Name == name {
+			return tag, true
+		}
+	}
+
+	return model.Tag{}, false
+}
+
+func hasTags(itemTags, queried []string, mode model.TagMode) bool {
+	set := make(map[string]struct{}, len(itemTags))
+	for _, name := range itemTags {
+		set[name] = struct{}{}
+	}
+
+	for _, name := range queried {
+		_, ok := set[name]
+		if ok && mode == model.TagModeAny {
+			return true
+		}
+		if !ok && mode == model.TagModeAll {
+			return false
+		}
+	}
+
+	return mode == model.TagModeAll
+}
@@ -0,0 +1,139 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (m *memoryStorage) GetTrash(ctx context.Context, ownerID string) (res []model.Item, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, item := range m.trash {
+		if item.OwnerID != ownerID {
+			continue
+		}
+		// subtasks deleted with the parent go along with it
+		if parent, ok := m.trash[item.ParentID]; ok && parent.DeletedAt.Time.Equal(item.DeletedAt.Time) {
+			continue
+		}
+		res = append(res, m.withTags(item))
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if c := compareTime(res[i].DeletedAt.Time, res[j].DeletedAt.Time); c != 0 {
+			return c > 0
+		}
+		return strings.Compare(res[i].ID, res[j].ID) < 0
+	})
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetTrashedItem(ctx context.Context, ownerID, id string) (model.Item, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	item, ok := m.trash[id]
+	if !ok || item.OwnerID != ownerID {
+		return model.Item{}, todo.ErrNotFound
+	}
+
+	return m.withTags(item), nil
+}
+
+func (m *memoryStorage) RestoreItem(ctx context.Context, ownerID, id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.trash[id]
+	if !ok || item.OwnerID != ownerID {
+		return "", todo.ErrNotFound
+	}
+
+	now := time.Now()
+	deletedAt := item.DeletedAt.Time
+	for _, restored := range append(m.trashedSubtasks(id), item) {
+		if !restored.DeletedAt.Time.Equal(deletedAt) {
+			// deleted on its own before the parent
+			continue
+		}
+		before := m.withTags(restored)
+		restored.DeletedAt = gorm.DeletedAt{}
+		restored.UpdatedAt = now
+		restored.Version++
+		delete(m.trash, restored.ID)
+		m.items[restored.ID] = restored
+		if err := m.record(ctx, model.RevisionRestored, before, restored); err != nil {
+			return "", err
+		}
+	}
+
+	return id, nil
+}
+
+func (m *memoryStorage) PurgeItem(ctx context.Context, ownerID, id string) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	item, ok := m.trash[id]
+	if !ok || item.OwnerID != ownerID {
+		return "", todo.ErrNotFound
+	}
+
+	for _, purged := range append(m.trashedSubtasks(id), item) {
+		delete(m.trash, purged.ID)
+		delete(m.itemTags, purged.ID)
+		delete(m.revisions, purged.ID)
+		delete(m.itemGrants, purged.ID)
+	}
+
+	return id, nil
+}
+
+func (m *memoryStorage) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	var n int64
+	for id, item := range m.trash {
+		if item.DeletedAt.Time.Before(deletedBefore) {
+			delete(m.trash, id)
+			delete(m.itemTags, id)
+			delete(m.revisions, id)
+			delete(m.itemGrants, id)
+			n++
+		}
+	}
+
+	return n, nil
+}
+
+// trashedSubtasks collects the deleted descendants of a deleted item, the deletion of the parent
+// deletes every subtask. Callers hold the lock.
+func (m *memoryStorage) trashedSubtasks(id string) (res []model.Item) {
+	children := make(map[string][]model.Item)
+	for _, item := range m.trash {
+		if item.ParentID != "" {
+			children[item.ParentID] = append(children[item.ParentID], item)
+		}
+	}
+
+	queue := []string{id}
+	for len(queue) > 0 {
+		for _, child := range children[queue[0]] {
+			res = append(res, child)
+			queue = append(queue, child.ID)
+		}
+		queue = queue[1:]
+	}
+
+	return res
+}
@@ -0,0 +1,105 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// sharedItems is the condition of the items shared with the user of the @user argument,
+// by themselves or by their list.
+const sharedItems = `EXISTS (SELECT 1 FROM grants WHERE grants.user_id = @user
+	AND (grants.item_id = items.id OR (grants.list_id <> '' AND grants.list_id = items.list_id)))`
+
+func (p postgres) SaveGrant(ctx context.Context, grant model.Grant) error {
+	p.logger.Debug("SaveGrant", zap.String("item_id", grant.ItemID), zap.String("list_id", grant.ListID))
+
+	// granting again changes the access, the grant keeps its creation time
+	return p.conn.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "item_id"}, {Name: "list_id"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"access"}),
+	}).Create(&grant).Error
+}
+
+func (p postgres) DeleteGrant(ctx context.Context, grant model.Grant) error {
+	p.logger.Debug("DeleteGrant", zap.String("item_id", grant.ItemID), zap.String("list_id", grant.ListID))
+
+	res := p.conn.WithContext(ctx).
+		Where("item_id = ? AND list_id = ? AND user_id = ?", grant.ItemID, grant.ListID, grant.UserID).
+		Delete(&model.Grant{})
+	if res.Error != nil {
+		return res.Error
+	}
+	if res.RowsAffected == 0 {
+		return todo.ErrGrantNotFound
+	}
+
+	return nil
+}
+
+func (p postgres) GetGrants(ctx context.Context, itemID, listID string) ([]model.Grant, error) {
+	p.logger.Debug("GetGrants", zap.String("item_id", itemID), zap.String("list_id", listID))
+
+	grants := []model.Grant{}
+	err := p.conn.WithContext(ctx).
+		Where("(item_id <> '' AND item_id = ?) OR (list_id <> '' AND list_id = ?)", itemID, listID).
+		Order("created_at, user_id").Find(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+func (p postgres) GetAccess(ctx context.Context, userID string, item model.Item) (model.Access, error) {
+	var accesses []model.Access
+	err := p.conn.WithContext(ctx).Model(&model.Grant{}).
+		Where("user_id = ? AND (item_id = ? OR (list_id <> '' AND list_id = ?))", userID, item.ID, item.ListID).
+		Pluck("access", &accesses).Error
+	if err != nil {
+		return "", err
+	}
+
+	var access model.Access
+	for _, granted := range accesses {
+		access = access.Wider(granted)
+	}
+
+	return access, nil
+}
+
+func (p postgres) GetUserGrants(ctx context.Context, userID string) ([]model.Grant, error) {
+	p.logger.Debug("GetUserGrants", zap.String("user_id", userID))
+
+	grants := []model.Grant{}
+	err := p.conn.WithContext(ctx).Where("user_id = ?", userID).
+		Order("created_at, item_id, list_id").Find(&grants).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return grants, nil
+}
+
+// visible scopes the items to the ones of the user and the ones shared with them.
+func visible(userID string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		return db.Where("(owner_id = @user OR "+sharedItems+")", map[string]interface{}{"user": userID})
+	}
+}
+
+func getVisibleItem(db *gorm.DB, userID, id string) (model.Item, error) {
+	var item model.Item
+	err := db.Scopes(visible(userID)).Where("id = ?", id).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, todo.ErrNotFound
+	}
+
+	return item, err
+}
@@ -2,15 +2,25 @@ package postgres
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
 
 	"go.uber.org/zap"
 	"gorm.io/gorm"
 
+	"github.com/silverspase/todo/internal/app/repository/sql"
 	"github.com/silverspase/todo/internal/modules/todo"
 	"github.com/silverspase/todo/internal/modules/todo/model"
 )
 
-const pageSize = 2
+var sortColumns = map[model.SortField]string{
+	model.SortCreated:  "created_at",
+	model.SortUpdated:  "updated_at",
+	model.SortDue:      "due_at",
+	model.SortPriority: "priority",
+}
 
 type postgres struct {
 	conn   *gorm.DB
@@ -27,53 +37,139 @@ func NewRepository(conn *gorm.DB, logger *zap.Logger) todo.Repository {
 func (p postgres) CreateItem(ctx context.Context, item model.Item) (string, error) {
 	p.logger.Debug("CreateItem")
 
-	res := p.conn.Create(&item)
-	if res.Error != nil {
-		return "", res.Error
+	item.Version = 1
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&item).Error; err != nil {
+			return err
+		}
+		if err := setTags(tx, item.OwnerID, item.ID, item.Tags); err != nil {
+			return err
+		}
+
+		return record(ctx, tx, model.RevisionCreated, []string{item.ID}, nil)
+	})
+	if err != nil {
+		return "", err
 	}
 
-	p.logger.Debug("created", zap.Any("item", res))
+	p.logger.Debug("created", zap.String("id", item.ID))
 
 	return item.ID, nil
 }
 
-func (p postgres) GetAllItems(ctx context.Context, page int) (items []model.Item, err error) {
-	p.logger.Debug("GetAllItems", zap.Int("page", page))
-	if page < 1 {
-		page = 1
+func (p postgres) GetAllItems(ctx context.Context, query model.Query) (items []model.Item, err error) {
+	p.logger.Debug("GetAllItems", zap.Any("query", query))
+
+	db := p.conn.WithContext(ctx)
+	if query.Shared {
+		db = db.Where("owner_id <> @user AND "+sharedItems, map[string]interface{}{"user": query.OwnerID})
+	} else {
+		db = db.Where("owner_id = ?", query.OwnerID)
+	}
+	if query.ListID != "" {
+		db = db.Where("list_id = ?", query.ListID)
+	}
+	if query.Completed != nil {
+		db = db.Where("completed = ?", *query.Completed)
+	}
+	if query.DueBefore != nil {
+		db = db.Where("due_at < ?", *query.DueBefore)
+	}
+	if query.DueAfter != nil {
+		db = db.Where("due_at > ?", *query.DueAfter)
+	}
+	if len(query.Priorities) > 0 {
+		db = db.Where("priority IN ?", query.Priorities)
+	}
+	if len(query.Tags) > 0 {
+		db = db.Where("id IN (?)", taggedItems(p.conn, query.Tags, query.TagMode))
+	}
+	if query.Search != "" {
+		pattern := "%" + escapeLike(strings.ToLower(query.Search)) + "%"
+		db = db.Where(`(LOWER(title) LIKE ? ESCAPE '\' OR LOWER(description) LIKE ? ESCAPE '\')`, pattern, pattern)
+	}
+
+	if query.After != nil {
+		cond, args := keysetCondition(*query.After, query.Sort, query.Desc)
+		db = db.Where(cond, args)
+	}
+
+	direction := "ASC"
+	if query.Desc {
+		direction = "DESC"
 	}
+	// NULLS LAST keeps items without due date at the end in both directions
+	db = db.Order(fmt.Sprintf("%s %s NULLS LAST, created_at %s, id %s",
+		sortColumns[query.Sort], direction, direction, direction))
 
-	res := p.conn.Limit(pageSize).Offset((page - 1) * pageSize).Find(&items)
+	res := db.Limit(query.PageSize).Find(&items)
 	if res.Error != nil {
 		return nil, res.Error
 	}
 
+	if err = loadTags(p.conn.WithContext(ctx), items); err != nil {
+		return nil, err
+	}
+
 	return items, nil
 }
 
-func (p postgres) GetItem(ctx context.Context, id string) (model.Item, error) {
+func (p postgres) GetItem(ctx context.Context, userID, id string) (model.Item, error) {
 	p.logger.Debug("GetItem", zap.String("id", id))
 
-	item := model.Item{ID: id}
-	err := p.conn.First(&item).Error
-
+	item, err := getVisibleItem(p.conn.WithContext(ctx), userID, id)
 	if err != nil {
 		return item, err
 	}
 
-	return item, nil
+	items := []model.Item{item}
+	if err = loadTags(p.conn.WithContext(ctx), items); err != nil {
+		return item, err
+	}
+
+	return items[0], nil
 }
 
-func (p postgres) UpdateItem(ctx context.Context, newItem model.Item) (string, error) {
-	p.logger.Debug("UpdateItem", zap.String("id", newItem.ID))
+func (p postgres) UpdateItem(ctx context.Context, item model.Item) (string, error) {
+	p.logger.Debug("UpdateItem", zap.String("id", item.ID))
 
-	item, err := p.GetItem(ctx, newItem.ID)
-	if err != nil {
-		return "", err
-	}
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		before, err := states(tx, []string{item.ID})
+		if err != nil {
+			return err
+		}
 
-	item.Title = newItem.Title
-	err = p.conn.Save(&item).Error
+		// a single conditional UPDATE, so concurrent updates of one version can't both succeed
+		db := tx.Model(&model.Item{}).Where("id = ? AND owner_id = ?", item.ID, item.OwnerID)
+		if item.Version != 0 {
+			db = db.Where("version = ?", item.Version)
+		}
+		res := db.Updates(map[string]interface{}{
+			"list_id":      item.ListID,
+			"parent_id":    item.ParentID,
+			"title":        item.Title,
+			"description":  item.Description,
+			"completed":    item.Completed,
+			"completed_at": item.CompletedAt,
+			"due_at":       item.DueAt,
+			"priority":     item.Priority,
+			"recurrence":   item.Recurrence,
+			"series_start": item.SeriesStart,
+			"updated_at":   time.Now(),
+			"version":      gorm.Expr("version + 1"),
+		})
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return changedOrMissing(tx, item.OwnerID, item.ID)
+		}
+		if err = setTags(tx, item.OwnerID, item.ID, item.Tags); err != nil {
+			return err
+		}
+
+		return record(ctx, tx, model.RevisionUpdated, []string{item.ID}, before)
+	})
 	if err != nil {
 		return "", err
 	}
@@ -81,13 +177,167 @@ func (p postgres) UpdateItem(ctx context.Context, newItem model.Item) (string, e
 	return item.ID, nil
 }
 
-func (p postgres) DeleteItem(ctx context.Context, id string) (string, error) {
+func (p postgres) DeleteItem(ctx context.Context, ownerID, id string, version int64) (string, error) {
 	p.logger.Info("DeleteItem", zap.String("id", id))
 
-	err := p.conn.Delete(&model.Item{ID: id}).Error
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		subtasks, err := subtaskIDs(tx, ownerID, id)
+		if err != nil {
+			return err
+		}
+		deleted := append(subtasks, id)
+		before, err := states(tx, deleted)
+		if err != nil {
+			return err
+		}
+
+		// the subtasks share the deletion time of the item, which tells they are restored with it
+		now := time.Now()
+		db := tx.Model(&model.Item{}).Where("id = ? AND owner_id = ?", id, ownerID)
+		if version != 0 {
+			db = db.Where("version = ?", version)
+		}
+		res := db.UpdateColumn("deleted_at", now)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return changedOrMissing(tx, ownerID, id)
+		}
+		if len(subtasks) > 0 {
+			err = tx.Model(&model.Item{}).Where("id IN ?", subtasks).UpdateColumn("deleted_at", now).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return record(ctx, tx, model.RevisionDeleted, deleted, before)
+	})
 	if err != nil {
 		return "", err
 	}
 
 	return id, nil
 }
+
+func (p postgres) GetSubtasks(ctx context.Context, userID, id string) ([]model.Item, error) {
+	p.logger.Debug("GetSubtasks", zap.String("id", id))
+
+	db := p.conn.WithContext(ctx)
+	item, err := getVisibleItem(db, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	var items []model.Item
+	err = db.Raw(subtasksQuery+" SELECT * FROM items WHERE id IN (SELECT id FROM subtasks) ORDER BY created_at, id",
+		id, item.OwnerID).Scan(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err = loadTags(db, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (p postgres) DeleteListItems(ctx context.Context, ownerID, listID string) (items []model.Item, err error) {
+	p.logger.Info("DeleteListItems", zap.String("list_id", listID))
+
+	err = sql.Conn(ctx, p.conn).Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("owner_id = ? AND list_id = ?", ownerID, listID).Find(&items).Error
+		if err != nil || len(items) == 0 {
+			return err
+		}
+		ids := make([]string, 0, len(items))
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+		before, err := states(tx, ids)
+		if err != nil {
+			return err
+		}
+
+		if err = tx.Where("id IN ?", ids).Delete(&model.Item{}).Error; err != nil {
+			return err
+		}
+
+		return record(ctx, tx, model.RevisionDeleted, ids, before)
+	})
+
+	return items, err
+}
+
+// subtasksQuery is a CTE of the IDs of the item's descendants at any depth,
+// it takes the item ID and the owner ID.
+const subtasksQuery = `WITH RECURSIVE subtasks (id) AS (
+	SELECT id FROM items WHERE parent_id = ? AND owner_id = ? AND deleted_at IS NULL
+	UNION ALL
+	SELECT items.id FROM items JOIN subtasks ON items.parent_id = subtasks.id WHERE items.deleted_at IS NULL
+)`
+
+func subtaskIDs(db *gorm.DB, ownerID, id string) (ids []string, err error) {
+	err = db.Raw(subtasksQuery+" SELECT id FROM subtasks", id, ownerID).Scan(&ids).Error
+	return ids, err
+}
+
+func getItem(db *gorm.DB, ownerID, id string) (model.Item, error) {
+	var item model.Item
+	err := db.Where("id = ? AND owner_id = ?", id, ownerID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, todo.ErrNotFound
+	}
+
+	return item, err
+}
+
+// changedOrMissing tells why a conditional statement affected no rows:
+// the item doesn't exist or its version doesn't match.
+func changedOrMissing(db *gorm.DB, ownerID, id string) error {
+	if _, err := getItem(db, ownerID, id); err != nil {
+		return err
+	}
+
+	return todo.ErrVersionMismatch
+}
+
+// keysetCondition selects the rows following the cursor in the ORDER BY used by GetAllItems.
+func keysetCondition(c model.Cursor, sort model.SortField, desc bool) (string, map[string]interface{}) {
+	op := ">"
+	if desc {
+		op = "<"
+	}
+
+	// (created_at, id) breaks the ties of every sort field
+	tail := fmt.Sprintf("(created_at %[1]s @created OR (created_at = @created AND id %[1]s @id))", op)
+	args := map[string]interface{}{"created": c.CreatedAt, "id": c.ID}
+
+	var cond string
+	switch sort {
+	case model.SortCreated:
+		cond = tail
+	case model.SortUpdated:
+		cond = fmt.Sprintf("(updated_at %[1]s @key OR (updated_at = @key AND %[2]s))", op, tail)
+		args["key"] = c.UpdatedAt
+	case model.SortPriority:
+		cond = fmt.Sprintf("(priority %[1]s @key OR (priority = @key AND %[2]s))", op, tail)
+		args["key"] = c.Priority
+	case model.SortDue:
+		// items without due date are last in both directions
+		if c.DueAt == nil {
+			cond = fmt.Sprintf("(due_at IS NULL AND %s)", tail)
+		} else {
+			cond = fmt.Sprintf("(due_at %[1]s @key OR due_at IS NULL OR (due_at = @key AND %[2]s))", op, tail)
+			args["key"] = *c.DueAt
+		}
+	}
+
+	return cond, args
+}
+
+// escapeLike escapes the LIKE wildcards so the search term is matched literally.
+func escapeLike(s string) string {
+	return strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(s)
+}
@@ -0,0 +1,23 @@
+package postgres_test
+
+import (
+	"testing"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/app/repository/sql/sqltest"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/repository/postgres"
+	"github.com/silverspase/todo/internal/modules/todo/repository/repositorytest"
+)
+
+func TestRepository(t *testing.T) {
+	for _, db := range sqltest.Databases() {
+		db := db
+		t.Run(db.Name, func(t *testing.T) {
+			repositorytest.Run(t, func(t *testing.T) todo.Repository {
+				return postgres.NewRepository(db.Open(t), zap.NewNop())
+			})
+		})
+	}
+}
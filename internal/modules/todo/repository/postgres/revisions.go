@@ -0,0 +1,118 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (p postgres) GetRevisions(ctx context.Context, userID, id string) ([]model.Revision, error) {
+	p.logger.Debug("GetRevisions", zap.String("id", id))
+
+	db := p.conn.WithContext(ctx)
+	if err := checkExists(db, userID, id); err != nil {
+		return nil, err
+	}
+
+	revisions := []model.Revision{}
+	err := db.Omit("item").Where("item_id = ?", id).Order("rev").Find(&revisions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return revisions, nil
+}
+
+func (p postgres) GetRevision(ctx context.Context, userID, id string, rev int64) (model.Revision, error) {
+	p.logger.Debug("GetRevision", zap.String("id", id), zap.Int64("rev", rev))
+
+	db := p.conn.WithContext(ctx)
+	if err := checkExists(db, userID, id); err != nil {
+		return model.Revision{}, err
+	}
+
+	var revision model.Revision
+	err := db.Where("item_id = ? AND rev = ?", id, rev).First(&revision).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return revision, todo.ErrRevisionNotFound
+	}
+
+	return revision, err
+}
+
+// checkExists makes sure the user has the item, live or deleted, or it's shared with them.
+func checkExists(db *gorm.DB, userID, id string) error {
+	var n int64
+	err := db.Unscoped().Model(&model.Item{}).Scopes(visible(userID)).Where("id = ?", id).Count(&n).Error
+	if err != nil {
+		return err
+	}
+	if n == 0 {
+		return todo.ErrNotFound
+	}
+
+	return nil
+}
+
+// states loads the items, live or deleted, with their tags by ID.
+func states(db *gorm.DB, ids []string) (map[string]model.Item, error) {
+	var items []model.Item
+	if err := db.Unscoped().Where("id IN ?", ids).Find(&items).Error; err != nil {
+		return nil, err
+	}
+	if err := loadTags(db, items); err != nil {
+		return nil, err
+	}
+
+	res := make(map[string]model.Item, len(items))
+	for _, item := range items {
+		res[item.ID] = item
+	}
+
+	return res, nil
+}
+
+// record writes the revisions of the changed items. before holds their states prior to the change
+// loaded by states, the created items are missing from it.
+func record(ctx context.Context, tx *gorm.DB, action model.RevisionAction, ids []string, before map[string]model.Item) error {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	after, err := states(tx, ids)
+	if err != nil {
+		return err
+	}
+
+	var last []struct {
+		ItemID string
+		Rev    int64
+	}
+	err = tx.Model(&model.Revision{}).Select("item_id, MAX(rev) AS rev").
+		Where("item_id IN ?", ids).Group("item_id").Scan(&last).Error
+	if err != nil {
+		return err
+	}
+	revs := make(map[string]int64, len(last))
+	for _, row := range last {
+		revs[row.ItemID] = row.Rev
+	}
+
+	revisions := make([]model.Revision, 0, len(ids))
+	for _, id := range ids {
+		revision, err := model.NewRevision(action, before[id], after[id])
+		if err != nil {
+			return err
+		}
+		revision.Rev = revs[id] + 1
+		revision.ActorID = todo.Actor(ctx)
+		revisions = append(revisions, revision)
+	}
+
+	return tx.Create(&revisions).Error
+}
@@ -0,0 +1,189 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (p postgres) GetAllTags(ctx context.Context, userID string) ([]model.Tag, error) {
+	p.logger.Debug("GetAllTags")
+
+	var rows []struct {
+		model.Tag
+		Count int64
+	}
+	// soft deleted items don't count, the tags of the other owners are listed while they
+	// carry a shared item
+	user := map[string]interface{}{"user": userID}
+	err := p.conn.WithContext(ctx).Model(&model.Tag{}).
+		Select("tags.*, COUNT(items.id) AS count").
+		Joins("LEFT JOIN item_tags ON item_tags.tag_id = tags.id").
+		Joins("LEFT JOIN items ON items.id = item_tags.item_id AND items.deleted_at IS NULL "+
+			"AND (items.owner_id = @user OR "+sharedItems+")", user).
+		Where("tags.owner_id = @user OR items.id IS NOT NULL", user).
+		Group("tags.id").
+		Order("tags.name, tags.owner_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	tags := make([]model.Tag, 0, len(rows))
+	for _, row := range rows {
+		row.Tag.ItemCount = row.Count
+		tags = append(tags, row.Tag)
+	}
+
+	return tags, nil
+}
+
+func (p postgres) RenameTag(ctx context.Context, ownerID, id, name string) (string, error) {
+	p.logger.Debug("RenameTag", zap.String("id", id))
+
+	resultID := id
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var tag model.Tag
+		err := tx.Where("id = ? AND owner_id = ?", id, ownerID).First(&tag).Error
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return todo.ErrTagNotFound
+		}
+		if err != nil {
+			return err
+		}
+
+		var target model.Tag
+		err = tx.Where("owner_id = ? AND name = ?", ownerID, name).First(&target).Error
+		exists := err == nil
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return err
+		}
+		if exists && target.ID == tag.ID {
+			return nil
+		}
+
+		// the tag names are a part of the items
+		var ids []string
+		err = tx.Model(&model.Item{}).Where("id IN (SELECT item_id FROM item_tags WHERE tag_id = ?)", tag.ID).
+			Pluck("id", &ids).Error
+		if err != nil {
+			return err
+		}
+		before, err := states(tx, ids)
+		if err != nil {
+			return err
+		}
+		if len(ids) > 0 {
+			err = tx.Model(&model.Item{}).Where("id IN ?", ids).UpdateColumn("version", gorm.Expr("version + 1")).Error
+			if err != nil {
+				return err
+			}
+		}
+		if !exists {
+			err = tx.Model(&tag).Update("name", name).Error
+		} else {
+			err = mergeTag(tx, tag, target.ID)
+			resultID = target.ID
+		}
+		if err != nil {
+			return err
+		}
+
+		return record(ctx, tx, model.RevisionUpdated, ids, before)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return resultID, nil
+}
+
+// mergeTag gives the items of the tag the target one instead and deletes it.
+func mergeTag(tx *gorm.DB, tag model.Tag, targetID string) error {
+	err := tx.Exec(`INSERT INTO item_tags (item_id, tag_id)
+		SELECT item_id, ? FROM item_tags
+		WHERE tag_id = ? AND item_id NOT IN (SELECT item_id FROM item_tags WHERE tag_id = ?)`,
+		targetID, tag.ID, targetID).Error
+	if err != nil {
+		return err
+	}
+	if err = tx.Where("tag_id = ?", tag.ID).Delete(&model.ItemTag{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Delete(&tag).Error
+}
+
+// setTags replaces the item's tags, creating the missing ones.
+func setTags(tx *gorm.DB, ownerID, itemID string, names []string) error {
+	if err := tx.Where("item_id = ?", itemID).Delete(&model.ItemTag{}).Error; err != nil {
+		return err
+	}
+
+	for _, name := range names {
+		tag := model.Tag{OwnerID: ownerID, Name: name}
+		if err := tx.Where(&tag).FirstOrCreate(&tag).Error; err != nil {
+			return err
+		}
+		if err := tx.Create(&model.ItemTag{ItemID: itemID, TagID: tag.ID}).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadTags fills the tag names of all items with a single query.
+func loadTags(db *gorm.DB, items []model.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	index := make(map[string]int, len(items))
+	ids := make([]string, 0, len(items))
+	for i := range items {
+		items[i].Tags = []string{}
+		index[items[i].ID] = i
+		ids = append(ids, items[i].ID)
+	}
+
+	var rows []struct {
+		ItemID string
+		Name   string
+	}
+	err := db.Table("item_tags").
+		Select("item_tags.item_id, tags.name").
+		Joins("JOIN tags ON tags.id = item_tags.tag_id").
+		Where("item_tags.item_id IN ?", ids).
+		Order("tags.name").
+		Scan(&rows).Error
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		i := index[row.ItemID]
+		items[i].Tags = append(items[i].Tags, row.Name)
+	}
+
+	return nil
+}
+
+// taggedItems is a subquery of the IDs of items carrying any or all of the tags.
+func taggedItems(db *gorm.DB, names []string, mode model.TagMode) *gorm.DB {
+	sub := db.Table("item_tags").
+		Select("item_tags.item_id").
+		Joins("JOIN tags ON tags.id = item_tags.tag_id").
+		Where("tags.name IN ?", names)
+
+	if mode == model.TagModeAll {
+		sub = sub.Group("item_tags.item_id").Having("COUNT(DISTINCT tags.name) = ?", len(names))
+	}
+
+	return sub
+}
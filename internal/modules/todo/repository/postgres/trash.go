@@ -0,0 +1,185 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// trashedSubtasksQuery is a CTE of the IDs of a deleted item and its deleted descendants,
+// it takes the item ID.
+const trashedSubtasksQuery = `WITH RECURSIVE trashed (id) AS (
+	SELECT id FROM items WHERE id = ?
+	UNION ALL
+	SELECT items.id FROM items JOIN trashed ON items.parent_id = trashed.id WHERE items.deleted_at IS NOT NULL
+)`
+
+// deletedWithQuery is a CTE of the IDs of a deleted item and the descendants deleted together with it,
+// it takes the item ID and its deletion time.
+const deletedWithQuery = `WITH RECURSIVE deleted_with (id) AS (
+	SELECT id FROM items WHERE id = ?
+	UNION ALL
+	SELECT items.id FROM items JOIN deleted_with ON items.parent_id = deleted_with.id WHERE items.deleted_at = ?
+)`
+
+func (p postgres) GetTrash(ctx context.Context, ownerID string) ([]model.Item, error) {
+	p.logger.Debug("GetTrash")
+
+	db := p.conn.WithContext(ctx)
+	var items []model.Item
+	// subtasks deleted with the parent go along with it
+	err := db.Unscoped().
+		Where("owner_id = ? AND deleted_at IS NOT NULL", ownerID).
+		Where(`NOT EXISTS (SELECT 1 FROM items parent
+			WHERE parent.id = items.parent_id AND parent.deleted_at = items.deleted_at)`).
+		Order("deleted_at DESC, id").
+		Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	if err = loadTags(db, items); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+func (p postgres) GetTrashedItem(ctx context.Context, ownerID, id string) (model.Item, error) {
+	p.logger.Debug("GetTrashedItem", zap.String("id", id))
+
+	db := p.conn.WithContext(ctx)
+	item, err := getTrashedItem(db, ownerID, id)
+	if err != nil {
+		return item, err
+	}
+
+	items := []model.Item{item}
+	if err = loadTags(db, items); err != nil {
+		return item, err
+	}
+
+	return items[0], nil
+}
+
+func (p postgres) RestoreItem(ctx context.Context, ownerID, id string) (string, error) {
+	p.logger.Info("RestoreItem", zap.String("id", id))
+
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		item, err := getTrashedItem(tx, ownerID, id)
+		if err != nil {
+			return err
+		}
+
+		var ids []string
+		err = tx.Raw(deletedWithQuery+" SELECT id FROM deleted_with", id, item.DeletedAt.Time).Scan(&ids).Error
+		if err != nil {
+			return err
+		}
+
+		before, err := states(tx, ids)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Unscoped().Model(&model.Item{}).Where("id IN ?", ids).UpdateColumns(map[string]interface{}{
+			"deleted_at": nil,
+			"updated_at": time.Now(),
+			"version":    gorm.Expr("version + 1"),
+		}).Error
+		if err != nil {
+			return err
+		}
+
+		return record(ctx, tx, model.RevisionRestored, ids, before)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (p postgres) PurgeItem(ctx context.Context, ownerID, id string) (string, error) {
+	p.logger.Info("PurgeItem", zap.String("id", id))
+
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if _, err := getTrashedItem(tx, ownerID, id); err != nil {
+			return err
+		}
+
+		var ids []string
+		if err := tx.Raw(trashedSubtasksQuery+" SELECT id FROM trashed", id).Scan(&ids).Error; err != nil {
+			return err
+		}
+
+		return purge(tx, ids)
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (p postgres) PurgeTrash(ctx context.Context, deletedBefore time.Time) (int64, error) {
+	p.logger.Debug("PurgeTrash", zap.Time("deleted_before", deletedBefore))
+
+	var n int64
+	err := p.conn.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		// subqueries, as the expired items may be too many for the bind parameters
+		expired := tx.Unscoped().Model(&model.Item{}).Select("id").Where("deleted_at < ?", deletedBefore)
+		if err := tx.Where("item_id IN (?)", expired).Delete(&model.ItemTag{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("item_id IN (?)", expired).Delete(&model.Revision{}).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("item_id IN (?)", expired).Delete(&model.Grant{}).Error; err != nil {
+			return err
+		}
+
+		res := tx.Unscoped().Where("deleted_at < ?", deletedBefore).Delete(&model.Item{})
+		n = res.RowsAffected
+		return res.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return n, nil
+}
+
+// purge permanently deletes the items with their tags, revisions and grants.
+func purge(tx *gorm.DB, ids []string) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	if err := tx.Where("item_id IN ?", ids).Delete(&model.ItemTag{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("item_id IN ?", ids).Delete(&model.Revision{}).Error; err != nil {
+		return err
+	}
+	if err := tx.Where("item_id IN ?", ids).Delete(&model.Grant{}).Error; err != nil {
+		return err
+	}
+
+	return tx.Unscoped().Where("id IN ?", ids).Delete(&model.Item{}).Error
+}
+
+func getTrashedItem(db *gorm.DB, ownerID, id string) (model.Item, error) {
+	var item model.Item
+	err := db.Unscoped().Where("id = ? AND owner_id = ? AND deleted_at IS NOT NULL", id, ownerID).First(&item).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return item, todo.ErrNotFound
+	}
+
+	return item, err
+}
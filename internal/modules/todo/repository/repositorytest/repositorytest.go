@@ -0,0 +1,1185 @@
+// Package repositorytest is the conformance suite of the todo.Repository implementations.
+package repositorytest
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	authmodel "github.com/silverspase/todo/internal/modules/auth/model"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// Run checks that the repositories returned by newRepository behave like todo.Repository
+// documents. Every subtest gets a new repository.
+func Run(t *testing.T, newRepository func(t *testing.T) todo.Repository) {
+	tests := []struct {
+		name string
+		test func(t *testing.T, repo todo.Repository)
+	}{
+		{"CreateAndGet", testCreateAndGet},
+		{"OwnerScope", testOwnerScope},
+		{"Update", testUpdate},
+		{"Delete", testDelete},
+		{"Versions", testVersions},
+		{"ConcurrentUpdates", testConcurrentUpdates},
+		{"Subtasks", testSubtasks},
+		{"DeleteListItems", testDeleteListItems},
+		{"Trash", testTrash},
+		{"Restore", testRestore},
+		{"Purge", testPurge},
+		{"PurgeTrash", testPurgeTrash},
+		{"Revisions", testRevisions},
+		{"Grants", testGrants},
+		{"SharedItems", testSharedItems},
+		{"Filters", testFilters},
+		{"Search", testSearch},
+		{"Order", testOrder},
+		{"Pages", testPages},
+		{"Tags", testTags},
+		{"SharedTags", testSharedTags},
+		{"RenameTag", testRenameTag},
+		{"MergeTag", testMergeTag},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			tt.test(t, newRepository(t))
+		})
+	}
+}
+
+var ctx = context.Background()
+
+func day(d int) *time.Time {
+	date := time.Date(2030, time.January, d, 9, 0, 0, 0, time.UTC)
+	return &date
+}
+
+func newOwner() string {
+	return uuid.New().String()
+}
+
+func create(t *testing.T, repo todo.Repository, item model.Item) model.Item {
+	t.Helper()
+
+	if item.ListID == "" {
+		item.ListID = "list"
+	}
+	id, err := repo.CreateItem(ctx, item)
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	if id == "" {
+		t.Fatal("CreateItem returned an empty ID")
+	}
+
+	return get(t, repo, item.OwnerID, id)
+}
+
+func get(t *testing.T, repo todo.Repository, ownerID, id string) model.Item {
+	t.Helper()
+
+	item, err := repo.GetItem(ctx, ownerID, id)
+	if err != nil {
+		t.Fatalf("GetItem(%s): %v", id, err)
+	}
+
+	return item
+}
+
+func list(t *testing.T, repo todo.Repository, query model.Query) []model.Item {
+	t.Helper()
+
+	if query.Sort == "" {
+		query.Sort = model.SortCreated
+	}
+	if query.TagMode == "" {
+		query.TagMode = model.TagModeAny
+	}
+	if query.PageSize == 0 {
+		query.PageSize = 100
+	}
+	items, err := repo.GetAllItems(ctx, query)
+	if err != nil {
+		t.Fatalf("GetAllItems: %v", err)
+	}
+
+	return items
+}
+
+func titles(items []model.Item) []string {
+	res := make([]string, 0, len(items))
+	for _, item := range items {
+		res = append(res, item.Title)
+	}
+
+	return res
+}
+
+func sorted(s []string) []string {
+	s = append([]string{}, s...)
+	sort.Strings(s)
+	return s
+}
+
+func equalTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(*b)
+}
+
+func expectTitles(t *testing.T, items []model.Item, want ...string) {
+	t.Helper()
+
+	if got := titles(items); !reflect.DeepEqual(sorted(got), sorted(want)) {
+		t.Errorf("got items %q, want %q", got, want)
+	}
+}
+
+func testCreateAndGet(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	before := time.Now().Add(-time.Second)
+	item := create(t, repo, model.Item{
+		OwnerID:     owner,
+		ListID:      "home",
+		Title:       "Buy milk",
+		Description: "2 liters",
+		DueAt:       day(1),
+		Priority:    model.PriorityHigh,
+		Tags:        []string{"shop", "errand"},
+		Recurrence:  "FREQ=WEEKLY",
+		SeriesStart: day(1),
+	})
+
+	if item.OwnerID != owner || item.ListID != "home" || item.Title != "Buy milk" || item.Description != "2 liters" {
+		t.Errorf("fields not stored: %+v", item)
+	}
+	if !equalTime(item.DueAt, day(1)) || !equalTime(item.SeriesStart, day(1)) {
+		t.Errorf("dates not stored: due %v, series start %v", item.DueAt, item.SeriesStart)
+	}
+	if item.Priority != model.PriorityHigh || item.Recurrence != "FREQ=WEEKLY" || item.Completed {
+		t.Errorf("fields not stored: %+v", item)
+	}
+	if !reflect.DeepEqual(item.Tags, []string{"errand", "shop"}) {
+		t.Errorf("got tags %q, want them sorted by name", item.Tags)
+	}
+	if item.CreatedAt.Before(before) || item.UpdatedAt.Before(before) {
+		t.Errorf("timestamps not set: created %v, updated %v", item.CreatedAt, item.UpdatedAt)
+	}
+
+	other := create(t, repo, model.Item{OwnerID: owner, Title: "Other"})
+	if other.ID == item.ID {
+		t.Error("items got the same ID")
+	}
+	if other.Tags == nil || len(other.Tags) != 0 {
+		t.Errorf("got tags %#v, want an empty slice", other.Tags)
+	}
+
+	if _, err := repo.GetItem(ctx, owner, uuid.New().String()); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem of an unknown ID: got %v, want ErrNotFound", err)
+	}
+}
+
+func testOwnerScope(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	item := create(t, repo, model.Item{OwnerID: owner, Title: "Mine"})
+
+	if _, err := repo.GetItem(ctx, stranger, item.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem: got %v, want ErrNotFound", err)
+	}
+	item.OwnerID = stranger
+	if _, err := repo.UpdateItem(ctx, item); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("UpdateItem: got %v, want ErrNotFound", err)
+	}
+	if _, err := repo.DeleteItem(ctx, stranger, item.ID, 0); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("DeleteItem: got %v, want ErrNotFound", err)
+	}
+	if _, err := repo.GetSubtasks(ctx, stranger, item.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetSubtasks: got %v, want ErrNotFound", err)
+	}
+	if items := list(t, repo, model.Query{OwnerID: stranger}); len(items) != 0 {
+		t.Errorf("GetAllItems returned the items of another owner: %q", titles(items))
+	}
+
+	if got := get(t, repo, owner, item.ID); got.Title != "Mine" {
+		t.Errorf("item changed by another owner: %+v", got)
+	}
+}
+
+func testUpdate(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	parent := create(t, repo, model.Item{OwnerID: owner, Title: "Parent"})
+	item := create(t, repo, model.Item{OwnerID: owner, Title: "Draft", Tags: []string{"a", "b"}, DueAt: day(1)})
+	time.Sleep(10 * time.Millisecond)
+
+	completedAt := time.Date(2030, time.January, 2, 10, 30, 0, 0, time.UTC)
+	update := item
+	update.ListID = "work"
+	update.ParentID = parent.ID
+	update.Title = "Final"
+	update.Description = "done right"
+	update.Completed = true
+	update.CompletedAt = &completedAt
+	update.DueAt = nil
+	update.Priority = model.PriorityLow
+	update.Tags = []string{"c", "b"}
+	update.Recurrence = "FREQ=DAILY"
+	update.SeriesStart = day(3)
+
+	id, err := repo.UpdateItem(ctx, update)
+	if err != nil || id != item.ID {
+		t.Fatalf("UpdateItem: got %q, %v", id, err)
+	}
+
+	got := get(t, repo, owner, item.ID)
+	if got.ListID != "work" || got.ParentID != parent.ID || got.Title != "Final" || got.Description != "done right" {
+		t.Errorf("fields not updated: %+v", got)
+	}
+	if !got.Completed || !equalTime(got.CompletedAt, &completedAt) || got.DueAt != nil {
+		t.Errorf("completion not updated: %+v", got)
+	}
+	if got.Priority != model.PriorityLow || got.Recurrence != "FREQ=DAILY" || !equalTime(got.SeriesStart, day(3)) {
+		t.Errorf("fields not updated: %+v", got)
+	}
+	if !reflect.DeepEqual(got.Tags, []string{"b", "c"}) {
+		t.Errorf("got tags %q, want [b c]", got.Tags)
+	}
+	if !got.CreatedAt.Equal(item.CreatedAt) {
+		t.Errorf("created_at changed from %v to %v", item.CreatedAt, got.CreatedAt)
+	}
+	if !got.UpdatedAt.After(item.UpdatedAt) {
+		t.Errorf("updated_at not bumped: %v, was %v", got.UpdatedAt, item.UpdatedAt)
+	}
+
+	update.ID = uuid.New().String()
+	if _, err = repo.UpdateItem(ctx, update); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("UpdateItem of an unknown ID: got %v, want ErrNotFound", err)
+	}
+}
+
+func testDelete(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	parent := create(t, repo, model.Item{OwnerID: owner, Title: "Parent", Tags: []string{"a"}})
+	child := create(t, repo, model.Item{OwnerID: owner, Title: "Child", ParentID: parent.ID})
+	grandchild := create(t, repo, model.Item{OwnerID: owner, Title: "Grandchild", ParentID: child.ID, Tags: []string{"a"}})
+	create(t, repo, model.Item{OwnerID: owner, Title: "Sibling"})
+
+	id, err := repo.DeleteItem(ctx, owner, parent.ID, 0)
+	if err != nil || id != parent.ID {
+		t.Fatalf("DeleteItem: got %q, %v", id, err)
+	}
+
+	for _, deleted := range []model.Item{parent, child, grandchild} {
+		if _, err = repo.GetItem(ctx, owner, deleted.ID); !errors.Is(err, todo.ErrNotFound) {
+			t.Errorf("GetItem of deleted %s: got %v, want ErrNotFound", deleted.Title, err)
+		}
+	}
+	expectTitles(t, list(t, repo, model.Query{OwnerID: owner}), "Sibling")
+
+	tags, err := repo.GetAllTags(ctx, owner)
+	if err != nil {
+		t.Fatalf("GetAllTags: %v", err)
+	}
+	for _, tag := range tags {
+		if tag.ItemCount != 0 {
+			t.Errorf("tag %s counts %d deleted items", tag.Name, tag.ItemCount)
+		}
+	}
+
+	if _, err = repo.DeleteItem(ctx, owner, parent.ID, 0); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("DeleteItem of a deleted item: got %v, want ErrNotFound", err)
+	}
+}
+
+func testVersions(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	item := create(t, repo, model.Item{OwnerID: owner, Title: "Draft", Tags: []string{"a"}})
+	if item.Version != 1 {
+		t.Errorf("created with version %d, want 1", item.Version)
+	}
+
+	update := item
+	update.Title = "Final"
+	if _, err := repo.UpdateItem(ctx, update); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if got := get(t, repo, owner, item.ID); got.Version != 2 {
+		t.Errorf("got version %d after the update, want 2", got.Version)
+	}
+
+	// the update of a client which has read the item before
+	update.Title = "Lost"
+	if _, err := repo.UpdateItem(ctx, update); !errors.Is(err, todo.ErrVersionMismatch) {
+		t.Errorf("UpdateItem of a stale version: got %v, want ErrVersionMismatch", err)
+	}
+	if got := get(t, repo, owner, item.ID); got.Title != "Final" || got.Version != 2 {
+		t.Errorf("stale update applied: %+v", got)
+	}
+
+	update.Version = 0
+	update.Title = "Forced"
+	if _, err := repo.UpdateItem(ctx, update); err != nil {
+		t.Fatalf("UpdateItem without a version: %v", err)
+	}
+	if got := get(t, repo, owner, item.ID); got.Title != "Forced" || got.Version != 3 {
+		t.Errorf("got %q of version %d, want Forced of version 3", got.Title, got.Version)
+	}
+
+	// the tag names are a part of the item
+	if _, err := repo.RenameTag(ctx, owner, tagsOf(t, repo, owner)["a"].ID, "b"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if got := get(t, repo, owner, item.ID); got.Version != 4 {
+		t.Errorf("got version %d after the tag rename, want 4", got.Version)
+	}
+
+	if _, err := repo.DeleteItem(ctx, owner, item.ID, 3); !errors.Is(err, todo.ErrVersionMismatch) {
+		t.Errorf("DeleteItem of a stale version: got %v, want ErrVersionMismatch", err)
+	}
+	get(t, repo, owner, item.ID)
+	if _, err := repo.DeleteItem(ctx, owner, item.ID, 4); err != nil {
+		t.Fatalf("DeleteItem of the current version: %v", err)
+	}
+	if _, err := repo.DeleteItem(ctx, owner, item.ID, 4); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("DeleteItem of a deleted item: got %v, want ErrNotFound", err)
+	}
+}
+
+func testConcurrentUpdates(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	item := create(t, repo, model.Item{OwnerID: owner, Title: "Shared"})
+
+	const writers = 8
+	errs := make(chan error, writers)
+	for i := 0; i < writers; i++ {
+		update := item
+		update.Title = fmt.Sprintf("Writer %d", i)
+		go func() {
+			_, err := repo.UpdateItem(ctx, update)
+			errs <- err
+		}()
+	}
+
+	var updated int
+	for i := 0; i < writers; i++ {
+		err := <-errs
+		switch {
+		case err == nil:
+			updated++
+		case !errors.Is(err, todo.ErrVersionMismatch):
+			t.Errorf("UpdateItem: got %v, want nil or ErrVersionMismatch", err)
+		}
+	}
+	if updated != 1 {
+		t.Errorf("%d writers updated version 1, want exactly one", updated)
+	}
+	if got := get(t, repo, owner, item.ID); got.Version != 2 {
+		t.Errorf("got version %d, want 2", got.Version)
+	}
+}
+
+func testSubtasks(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	root := create(t, repo, model.Item{OwnerID: owner, Title: "Root"})
+	a := create(t, repo, model.Item{OwnerID: owner, Title: "A", ParentID: root.ID, Tags: []string{"x"}})
+	create(t, repo, model.Item{OwnerID: owner, Title: "A1", ParentID: a.ID})
+	create(t, repo, model.Item{OwnerID: owner, Title: "B", ParentID: root.ID})
+	create(t, repo, model.Item{OwnerID: owner, Title: "Unrelated"})
+
+	subtasks, err := repo.GetSubtasks(ctx, owner, root.ID)
+	if err != nil {
+		t.Fatalf("GetSubtasks: %v", err)
+	}
+	if got := titles(subtasks); !reflect.DeepEqual(got, []string{"A", "A1", "B"}) {
+		t.Errorf("got subtasks %q, want [A A1 B] in creation order", got)
+	}
+	if len(subtasks) > 0 && !reflect.DeepEqual(subtasks[0].Tags, []string{"x"}) {
+		t.Errorf("subtask tags not loaded: %q", subtasks[0].Tags)
+	}
+
+	leaf, err := repo.GetSubtasks(ctx, owner, subtasks[len(subtasks)-1].ID)
+	if err != nil || len(leaf) != 0 {
+		t.Errorf("GetSubtasks of a leaf: got %q, %v", titles(leaf), err)
+	}
+	if _, err = repo.GetSubtasks(ctx, owner, uuid.New().String()); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetSubtasks of an unknown ID: got %v, want ErrNotFound", err)
+	}
+}
+
+func testDeleteListItems(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	create(t, repo, model.Item{OwnerID: owner, ListID: "home", Title: "Home 1"})
+	create(t, repo, model.Item{OwnerID: owner, ListID: "home", Title: "Home 2"})
+	create(t, repo, model.Item{OwnerID: owner, ListID: "work", Title: "Work"})
+	create(t, repo, model.Item{OwnerID: stranger, ListID: "home", Title: "Stranger's home"})
+
+	deleted, err := repo.DeleteListItems(ctx, owner, "home")
+	if err != nil {
+		t.Fatalf("DeleteListItems: %v", err)
+	}
+
+	expectTitles(t, deleted, "Home 1", "Home 2")
+	expectTitles(t, list(t, repo, model.Query{OwnerID: owner}), "Work")
+	expectTitles(t, list(t, repo, model.Query{OwnerID: stranger}), "Stranger's home")
+}
+
+func trash(t *testing.T, repo todo.Repository, owner string) []model.Item {
+	t.Helper()
+
+	items, err := repo.GetTrash(ctx, owner)
+	if err != nil {
+		t.Fatalf("GetTrash: %v", err)
+	}
+	for _, item := range items {
+		if !item.DeletedAt.Valid {
+			t.Errorf("%s: DeletedAt is not set", item.Title)
+		}
+	}
+
+	return items
+}
+
+func remove(t *testing.T, repo todo.Repository, item model.Item) {
+	t.Helper()
+
+	if _, err := repo.DeleteItem(ctx, item.OwnerID, item.ID, 0); err != nil {
+		t.Fatalf("DeleteItem(%s): %v", item.Title, err)
+	}
+}
+
+func testTrash(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	parent := create(t, repo, model.Item{OwnerID: owner, Title: "Parent", Tags: []string{"a"}})
+	create(t, repo, model.Item{OwnerID: owner, Title: "Child", ParentID: parent.ID})
+	early := create(t, repo, model.Item{OwnerID: owner, Title: "Early child", ParentID: parent.ID})
+	create(t, repo, model.Item{OwnerID: owner, Title: "Kept"})
+	create(t, repo, model.Item{OwnerID: stranger, Title: "Stranger's"})
+
+	if got := trash(t, repo, owner); len(got) != 0 {
+		t.Errorf("got trash %q before any deletion", titles(got))
+	}
+
+	remove(t, repo, early)
+	time.Sleep(10 * time.Millisecond)
+	remove(t, repo, parent)
+
+	// the child went with the parent, the early child was deleted on its own
+	items := trash(t, repo, owner)
+	if got := titles(items); !reflect.DeepEqual(got, []string{"Parent", "Early child"}) {
+		t.Fatalf("got trash %q, want [Parent Early child], the latest deleted first", got)
+	}
+	if !reflect.DeepEqual(items[0].Tags, []string{"a"}) {
+		t.Errorf("trashed item tags not loaded: %q", items[0].Tags)
+	}
+	if got := trash(t, repo, stranger); len(got) != 0 {
+		t.Errorf("got the owner's trash %q for another user", titles(got))
+	}
+
+	item, err := repo.GetTrashedItem(ctx, owner, parent.ID)
+	if err != nil || item.Title != "Parent" || !item.DeletedAt.Valid {
+		t.Errorf("GetTrashedItem: got %+v, %v", item, err)
+	}
+	if _, err = repo.GetTrashedItem(ctx, stranger, parent.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetTrashedItem by another owner: got %v, want ErrNotFound", err)
+	}
+	kept := list(t, repo, model.Query{OwnerID: owner})
+	if _, err = repo.GetTrashedItem(ctx, owner, kept[0].ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetTrashedItem of a live item: got %v, want ErrNotFound", err)
+	}
+}
+
+func testRestore(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	parent := create(t, repo, model.Item{OwnerID: owner, Title: "Parent", Tags: []string{"a"}})
+	child := create(t, repo, model.Item{OwnerID: owner, Title: "Child", ParentID: parent.ID})
+	early := create(t, repo, model.Item{OwnerID: owner, Title: "Early child", ParentID: parent.ID})
+
+	remove(t, repo, early)
+	time.Sleep(10 * time.Millisecond)
+	remove(t, repo, parent)
+
+	if _, err := repo.RestoreItem(ctx, stranger, parent.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("RestoreItem by another owner: got %v, want ErrNotFound", err)
+	}
+
+	id, err := repo.RestoreItem(ctx, owner, parent.ID)
+	if err != nil || id != parent.ID {
+		t.Fatalf("RestoreItem: got %q, %v", id, err)
+	}
+	restored := get(t, repo, owner, parent.ID)
+	if restored.Version != parent.Version+1 || !reflect.DeepEqual(restored.Tags, []string{"a"}) {
+		t.Errorf("restored %+v, want version %d with tags [a]", restored, parent.Version+1)
+	}
+	if got := get(t, repo, owner, child.ID); got.Version != child.Version+1 {
+		t.Errorf("restored child has version %d, want %d", got.Version, child.Version+1)
+	}
+	if _, err = repo.GetItem(ctx, owner, early.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem of the early child: got %v, want it kept in the trash", err)
+	}
+	if got := titles(trash(t, repo, owner)); !reflect.DeepEqual(got, []string{"Early child"}) {
+		t.Errorf("got trash %q, want [Early child]", got)
+	}
+	if tags := tagsOf(t, repo, owner); tags["a"].ItemCount != 1 {
+		t.Errorf("got %d items tagged a, want the restored one", tags["a"].ItemCount)
+	}
+
+	if _, err = repo.RestoreItem(ctx, owner, parent.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("RestoreItem of a live item: got %v, want ErrNotFound", err)
+	}
+}
+
+func testPurge(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	parent := create(t, repo, model.Item{OwnerID: owner, Title: "Parent", Tags: []string{"a"}})
+	child := create(t, repo, model.Item{OwnerID: owner, Title: "Child", ParentID: parent.ID})
+	early := create(t, repo, model.Item{OwnerID: owner, Title: "Early child", ParentID: parent.ID})
+	other := create(t, repo, model.Item{OwnerID: owner, Title: "Other", Tags: []string{"a"}})
+
+	if _, err := repo.PurgeItem(ctx, owner, parent.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("PurgeItem of a live item: got %v, want ErrNotFound", err)
+	}
+
+	remove(t, repo, early)
+	remove(t, repo, parent)
+	remove(t, repo, other)
+	if _, err := repo.PurgeItem(ctx, stranger, parent.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("PurgeItem by another owner: got %v, want ErrNotFound", err)
+	}
+
+	id, err := repo.PurgeItem(ctx, owner, parent.ID)
+	if err != nil || id != parent.ID {
+		t.Fatalf("PurgeItem: got %q, %v", id, err)
+	}
+	// every deleted subtask is purged with the parent
+	for _, purged := range []model.Item{parent, child, early} {
+		if _, err = repo.GetTrashedItem(ctx, owner, purged.ID); !errors.Is(err, todo.ErrNotFound) {
+			t.Errorf("GetTrashedItem of purged %s: got %v, want ErrNotFound", purged.Title, err)
+		}
+	}
+	if got := titles(trash(t, repo, owner)); !reflect.DeepEqual(got, []string{"Other"}) {
+		t.Errorf("got trash %q, want [Other]", got)
+	}
+	if _, err = repo.RestoreItem(ctx, owner, parent.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("RestoreItem of a purged item: got %v, want ErrNotFound", err)
+	}
+
+	// the tags of the purged items are gone, the others are restored with theirs
+	if _, err = repo.RestoreItem(ctx, owner, other.ID); err != nil {
+		t.Fatalf("RestoreItem: %v", err)
+	}
+	if tags := tagsOf(t, repo, owner); tags["a"].ItemCount != 1 {
+		t.Errorf("got %d items tagged a, want 1", tags["a"].ItemCount)
+	}
+}
+
+func testPurgeTrash(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	old := create(t, repo, model.Item{OwnerID: owner, Title: "Old", Tags: []string{"a"}})
+	oldChild := create(t, repo, model.Item{OwnerID: owner, Title: "Old child", ParentID: old.ID})
+	strangers := create(t, repo, model.Item{OwnerID: stranger, Title: "Stranger's old"})
+	recent := create(t, repo, model.Item{OwnerID: owner, Title: "Recent"})
+	create(t, repo, model.Item{OwnerID: owner, Title: "Live"})
+
+	remove(t, repo, old)
+	remove(t, repo, strangers)
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	remove(t, repo, recent)
+
+	n, err := repo.PurgeTrash(ctx, cutoff)
+	if err != nil {
+		t.Fatalf("PurgeTrash: %v", err)
+	}
+	if n != 3 {
+		t.Errorf("PurgeTrash purged %d items, want the 3 deleted before the cutoff", n)
+	}
+	if got := titles(trash(t, repo, owner)); !reflect.DeepEqual(got, []string{"Recent"}) {
+		t.Errorf("got trash %q, want [Recent]", got)
+	}
+	if got := trash(t, repo, stranger); len(got) != 0 {
+		t.Errorf("got trash %q, want the stranger's purged too", titles(got))
+	}
+	if _, err = repo.GetTrashedItem(ctx, owner, oldChild.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetTrashedItem of the purged child: got %v, want ErrNotFound", err)
+	}
+	expectTitles(t, list(t, repo, model.Query{OwnerID: owner}), "Live")
+}
+
+func revisions(t *testing.T, repo todo.Repository, item model.Item) []model.Revision {
+	t.Helper()
+
+	res, err := repo.GetRevisions(ctx, item.OwnerID, item.ID)
+	if err != nil {
+		t.Fatalf("GetRevisions(%s): %v", item.Title, err)
+	}
+
+	return res
+}
+
+func changedFields(changes model.Changes) []string {
+	res := []string{}
+	for _, change := range changes {
+		res = append(res, change.Field)
+	}
+
+	return res
+}
+
+func testRevisions(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	actor := auth.NewContext(ctx, authmodel.User{ID: owner})
+
+	id, err := repo.CreateItem(actor, model.Item{OwnerID: owner, ListID: "list", Title: "Draft", Tags: []string{"a"}})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	item := get(t, repo, owner, id)
+	child := create(t, repo, model.Item{OwnerID: owner, Title: "Child", ParentID: item.ID})
+
+	updated := item
+	updated.Title = "Final"
+	updated.DueAt = day(3)
+	if _, err = repo.UpdateItem(actor, updated); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if _, err = repo.RenameTag(actor, owner, tagsOf(t, repo, owner)["a"].ID, "b"); err != nil {
+		t.Fatalf("RenameTag: %v", err)
+	}
+	if _, err = repo.DeleteItem(actor, owner, item.ID, 0); err != nil {
+		t.Fatalf("DeleteItem: %v", err)
+	}
+	if _, err = repo.RestoreItem(actor, owner, item.ID); err != nil {
+		t.Fatalf("RestoreItem: %v", err)
+	}
+
+	want := []struct {
+		action model.RevisionAction
+		fields []string
+	}{
+		{model.RevisionCreated, []string{"list_id", "tags", "title"}},
+		{model.RevisionUpdated, []string{"due_at", "title"}},
+		{model.RevisionUpdated, []string{"tags"}},
+		{model.RevisionDeleted, []string{}},
+		{model.RevisionRestored, []string{}},
+	}
+	got := revisions(t, repo, item)
+	if len(got) != len(want) {
+		t.Fatalf("got %d revisions, want %d", len(got), len(want))
+	}
+	for n, revision := range got {
+		fields := changedFields(revision.Changes)
+		if revision.Rev != int64(n+1) || revision.Action != want[n].action || !reflect.DeepEqual(fields, want[n].fields) {
+			t.Errorf("revision %d: got rev %d %s of %q, want %s of %q",
+				n+1, revision.Rev, revision.Action, fields, want[n].action, want[n].fields)
+		}
+		if revision.ActorID != owner || revision.Item != nil {
+			t.Errorf("revision %d: got actor %q with the item %s, want %q without it",
+				n+1, revision.ActorID, revision.Item, owner)
+		}
+	}
+	if change := got[1].Changes[1]; string(change.From) != `"Draft"` || string(change.To) != `"Final"` {
+		t.Errorf("got title change from %s to %s, want from \"Draft\" to \"Final\"", change.From, change.To)
+	}
+
+	// the subtask deleted and restored along with its parent gets its revisions, with no actor for ctx
+	var actions []model.RevisionAction
+	for _, revision := range revisions(t, repo, child) {
+		actions = append(actions, revision.Action)
+	}
+	wantActions := []model.RevisionAction{model.RevisionCreated, model.RevisionDeleted, model.RevisionRestored}
+	if !reflect.DeepEqual(actions, wantActions) {
+		t.Errorf("got child revisions %q, want %q", actions, wantActions)
+	}
+
+	revision, err := repo.GetRevision(ctx, owner, item.ID, 2)
+	if err != nil {
+		t.Fatalf("GetRevision: %v", err)
+	}
+	var snapshot model.Item
+	if err = json.Unmarshal(revision.Item, &snapshot); err != nil {
+		t.Fatalf("unmarshal the item of the revision: %v", err)
+	}
+	if snapshot.Title != "Final" || !equalTime(snapshot.DueAt, day(3)) || !reflect.DeepEqual(snapshot.Tags, []string{"a"}) {
+		t.Errorf("got revision 2 of %+v, want the item as updated", snapshot)
+	}
+
+	if _, err = repo.GetRevision(ctx, owner, item.ID, 9); !errors.Is(err, todo.ErrRevisionNotFound) {
+		t.Errorf("GetRevision of an unknown rev: got %v, want ErrRevisionNotFound", err)
+	}
+	if _, err = repo.GetRevision(ctx, stranger, item.ID, 1); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetRevision by another owner: got %v, want ErrNotFound", err)
+	}
+	if _, err = repo.GetRevisions(ctx, stranger, item.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetRevisions by another owner: got %v, want ErrNotFound", err)
+	}
+
+	// the history of a deleted item is kept until it's purged
+	remove(t, repo, item)
+	if got = revisions(t, repo, item); len(got) != len(want)+1 {
+		t.Errorf("got %d revisions of the deleted item, want %d", len(got), len(want)+1)
+	}
+	if _, err = repo.PurgeItem(ctx, owner, item.ID); err != nil {
+		t.Fatalf("PurgeItem: %v", err)
+	}
+	if _, err = repo.GetRevisions(ctx, owner, item.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetRevisions of a purged item: got %v, want ErrNotFound", err)
+	}
+}
+
+func grant(t *testing.T, repo todo.Repository, g model.Grant) {
+	t.Helper()
+
+	if err := repo.SaveGrant(ctx, g); err != nil {
+		t.Fatalf("SaveGrant: %v", err)
+	}
+}
+
+func access(t *testing.T, repo todo.Repository, userID string, item model.Item) model.Access {
+	t.Helper()
+
+	res, err := repo.GetAccess(ctx, userID, item)
+	if err != nil {
+		t.Fatalf("GetAccess: %v", err)
+	}
+
+	return res
+}
+
+func testGrants(t *testing.T, repo todo.Repository) {
+	owner, ann, bob := newOwner(), newOwner(), newOwner()
+	work := uuid.New().String()
+	item := create(t, repo, model.Item{OwnerID: owner, ListID: work, Title: "Report"})
+
+	if got := access(t, repo, ann, item); got != "" {
+		t.Errorf("got access %q before sharing, want none", got)
+	}
+
+	grant(t, repo, model.Grant{ItemID: item.ID, UserID: ann, Access: model.AccessView})
+	grant(t, repo, model.Grant{ListID: work, UserID: bob, Access: model.AccessView})
+	if got := access(t, repo, ann, item); got != model.AccessView {
+		t.Errorf("got access %q, want view", got)
+	}
+	// the wider access of the item and of its list applies
+	grant(t, repo, model.Grant{ListID: work, UserID: ann, Access: model.AccessEdit})
+	if got := access(t, repo, ann, item); got != model.AccessEdit {
+		t.Errorf("got access %q by the list, want edit", got)
+	}
+	// granting again changes the access
+	grant(t, repo, model.Grant{ListID: work, UserID: bob, Access: model.AccessEdit})
+	if got := access(t, repo, bob, item); got != model.AccessEdit {
+		t.Errorf("got access %q after the change, want edit", got)
+	}
+
+	grants, err := repo.GetGrants(ctx, item.ID, work)
+	if err != nil {
+		t.Fatalf("GetGrants: %v", err)
+	}
+	var got []string
+	for _, g := range grants {
+		got = append(got, fmt.Sprintf("%v/%v/%v", g.ItemID != "", g.UserID == ann, g.Access))
+	}
+	want := []string{"true/true/view", "false/false/edit", "false/true/edit"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got grants (item/ann/access) %q, want %q", got, want)
+	}
+	if grants, err = repo.GetGrants(ctx, "", work); err != nil || len(grants) != 2 {
+		t.Errorf("GetGrants of the list: got %d, %v, want 2", len(grants), err)
+	}
+	if grants, err = repo.GetUserGrants(ctx, ann); err != nil {
+		t.Fatalf("GetUserGrants: %v", err)
+	}
+	got = nil
+	for _, g := range grants {
+		got = append(got, fmt.Sprintf("%v/%v/%v", g.ItemID == item.ID, g.ListID == work, g.Access))
+	}
+	want = []string{"true/false/view", "false/true/edit"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got grants of ann (item/list/access) %q, want %q", got, want)
+	}
+
+	if err = repo.DeleteGrant(ctx, model.Grant{ListID: work, UserID: ann}); err != nil {
+		t.Fatalf("DeleteGrant: %v", err)
+	}
+	if got := access(t, repo, ann, item); got != model.AccessView {
+		t.Errorf("got access %q after revoking the list, want view", got)
+	}
+	err = repo.DeleteGrant(ctx, model.Grant{ListID: work, UserID: ann})
+	if !errors.Is(err, todo.ErrGrantNotFound) {
+		t.Errorf("DeleteGrant again: got %v, want ErrGrantNotFound", err)
+	}
+
+	// the grants are purged with the item
+	remove(t, repo, item)
+	if _, err = repo.PurgeItem(ctx, owner, item.ID); err != nil {
+		t.Fatalf("PurgeItem: %v", err)
+	}
+	if grants, err = repo.GetGrants(ctx, item.ID, ""); err != nil || len(grants) != 0 {
+		t.Errorf("GetGrants of a purged item: got %d, %v, want none", len(grants), err)
+	}
+}
+
+func testSharedItems(t *testing.T, repo todo.Repository) {
+	owner, ann, stranger := newOwner(), newOwner(), newOwner()
+	work, home := uuid.New().String(), uuid.New().String()
+	report := create(t, repo, model.Item{OwnerID: owner, ListID: work, Title: "Report"})
+	create(t, repo, model.Item{OwnerID: owner, ListID: work, Title: "Slides"})
+	create(t, repo, model.Item{OwnerID: owner, ListID: home, Title: "Dishes"})
+	create(t, repo, model.Item{OwnerID: ann, ListID: work, Title: "Ann's"})
+	plan := create(t, repo, model.Item{OwnerID: owner, ListID: home, Title: "Plan"})
+	create(t, repo, model.Item{OwnerID: owner, ListID: home, ParentID: plan.ID, Title: "Step"})
+
+	if _, err := repo.GetItem(ctx, ann, report.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem before sharing: got %v, want ErrNotFound", err)
+	}
+
+	grant(t, repo, model.Grant{ListID: work, UserID: ann, Access: model.AccessView})
+	grant(t, repo, model.Grant{ItemID: plan.ID, UserID: ann, Access: model.AccessEdit})
+
+	// the items of the user are left out, they are listed without Shared
+	expectTitles(t, list(t, repo, model.Query{OwnerID: ann, Shared: true}), "Report", "Slides", "Plan")
+	expectTitles(t, list(t, repo, model.Query{OwnerID: ann, Shared: true, ListID: work}), "Report", "Slides")
+	expectTitles(t, list(t, repo, model.Query{OwnerID: ann}), "Ann's")
+	expectTitles(t, list(t, repo, model.Query{OwnerID: stranger, Shared: true}))
+
+	if got := get(t, repo, ann, report.ID); got.OwnerID != owner {
+		t.Errorf("got owner %q of the shared item, want %q", got.OwnerID, owner)
+	}
+	// the subtasks of a shared item are listed with it
+	subtasks, err := repo.GetSubtasks(ctx, ann, plan.ID)
+	if err != nil || len(subtasks) != 1 {
+		t.Errorf("GetSubtasks of a shared item: got %d, %v, want 1", len(subtasks), err)
+	}
+	if history, err := repo.GetRevisions(ctx, ann, report.ID); err != nil || len(history) != 1 {
+		t.Errorf("GetRevisions of a shared item: got %d, %v, want 1", len(history), err)
+	}
+	if _, err = repo.GetRevision(ctx, stranger, report.ID, 1); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetRevision by a stranger: got %v, want ErrNotFound", err)
+	}
+	if _, err = repo.GetItem(ctx, stranger, report.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem by a stranger: got %v, want ErrNotFound", err)
+	}
+
+	// the changes stay scoped to the owner
+	if _, err = repo.DeleteItem(ctx, ann, report.ID, 0); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("DeleteItem by a grantee: got %v, want ErrNotFound", err)
+	}
+
+	if err = repo.DeleteGrant(ctx, model.Grant{ListID: work, UserID: ann}); err != nil {
+		t.Fatalf("DeleteGrant: %v", err)
+	}
+	expectTitles(t, list(t, repo, model.Query{OwnerID: ann, Shared: true}), "Plan")
+	if _, err = repo.GetItem(ctx, ann, report.ID); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem after revoking: got %v, want ErrNotFound", err)
+	}
+}
+
+func testFilters(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	yes := true
+	create(t, repo, model.Item{OwnerID: owner, ListID: "home", Title: "Early", DueAt: day(1), Priority: model.PriorityHigh, Tags: []string{"a", "b"}})
+	create(t, repo, model.Item{OwnerID: owner, ListID: "home", Title: "Late", DueAt: day(20), Priority: model.PriorityLow, Tags: []string{"a"}})
+	create(t, repo, model.Item{OwnerID: owner, ListID: "work", Title: "Done", Completed: true, Priority: model.PriorityHigh, Tags: []string{"b"}})
+	create(t, repo, model.Item{OwnerID: owner, ListID: "work", Title: "Plain"})
+
+	tests := []struct {
+		name  string
+		query model.Query
+		want  []string
+	}{
+		{"all", model.Query{}, []string{"Early", "Late", "Done", "Plain"}},
+		{"list", model.Query{ListID: "home"}, []string{"Early", "Late"}},
+		{"completed", model.Query{Completed: &yes}, []string{"Done"}},
+		{"due before", model.Query{DueBefore: day(20)}, []string{"Early"}},
+		{"due after", model.Query{DueAfter: day(1)}, []string{"Late"}},
+		{"priorities", model.Query{Priorities: []model.Priority{model.PriorityHigh, model.PriorityLow}}, []string{"Early", "Late", "Done"}},
+		{"any tag", model.Query{Tags: []string{"b", "c"}}, []string{"Early", "Done"}},
+		{"all tags", model.Query{Tags: []string{"a", "b"}, TagMode: model.TagModeAll}, []string{"Early"}},
+		{"combined", model.Query{ListID: "home", Tags: []string{"a"}, Priorities: []model.Priority{model.PriorityLow}}, []string{"Late"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.query.OwnerID = owner
+			expectTitles(t, list(t, repo, tt.query), tt.want...)
+		})
+	}
+}
+
+func testSearch(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	create(t, repo, model.Item{OwnerID: owner, Title: "Call MOM"})
+	create(t, repo, model.Item{OwnerID: owner, Title: "Groceries", Description: "ask mom about the list"})
+	create(t, repo, model.Item{OwnerID: owner, Title: "50% off_sale"})
+	create(t, repo, model.Item{OwnerID: owner, Title: "500 offers"})
+
+	tests := []struct {
+		search string
+		want   []string
+	}{
+		{"mom", []string{"Call MOM", "Groceries"}},
+		{"Mom", []string{"Call MOM", "Groceries"}},
+		{"%", []string{"50% off_sale"}},
+		{"_", []string{"50% off_sale"}},
+		{"50", []string{"50% off_sale", "500 offers"}},
+		{"nothing", nil},
+	}
+
+	for _, tt := range tests {
+		expectTitles(t, list(t, repo, model.Query{OwnerID: owner, Search: tt.search}), tt.want...)
+	}
+}
+
+// sortKey compares the sort field of two items, items without due date are last in both directions.
+func sortKey(a, b model.Item, field model.SortField, desc bool) int {
+	sign := 1
+	if desc {
+		sign = -1
+	}
+
+	switch field {
+	case model.SortUpdated:
+		return sign * compareTime(a.UpdatedAt, b.UpdatedAt)
+	case model.SortPriority:
+		return sign * (int(a.Priority) - int(b.Priority))
+	case model.SortDue:
+		switch {
+		case a.DueAt == nil && b.DueAt == nil:
+			return 0
+		case a.DueAt == nil:
+			return 1
+		case b.DueAt == nil:
+			return -1
+		}
+		return sign * compareTime(*a.DueAt, *b.DueAt)
+	}
+
+	return 0
+}
+
+func compareTime(a, b time.Time) int {
+	switch {
+	case a.Before(b):
+		return -1
+	case a.After(b):
+		return 1
+	}
+
+	return 0
+}
+
+// inOrder reports whether b may follow a: by the sort field, then by (created_at, id).
+func inOrder(a, b model.Item, field model.SortField, desc bool) bool {
+	if c := sortKey(a, b, field, desc); c != 0 {
+		return c < 0
+	}
+
+	c := compareTime(a.CreatedAt, b.CreatedAt)
+	if c == 0 {
+		c = strings.Compare(a.ID, b.ID)
+	}
+	if desc {
+		return c > 0
+	}
+	return c < 0
+}
+
+var sortFields = []model.SortField{model.SortCreated, model.SortUpdated, model.SortDue, model.SortPriority}
+
+// createSortable creates items with ties on every sort field.
+func createSortable(t *testing.T, repo todo.Repository, owner string) {
+	t.Helper()
+
+	dues := []*time.Time{day(3), nil, day(1), day(3), nil, day(2), day(1)}
+	priorities := []model.Priority{model.PriorityLow, model.PriorityHigh, model.PriorityLow, model.PriorityNone,
+		model.PriorityHigh, model.PriorityMedium, model.PriorityLow}
+	for i := range dues {
+		create(t, repo, model.Item{OwnerID: owner, Title: string(rune('A' + i)), DueAt: dues[i], Priority: priorities[i]})
+	}
+}
+
+func testOrder(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	createSortable(t, repo, owner)
+
+	for _, field := range sortFields {
+		for _, desc := range []bool{false, true} {
+			items := list(t, repo, model.Query{OwnerID: owner, Sort: field, Desc: desc})
+			if len(items) != 7 {
+				t.Fatalf("sort %s: got %d items, want 7", field, len(items))
+			}
+			for i := 1; i < len(items); i++ {
+				if !inOrder(items[i-1], items[i], field, desc) {
+					t.Errorf("sort %s desc %v: %s is before %s", field, desc, items[i-1].Title, items[i].Title)
+				}
+			}
+		}
+	}
+}
+
+func testPages(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	createSortable(t, repo, owner)
+
+	for _, field := range sortFields {
+		for _, desc := range []bool{false, true} {
+			query := model.Query{OwnerID: owner, Sort: field, Desc: desc}
+			want := titles(list(t, repo, query))
+
+			var got []string
+			query.PageSize = 2
+			for i := 0; i < 10; i++ {
+				page := list(t, repo, query)
+				if len(page) > 2 {
+					t.Fatalf("sort %s: got a page of %d items, want at most 2", field, len(page))
+				}
+				got = append(got, titles(page)...)
+				if len(page) < 2 {
+					break
+				}
+				after := model.CursorOf(page[len(page)-1], field, desc)
+				query.After = &after
+			}
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("sort %s desc %v: paged %q, want %q", field, desc, got, want)
+			}
+		}
+	}
+}
+
+func tagsOf(t *testing.T, repo todo.Repository, owner string) map[string]model.Tag {
+	t.Helper()
+
+	tags, err := repo.GetAllTags(ctx, owner)
+	if err != nil {
+		t.Fatalf("GetAllTags: %v", err)
+	}
+
+	names := make([]string, 0, len(tags))
+	res := make(map[string]model.Tag, len(tags))
+	for _, tag := range tags {
+		if tag.ID == "" || tag.OwnerID != owner {
+			t.Errorf("tag not filled: %+v", tag)
+		}
+		names = append(names, tag.Name)
+		res[tag.Name] = tag
+	}
+	if !sort.StringsAreSorted(names) {
+		t.Errorf("got tags %q, want them sorted by name", names)
+	}
+
+	return res
+}
+
+func testTags(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	create(t, repo, model.Item{OwnerID: owner, Title: "One", Tags: []string{"work", "home"}})
+	two := create(t, repo, model.Item{OwnerID: owner, Title: "Two", Tags: []string{"work"}})
+	create(t, repo, model.Item{OwnerID: stranger, Title: "Stranger's", Tags: []string{"work", "secret"}})
+
+	tags := tagsOf(t, repo, owner)
+	if len(tags) != 2 || tags["work"].ItemCount != 2 || tags["home"].ItemCount != 1 {
+		t.Errorf("got tags %+v, want work with 2 items and home with 1", tags)
+	}
+
+	// an untagged tag is kept with no items
+	two.Tags = nil
+	if _, err := repo.UpdateItem(ctx, two); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	tags = tagsOf(t, repo, owner)
+	if tags["work"].ItemCount != 1 {
+		t.Errorf("got %d items tagged work, want 1", tags["work"].ItemCount)
+	}
+}
+
+func testSharedTags(t *testing.T, repo todo.Repository) {
+	owner, ann := newOwner(), newOwner()
+	create(t, repo, model.Item{OwnerID: owner, Title: "Own", Tags: []string{"work"}})
+	shared := create(t, repo, model.Item{OwnerID: ann, Title: "Shared", Tags: []string{"work", "secret"}})
+	create(t, repo, model.Item{OwnerID: ann, Title: "Private", Tags: []string{"work", "private"}})
+	create(t, repo, model.Item{OwnerID: ann, ListID: "ann's", Title: "Listed", Tags: []string{"listed"}})
+
+	grant(t, repo, model.Grant{ItemID: shared.ID, UserID: owner, Access: model.AccessView})
+	grant(t, repo, model.Grant{ListID: "ann's", UserID: owner, Access: model.AccessView})
+
+	tags, err := repo.GetAllTags(ctx, owner)
+	if err != nil {
+		t.Fatalf("GetAllTags: %v", err)
+	}
+	var got []string
+	for _, tag := range tags {
+		got = append(got, fmt.Sprintf("%s/%v/%d", tag.Name, tag.OwnerID == owner, tag.ItemCount))
+	}
+	// the tags of the same name are ordered by their owners
+	want := []string{"listed/false/1", "secret/false/1", "work/false/1", "work/true/1"}
+	if owner < ann {
+		want[2], want[3] = want[3], want[2]
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got tags (name/own/items) %q, want %q", got, want)
+	}
+}
+
+func testRenameTag(t *testing.T, repo todo.Repository) {
+	owner, stranger := newOwner(), newOwner()
+	item := create(t, repo, model.Item{OwnerID: owner, Title: "One", Tags: []string{"wrok"}})
+	work := tagsOf(t, repo, owner)["wrok"]
+
+	if _, err := repo.RenameTag(ctx, stranger, work.ID, "stolen"); !errors.Is(err, todo.ErrTagNotFound) {
+		t.Errorf("RenameTag by another owner: got %v, want ErrTagNotFound", err)
+	}
+	if _, err := repo.RenameTag(ctx, owner, uuid.New().String(), "x"); !errors.Is(err, todo.ErrTagNotFound) {
+		t.Errorf("RenameTag of an unknown ID: got %v, want ErrTagNotFound", err)
+	}
+
+	id, err := repo.RenameTag(ctx, owner, work.ID, "work")
+	if err != nil || id != work.ID {
+		t.Fatalf("RenameTag: got %q, %v", id, err)
+	}
+	if got := get(t, repo, owner, item.ID); !reflect.DeepEqual(got.Tags, []string{"work"}) {
+		t.Errorf("got tags %q, want [work]", got.Tags)
+	}
+
+	// renaming to the current name changes nothing
+	if id, err = repo.RenameTag(ctx, owner, work.ID, "work"); err != nil || id != work.ID {
+		t.Errorf("RenameTag to the same name: got %q, %v", id, err)
+	}
+}
+
+func testMergeTag(t *testing.T, repo todo.Repository) {
+	owner := newOwner()
+	both := create(t, repo, model.Item{OwnerID: owner, Title: "Both", Tags: []string{"job", "work"}})
+	job := create(t, repo, model.Item{OwnerID: owner, Title: "Job", Tags: []string{"job"}})
+	tags := tagsOf(t, repo, owner)
+
+	id, err := repo.RenameTag(ctx, owner, tags["job"].ID, "work")
+	if err != nil || id != tags["work"].ID {
+		t.Fatalf("RenameTag into an existing tag: got %q, %v, want the ID of the existing tag", id, err)
+	}
+
+	merged := tagsOf(t, repo, owner)
+	if len(merged) != 1 || merged["work"].ItemCount != 2 {
+		t.Errorf("got tags %+v, want only work with 2 items", merged)
+	}
+	for _, item := range []model.Item{both, job} {
+		if got := get(t, repo, owner, item.ID); !reflect.DeepEqual(got.Tags, []string{"work"}) {
+			t.Errorf("%s: got tags %q, want [work]", item.Title, got.Tags)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+package todo
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+)
+
+// Actor returns the ID of the user making the change in ctx (see auth.NewContext),
+// empty for the changes the service makes on its own, like the trash retention.
+// The repositories record it in the revisions.
+func Actor(ctx context.Context) string {
+	user, _ := auth.FromContext(ctx)
+	return user.ID
+}
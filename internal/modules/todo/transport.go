@@ -9,5 +9,26 @@ type Transport interface {
 	GetAllItems(w http.ResponseWriter, r *http.Request)
 	GetItem(w http.ResponseWriter, r *http.Request)
 	UpdateItem(w http.ResponseWriter, r *http.Request)
+	// PatchItem accepts JSON Merge Patch and JSON Patch documents, told by the Content-Type.
+	PatchItem(w http.ResponseWriter, r *http.Request)
 	DeleteItem(w http.ResponseWriter, r *http.Request)
+	GetTrash(w http.ResponseWriter, r *http.Request)
+	RestoreItem(w http.ResponseWriter, r *http.Request)
+	PurgeItem(w http.ResponseWriter, r *http.Request)
+	GetHistory(w http.ResponseWriter, r *http.Request)
+	GetRevision(w http.ResponseWriter, r *http.Request)
+	// RevertItem takes the revision to revert to in the rev query param.
+	RevertItem(w http.ResponseWriter, r *http.Request)
+	GetItemTree(w http.ResponseWriter, r *http.Request)
+	MoveItem(w http.ResponseWriter, r *http.Request)
+	GetOccurrences(w http.ResponseWriter, r *http.Request)
+	Events(w http.ResponseWriter, r *http.Request)
+	EventsWebSocket(w http.ResponseWriter, r *http.Request)
+	GetItemGrants(w http.ResponseWriter, r *http.Request)
+	// ShareItem takes the user from the user_id path param and the access in the body.
+	ShareItem(w http.ResponseWriter, r *http.Request)
+	UnshareItem(w http.ResponseWriter, r *http.Request)
+
+	GetAllTags(w http.ResponseWriter, r *http.Request)
+	RenameTag(w http.ResponseWriter, r *http.Request)
 }
@@ -0,0 +1,148 @@
+package gorilla_mux
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/gorilla/websocket"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/problem"
+)
+
+// keepAlive is the interval of the SSE comments and WebSocket pings which keep idle
+// connections open through proxies.
+const keepAlive = 15 * time.Second
+
+// checkOrigin lets in the WebSocket connections of the pages of the service's own origin
+// and of the allowed ones. Another site could open the stream with a token it got hold of
+// otherwise. The clients other than browsers send no Origin.
+func checkOrigin(allowed []string) func(r *http.Request) bool {
+	return func(r *http.Request) bool {
+		origin := r.Header.Get("Origin")
+		if origin == "" {
+			return true
+		}
+		if u, err := url.Parse(origin); err == nil && strings.EqualFold(u.Host, r.Host) {
+			return true
+		}
+		for _, o := range allowed {
+			if strings.EqualFold(origin, o) {
+				return true
+			}
+		}
+
+		return false
+	}
+}
+
+// Events streams item changes as Server-Sent Events. A reconnecting EventSource sends
+// the Last-Event-ID header, other clients may use the last_event_id query param.
+func (t *transport) Events(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("Events")
+	ctx := r.Context()
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		problem.Write(t.logger, w, r, errors.New("streaming is not supported"))
+		return
+	}
+
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	events, err := t.useCase.Subscribe(ctx, lastEventID)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				return
+			}
+			data, err := json.Marshal(event)
+			if err != nil {
+				t.logger.Error("unable to marshal event", zap.Error(err))
+				return
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.ID, event.Type, data)
+		case <-ticker.C:
+			fmt.Fprint(w, ": keep-alive\n\n")
+		}
+		flusher.Flush()
+	}
+}
+
+// EventsWebSocket streams item changes as JSON text messages, each carries its id
+// to pass as the last_event_id query param when reconnecting.
+func (t *transport) EventsWebSocket(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("EventsWebSocket")
+	ctx := r.Context()
+
+	events, err := t.useCase.Subscribe(ctx, r.URL.Query().Get("last_event_id"))
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	conn, err := t.upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		// the upgrader has responded already
+		t.logger.Debug("websocket upgrade failed", zap.Error(err))
+		return
+	}
+	defer conn.Close()
+
+	// the stream is one way, reading only handles the control frames and notices the close
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.NextReader(); err != nil {
+				return
+			}
+		}
+	}()
+
+	ticker := time.NewTicker(keepAlive)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case event, ok := <-events:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage,
+					websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "subscriber fell behind"))
+				return
+			}
+			if err = conn.WriteJSON(event); err != nil {
+				return
+			}
+		case <-ticker.C:
+			if err = conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(keepAlive)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package gorilla_mux
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// parseQuery reads the GET /todo/ filters: shared, list_id, completed, due_before, due_after,
+// priority and tag (repeatable), tag_mode, q, sort, order, cursor and page_size.
+func parseQuery(r *http.Request) (model.Query, error) {
+	var query model.Query
+	var v validate.Validator
+	values := r.URL.Query()
+
+	if s := values.Get("shared"); s != "" {
+		shared, err := strconv.ParseBool(s)
+		v.Check("shared", err == nil, validate.CodeType, "shared param is not a boolean")
+		query.Shared = shared
+	}
+
+	if s := values.Get("completed"); s != "" {
+		completed, err := strconv.ParseBool(s)
+		v.Check("completed", err == nil, validate.CodeType, "completed param is not a boolean")
+		query.Completed = &completed
+	}
+
+	query.DueBefore = parseTime(&v, values.Get("due_before"), "due_before")
+	query.DueAfter = parseTime(&v, values.Get("due_after"), "due_after")
+
+	for _, s := range values["priority"] {
+		priority, err := model.ParsePriority(s)
+		if err != nil {
+			v.Add("priority", validate.CodeNotAllowed, err.Error())
+			continue
+		}
+		query.Priorities = append(query.Priorities, priority)
+	}
+
+	query.ListID = values.Get("list_id")
+	query.Tags = values["tag"]
+	query.TagMode = model.TagMode(values.Get("tag_mode"))
+	query.Search = values.Get("q")
+	query.Sort = model.SortField(values.Get("sort"))
+
+	order := strings.ToLower(values.Get("order"))
+	v.String("order", order, validate.OneOf("", "asc", "desc"))
+	query.Desc = order == "desc"
+
+	query.Cursor = values.Get("cursor")
+	if s := values.Get("page_size"); s != "" {
+		var err error
+		query.PageSize, err = strconv.Atoi(s)
+		v.Check("page_size", err == nil, validate.CodeType, "page_size param is not a number")
+	}
+
+	return query, v.Err(todo.ErrInvalidQuery)
+}
+
+func parseTime(v *validate.Validator, s, param string) *time.Time {
+	if s == "" {
+		return nil
+	}
+
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		v.Add(param, validate.CodeFormat, param+" param is not an RFC 3339 timestamp")
+		return nil
+	}
+
+	return &t
+}
@@ -0,0 +1,92 @@
+package gorilla_mux
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/httpjson"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/problem"
+)
+
+var errMissingUserID = errs.New(errs.Validation, "missed user_id path param")
+
+// GetItemGrants lists who the item is shared with, the grants of its list included.
+func (t *transport) GetItemGrants(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetItemGrants")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	grants, err := t.useCase.GetItemGrants(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, grants)
+}
+
+func (t *transport) ShareItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("ShareItem")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id, userID := params["id"], params["user_id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+	if userID == "" {
+		problem.Write(t.logger, w, r, errMissingUserID)
+		return
+	}
+
+	var req struct {
+		Access model.Access `json:"access"`
+	}
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	id, err := t.useCase.ShareItem(ctx, id, userID, req.Access)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "shared", "id": id})
+}
+
+func (t *transport) UnshareItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("UnshareItem")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id, userID := params["id"], params["user_id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+	if userID == "" {
+		problem.Write(t.logger, w, r, errMissingUserID)
+		return
+	}
+
+	id, err := t.useCase.UnshareItem(ctx, id, userID)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "unshared", "id": id})
+}
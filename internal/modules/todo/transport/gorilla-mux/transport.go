@@ -1,46 +1,60 @@
 package gorilla_mux
 
 import (
-	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gorilla/mux"
+	"github.com/gorilla/websocket"
 	"go.uber.org/zap"
 
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/etag"
+	"github.com/silverspase/todo/internal/httpjson"
 	"github.com/silverspase/todo/internal/modules/todo"
 	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/problem"
+)
+
+// errors of the requests which don't reach the use case
+var (
+	errInvalidPayload = errs.New(errs.Validation, "invalid request payload")
+	errMissingID      = errs.New(errs.Validation, "missed id path param")
 )
 
 type transport struct {
-	useCase todo.UseCase
-	logger  *zap.Logger
+	useCase  todo.UseCase
+	upgrader websocket.Upgrader
+	logger   *zap.Logger
 }
 
-func NewTransport(logger *zap.Logger, useCase todo.UseCase) todo.Transport {
+// NewTransport returns the transport whose WebSocket stream the browsers may open from
+// the service's own origin and the allowed ones, e.g. "https://app.example.com".
+func NewTransport(logger *zap.Logger, useCase todo.UseCase, allowedOrigins []string) todo.Transport {
 	return &transport{
-		useCase: useCase,
-		logger:  logger,
+		useCase:  useCase,
+		upgrader: websocket.Upgrader{CheckOrigin: checkOrigin(allowedOrigins)},
+		logger:   logger,
 	}
 }
 
 func (t *transport) CreateItem(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("transport.CreateItem")
-	ctx := context.Background()
+	ctx := r.Context()
 	defer r.Body.Close()
 
 	var item model.Item
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&item); err != nil {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "Invalid request payload"})
+	if err := httpjson.Decode(r, &item, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
 	id, err := t.useCase.CreateItem(ctx, item)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
@@ -49,45 +63,43 @@ func (t *transport) CreateItem(w http.ResponseWriter, r *http.Request) {
 
 func (t *transport) GetAllItems(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("GetAllItems")
-	ctx := context.Background()
-
-	var page int
-	var err error
-
-	pageStr := r.FormValue("page")
-	if pageStr == "" {
-		page = 1
-	} else {
-		page, err = strconv.Atoi(pageStr)
-		if err != nil {
-			respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "page param is not a number"})
-			return
-		}
+	ctx := r.Context()
+
+	query, err := parseQuery(r)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
 	}
 
-	items, err := t.useCase.GetAllItems(ctx, page)
+	page, err := t.useCase.GetAllItems(ctx, query)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
-	respondWithJSON(w, http.StatusOK, items)
+	respondWithJSON(w, http.StatusOK, page)
 }
 
 func (t *transport) GetItem(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("GetItem")
-	ctx := context.Background()
+	ctx := r.Context()
 
 	params := mux.Vars(r)
 	id := params["id"]
 	if id == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "missed id path param"})
+		problem.Write(t.logger, w, r, errMissingID)
 		return
 	}
 
 	item, err := t.useCase.GetItem(ctx, id)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": fmt.Sprintf("unable to get item with id %v: %v", id, err)})
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to get item with id %v: %w", id, err))
+		return
+	}
+
+	w.Header().Set("ETag", etag.Format(item.Version))
+	if etag.NoneMatch(r, item.Version) {
+		w.WriteHeader(http.StatusNotModified)
 		return
 	}
 
@@ -96,52 +108,342 @@ func (t *transport) GetItem(w http.ResponseWriter, r *http.Request) {
 
 func (t *transport) UpdateItem(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("UpdateItem")
-	ctx := context.Background()
+	ctx := r.Context()
 	defer r.Body.Close()
 
 	params := mux.Vars(r)
 	id := params["id"]
 	if id == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "missed id path param"})
+		problem.Write(t.logger, w, r, errMissingID)
 		return
 	}
 
 	var item model.Item
-	decoder := json.NewDecoder(r.Body)
-	if err := decoder.Decode(&item); err != nil {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"unmarshal error": "Invalid request payload"})
+	if err := httpjson.Decode(r, &item, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
 		return
 	}
 
 	item.ID = id
+	// the version the client has read comes in If-Match, not in the body
+	item.Version = etag.IfMatch(r)
 	id, err := t.useCase.UpdateItem(ctx, item)
 	if err != nil {
-		respondWithJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		problem.Write(t.logger, w, r, err)
 		return
 	}
+	if item.Version > 0 {
+		// the precondition held, so this update made the next version
+		w.Header().Set("ETag", etag.Format(item.Version+1))
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+}
+
+func (t *transport) PatchItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("PatchItem")
+	ctx := r.Context()
+	defer r.Body.Close()
+	httpjson.AcceptPatch(w)
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	patch, err := httpjson.DecodePatch(r)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	version := etag.IfMatch(r)
+	id, err = t.useCase.PatchItem(ctx, id, patch, version)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+	if version > 0 {
+		w.Header().Set("ETag", etag.Format(version+1))
+	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
 }
 
 func (t *transport) DeleteItem(w http.ResponseWriter, r *http.Request) {
 	t.logger.Debug("DeleteItem")
-	ctx := context.Background()
+	ctx := r.Context()
 
 	params := mux.Vars(r)
 	id := params["id"]
 	if id == "" {
-		respondWithJSON(w, http.StatusBadRequest, map[string]string{"error": "missed id path param"})
+		problem.Write(t.logger, w, r, errMissingID)
 		return
 	}
-	id, err := t.useCase.DeleteItem(ctx, id)
+	_, err := t.useCase.DeleteItem(ctx, id, etag.IfMatch(r))
 	if err != nil {
-		respondWithJSON(w, http.StatusNotFound, map[string]string{"error": fmt.Sprintf("item with id %v not found", id)})
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to delete item with id %v: %w", id, err))
 		return
 	}
 
 	respondWithJSON(w, http.StatusOK, map[string]string{"status": "deleted", "id": id})
 }
 
+func (t *transport) GetItemTree(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetItemTree")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	tree, err := t.useCase.GetItemTree(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to get item with id %v: %w", id, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tree)
+}
+
+func (t *transport) MoveItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("MoveItem")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var req struct {
+		ListID   string `json:"list_id"`
+		ParentID string `json:"parent_id"`
+	}
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	id, err := t.useCase.MoveItem(ctx, id, req.ListID, req.ParentID)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "moved", "id": id})
+}
+
+// GetTrash lists the deleted items, which can be restored or purged.
+func (t *transport) GetTrash(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetTrash")
+
+	items, err := t.useCase.GetTrash(r.Context())
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, items)
+}
+
+func (t *transport) RestoreItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("RestoreItem")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	id, err := t.useCase.RestoreItem(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "restored", "id": id})
+}
+
+// PurgeItem permanently deletes an item of the trash.
+func (t *transport) PurgeItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("PurgeItem")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	id, err := t.useCase.PurgeItem(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "purged", "id": id})
+}
+
+// GetHistory lists the revisions of an item, live or deleted.
+func (t *transport) GetHistory(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetHistory")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	revisions, err := t.useCase.GetHistory(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revisions)
+}
+
+// GetRevision returns a revision of an item with the item as it was after the change.
+func (t *transport) GetRevision(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetRevision")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+	rev, err := strconv.ParseInt(params["rev"], 10, 64)
+	if err != nil {
+		problem.Write(t.logger, w, r, errs.New(errs.Validation, "rev path param is not a number"))
+		return
+	}
+
+	revision, err := t.useCase.GetRevision(ctx, id, rev)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, revision)
+}
+
+// RevertItem brings an item back to the revision in the rev query param.
+func (t *transport) RevertItem(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("RevertItem")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+	rev, err := strconv.ParseInt(r.URL.Query().Get("rev"), 10, 64)
+	if err != nil {
+		problem.Write(t.logger, w, r, errs.New(errs.Validation, "rev param is not a number"))
+		return
+	}
+
+	version := etag.IfMatch(r)
+	id, err = t.useCase.RevertItem(ctx, id, rev, version)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+	if version > 0 {
+		w.Header().Set("ETag", etag.Format(version+1))
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "reverted", "id": id})
+}
+
+// GetOccurrences previews the next occurrences of a recurring item, n query param limits their number.
+func (t *transport) GetOccurrences(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetOccurrences")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var n int
+	if s := r.URL.Query().Get("n"); s != "" {
+		var err error
+		if n, err = strconv.Atoi(s); err != nil {
+			problem.Write(t.logger, w, r, errs.New(errs.Validation, "n param is not a number"))
+			return
+		}
+	}
+
+	occurrences, err := t.useCase.GetOccurrences(ctx, id, n)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string][]time.Time{"occurrences": occurrences})
+}
+
+func (t *transport) GetAllTags(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetAllTags")
+
+	tags, err := t.useCase.GetAllTags(r.Context())
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, tags)
+}
+
+// RenameTag renames the tag, or merges it into the tag which already has the new name.
+func (t *transport) RenameTag(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("RenameTag")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var req struct {
+		Name string `json:"name"`
+	}
+	if err := httpjson.Decode(r, &req, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	id, err := t.useCase.RenameTag(ctx, id, req.Name)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+}
+
 func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
 	response, _ := json.Marshal(payload)
 
@@ -0,0 +1,97 @@
+package grpc
+
+import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/modules/todo/transport/grpc/pb"
+)
+
+func toProto(item model.Item) *pb.Item {
+	return &pb.Item{
+		Id:          item.ID,
+		OwnerId:     item.OwnerID,
+		ListId:      item.ListID,
+		ParentId:    item.ParentID,
+		Title:       item.Title,
+		Description: item.Description,
+		Completed:   item.Completed,
+		CompletedAt: timestamp(item.CompletedAt),
+		DueAt:       timestamp(item.DueAt),
+		Priority:    pb.Priority(item.Priority),
+		Tags:        item.Tags,
+		Recurrence:  item.Recurrence,
+		SeriesStart: timestamp(item.SeriesStart),
+		CreatedAt:   timestamppb.New(item.CreatedAt),
+		UpdatedAt:   timestamppb.New(item.UpdatedAt),
+		Version:     item.Version,
+	}
+}
+
+// fromProto takes the fields the client controls, the use case ignores the rest.
+func fromProto(item *pb.Item) model.Item {
+	return model.Item{
+		ID:          item.GetId(),
+		ListID:      item.GetListId(),
+		ParentID:    item.GetParentId(),
+		Title:       item.GetTitle(),
+		Description: item.GetDescription(),
+		Completed:   item.GetCompleted(),
+		DueAt:       timeOf(item.GetDueAt()),
+		Priority:    model.Priority(item.GetPriority()),
+		Tags:        item.GetTags(),
+		Recurrence:  item.GetRecurrence(),
+		Version:     item.GetVersion(),
+	}
+}
+
+func queryFromProto(req *pb.ListItemsRequest) model.Query {
+	var ok bool
+	query := model.Query{
+		ListID:    req.GetListId(),
+		DueBefore: timeOf(req.GetDueBefore()),
+		DueAfter:  timeOf(req.GetDueAfter()),
+		Tags:      req.GetTags(),
+		Search:    req.GetSearch(),
+		Desc:      req.GetDesc(),
+		Cursor:    req.GetCursor(),
+		PageSize:  int(req.GetPageSize()),
+		Shared:    req.GetShared(),
+	}
+	query.Sort, ok = sortFields[req.GetSort()]
+	if !ok {
+		// unknown values are rejected by the use case
+		query.Sort = model.SortField(req.GetSort().String())
+	}
+	if req.Completed != nil {
+		completed := req.Completed.GetValue()
+		query.Completed = &completed
+	}
+	for _, p := range req.GetPriorities() {
+		query.Priorities = append(query.Priorities, model.Priority(p))
+	}
+	if req.GetAllTags() {
+		query.TagMode = model.TagModeAll
+	}
+
+	return query
+}
+
+func timestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+
+	return timestamppb.New(*t)
+}
+
+func timeOf(ts *timestamppb.Timestamp) *time.Time {
+	if ts == nil {
+		return nil
+	}
+
+	t := ts.AsTime()
+	return &t
+}
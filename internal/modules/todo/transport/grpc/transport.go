@@ -0,0 +1,93 @@
+package grpc
+
+import (
+	"context"
+
+	"go.uber.org/zap"
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/silverspase/todo/internal/grpcstatus"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/modules/todo/transport/grpc/pb"
+)
+
+type transport struct {
+	pb.UnimplementedTodoServiceServer
+
+	useCase todo.UseCase
+	logger  *zap.Logger
+}
+
+func NewTransport(logger *zap.Logger, useCase todo.UseCase) pb.TodoServiceServer {
+	return &transport{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+func (t *transport) CreateItem(ctx context.Context, req *pb.CreateItemRequest) (*pb.ItemID, error) {
+	t.logger.Debug("grpc.CreateItem")
+
+	id, err := t.useCase.CreateItem(ctx, fromProto(req.GetItem()))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &pb.ItemID{Id: id}, nil
+}
+
+func (t *transport) ListItems(ctx context.Context, req *pb.ListItemsRequest) (*pb.ListItemsResponse, error) {
+	t.logger.Debug("grpc.ListItems")
+
+	page, err := t.useCase.GetAllItems(ctx, queryFromProto(req))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	res := &pb.ListItemsResponse{NextCursor: page.NextCursor}
+	for _, item := range page.Items {
+		res.Items = append(res.Items, toProto(item))
+	}
+
+	return res, nil
+}
+
+func (t *transport) GetItem(ctx context.Context, req *pb.ItemID) (*pb.Item, error) {
+	t.logger.Debug("grpc.GetItem")
+
+	item, err := t.useCase.GetItem(ctx, req.GetId())
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return toProto(item), nil
+}
+
+func (t *transport) UpdateItem(ctx context.Context, req *pb.UpdateItemRequest) (*pb.ItemID, error) {
+	t.logger.Debug("grpc.UpdateItem")
+
+	id, err := t.useCase.UpdateItem(ctx, fromProto(req.GetItem()))
+	if err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &pb.ItemID{Id: id}, nil
+}
+
+func (t *transport) DeleteItem(ctx context.Context, req *pb.DeleteItemRequest) (*emptypb.Empty, error) {
+	t.logger.Debug("grpc.DeleteItem")
+
+	if _, err := t.useCase.DeleteItem(ctx, req.GetId(), req.GetVersion()); err != nil {
+		return nil, grpcstatus.FromError(t.logger, err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+var sortFields = map[pb.SortField]model.SortField{
+	pb.SortField_SORT_CREATED:  model.SortCreated,
+	pb.SortField_SORT_UPDATED:  model.SortUpdated,
+	pb.SortField_SORT_DUE:      model.SortDue,
+	pb.SortField_SORT_PRIORITY: model.SortPriority,
+}
@@ -2,14 +2,75 @@ package todo
 
 import (
 	"context"
+	"time"
 
+	"github.com/silverspase/todo/internal/jsonpatch"
 	"github.com/silverspase/todo/internal/modules/todo/model"
 )
 
+// UseCase operates on the items of the user authenticated in ctx (see auth.NewContext).
+// The items other users shared with them are read, and changed with the edit access, as well:
+// ErrForbidden is returned for the rest, e.g. deleting, moving or sharing them.
 type UseCase interface {
 	CreateItem(ctx context.Context, items model.Item) (string, error)
-	GetAllItems(ctx context.Context, page int) ([]model.Item, error)
+	// GetAllItems returns the user's items, or the ones shared with them when query.Shared is set.
+	GetAllItems(ctx context.Context, query model.Query) (model.Page, error)
 	GetItem(ctx context.Context, id string) (model.Item, error)
+	// UpdateItem replaces the item. A non-zero item.Version must be the current version
+	// of the item, ErrVersionMismatch is returned otherwise.
 	UpdateItem(ctx context.Context, item model.Item) (string, error)
-	DeleteItem(ctx context.Context, id string) (string, error)
+	// PatchItem applies the patch to the JSON of the item and saves the result as UpdateItem does.
+	// Patching the read-only fields, like list_id, fails validation. A non-zero version is checked
+	// as UpdateItem does.
+	PatchItem(ctx context.Context, id string, patch jsonpatch.Patch, version int64) (string, error)
+	// DeleteItem moves the item with its subtasks to the trash. A non-zero version is checked
+	// as UpdateItem does.
+	DeleteItem(ctx context.Context, id string, version int64) (string, error)
+	// DeleteListItems moves the items of the user's list to the trash, in the transaction
+	// of ctx deleting the list. Their deletion is reported once it's committed.
+	DeleteListItems(ctx context.Context, listID string) error
+	// GetTrash returns the deleted items, the latest deleted first. The subtasks deleted
+	// with their parent are not listed, they are restored and purged with it.
+	GetTrash(ctx context.Context) ([]model.TrashedItem, error)
+	// RestoreItem takes the deleted item and the subtasks deleted with it out of the trash.
+	// The parent and the list of the item must not be deleted: ErrParentDeleted, ErrListDeleted.
+	RestoreItem(ctx context.Context, id string) (string, error)
+	// PurgeItem permanently deletes the deleted item with its subtasks.
+	PurgeItem(ctx context.Context, id string) (string, error)
+	// RunRetention purges the items deleted longer than the retention ago until ctx is done.
+	RunRetention(ctx context.Context)
+	// GetHistory returns the revisions of the item, live or deleted, oldest first and without
+	// the item JSON.
+	GetHistory(ctx context.Context, id string) ([]model.Revision, error)
+	// GetRevision returns the revision of the item with the item as it was after the change.
+	GetRevision(ctx context.Context, id string, rev int64) (model.Revision, error)
+	// RevertItem brings the fields a PUT replaces back to their state at the revision and saves
+	// the item as UpdateItem does, which is recorded as a new revision. The list and the parent
+	// are kept. A non-zero version is checked as UpdateItem does.
+	RevertItem(ctx context.Context, id string, rev, version int64) (string, error)
+	// GetItemTree returns the item with all its subtasks.
+	GetItemTree(ctx context.Context, id string) (model.Node, error)
+	// MoveItem moves the item with its subtasks under another parent item, or to the top level
+	// of a list when parentID is empty.
+	MoveItem(ctx context.Context, id, listID, parentID string) (string, error)
+	// GetOccurrences previews up to n occurrences of a recurring item following its due date.
+	// Completing an occurrence with UpdateItem creates the next one.
+	GetOccurrences(ctx context.Context, id string, n int) ([]time.Time, error)
+	// Subscribe streams the changes of the user's items and of the ones shared with them
+	// until ctx is done or the subscriber falls too far behind, then the channel is closed.
+	// A non-empty lastEventID replays the events following it first, ErrEventsExpired
+	// tells they are no longer retained.
+	Subscribe(ctx context.Context, lastEventID string) (<-chan model.Event, error)
+
+	// GetItemGrants returns who the item is shared with, directly or by its list.
+	GetItemGrants(ctx context.Context, id string) ([]model.Grant, error)
+	// ShareItem grants the user the access to the item, or changes the access they have.
+	// The user is given by their ID or email, see Grantee, and so is the one UnshareItem revokes.
+	ShareItem(ctx context.Context, id, userID string, access model.Access) (string, error)
+	// UnshareItem revokes the access of the user to the item, ErrGrantNotFound when it isn't shared with them.
+	// The access they have by the list of the item is kept.
+	UnshareItem(ctx context.Context, id, userID string) (string, error)
+
+	GetAllTags(ctx context.Context) ([]model.Tag, error)
+	RenameTag(ctx context.Context, id, name string) (string, error)
 }
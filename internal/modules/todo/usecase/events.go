@@ -0,0 +1,181 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+const (
+	// eventHistory is the number of recent events kept for resuming subscribers.
+	eventHistory = 1000
+	// subscriberBuffer is how far a subscriber may fall behind before it is disconnected.
+	subscriberBuffer = 64
+)
+
+func (i itemUseCase) Subscribe(ctx context.Context, lastEventID string) (<-chan model.Event, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := i.events.subscribe(user.ID, lastEventID)
+	if err != nil {
+		return nil, err
+	}
+
+	go func() {
+		<-ctx.Done()
+		i.events.unsubscribe(sub)
+	}()
+
+	return sub.ch, nil
+}
+
+// publish reports the change of the item to its subscribers and the listeners.
+func (i itemUseCase) publish(ctx context.Context, typ model.EventType, item model.Item) {
+	event := model.Event{Type: typ, OwnerID: item.OwnerID, ItemID: item.ID}
+	if typ != model.EventDeleted {
+		// the stored version carries the timestamps set by the repository
+		stored, err := i.repo.GetItem(ctx, item.OwnerID, item.ID)
+		if err != nil {
+			i.logger.Error("unable to load the changed item", zap.String("id", item.ID), zap.Error(err))
+			return
+		}
+		event.Item = &stored
+	}
+
+	grants, err := i.repo.GetGrants(ctx, item.ID, item.ListID)
+	if err != nil {
+		// the owner is told about the change all the same
+		i.logger.Error("unable to load the grants of the changed item", zap.String("id", item.ID), zap.Error(err))
+	}
+	for _, grant := range grants {
+		if !event.VisibleTo(grant.UserID) {
+			event.SharedWith = append(event.SharedWith, grant.UserID)
+		}
+	}
+
+	event = i.events.publish(event)
+
+	// the change is stored already, so it's reported even if the request is cancelled meanwhile
+	for _, listener := range i.listeners {
+		if err := listener.ItemChanged(context.Background(), event); err != nil {
+			i.logger.Error("item listener failed", zap.String("event", event.ID), zap.Error(err))
+		}
+	}
+}
+
+// bus fans the item events out to the subscribers who can view the items.
+// It lives in memory, so every instance of the service has its own stream.
+type bus struct {
+	mu sync.Mutex
+	// epoch tells the event IDs of different runs apart
+	epoch   string
+	seq     uint64
+	evicted uint64 // sequence number of the newest event dropped from history
+	history []model.Event
+	subs    map[*subscriber]struct{}
+}
+
+type subscriber struct {
+	userID string
+	ch     chan model.Event
+}
+
+func newBus() *bus {
+	return &bus{
+		epoch: strconv.FormatInt(time.Now().UnixNano(), 36),
+		subs:  make(map[*subscriber]struct{}),
+	}
+}
+
+// publish returns the event with its ID set.
+func (b *bus) publish(event model.Event) model.Event {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.seq++
+	event.ID = fmt.Sprintf("%s-%d", b.epoch, b.seq)
+	event.At = time.Now()
+
+	b.history = append(b.history, event)
+	if len(b.history) > eventHistory {
+		b.evicted = b.seq - eventHistory
+		b.history = b.history[1:]
+	}
+
+	for sub := range b.subs {
+		if !event.VisibleTo(sub.userID) {
+			continue
+		}
+		select {
+		case sub.ch <- event:
+		default:
+			// too slow, it resumes from its last event after reconnecting
+			delete(b.subs, sub)
+			close(sub.ch)
+		}
+	}
+
+	return event
+}
+
+// subscribe registers the subscriber and queues the events it missed after lastEventID.
+func (b *bus) subscribe(userID, lastEventID string) (*subscriber, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	var missed []model.Event
+	if lastEventID != "" {
+		last, ok := b.seqOf(lastEventID)
+		if !ok || last > b.seq || last < b.evicted {
+			return nil, todo.ErrEventsExpired
+		}
+
+		// history holds the events evicted+1..seq
+		for _, event := range b.history[last-b.evicted:] {
+			if event.VisibleTo(userID) {
+				missed = append(missed, event)
+			}
+		}
+	}
+
+	sub := &subscriber{userID: userID, ch: make(chan model.Event, len(missed)+subscriberBuffer)}
+	for _, event := range missed {
+		sub.ch <- event
+	}
+	b.subs[sub] = struct{}{}
+
+	return sub, nil
+}
+
+func (b *bus) unsubscribe(sub *subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if _, ok := b.subs[sub]; ok {
+		delete(b.subs, sub)
+		close(sub.ch)
+	}
+}
+
+// seqOf returns the sequence number of the event ID issued by this bus.
+func (b *bus) seqOf(id string) (uint64, bool) {
+	prefix := b.epoch + "-"
+	if !strings.HasPrefix(id, prefix) {
+		return 0, false
+	}
+
+	seq, err := strconv.ParseUint(strings.TrimPrefix(id, prefix), 10, 64)
+	return seq, err == nil
+}
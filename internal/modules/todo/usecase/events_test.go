@@ -0,0 +1,113 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/app/repository/sql/sqltest"
+	authPostgres "github.com/silverspase/todo/internal/modules/auth/repository/postgres"
+	"github.com/silverspase/todo/internal/modules/list"
+	listModel "github.com/silverspase/todo/internal/modules/list/model"
+	listPostgres "github.com/silverspase/todo/internal/modules/list/repository/postgres"
+	listUseCase "github.com/silverspase/todo/internal/modules/list/usecase"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	todoPostgres "github.com/silverspase/todo/internal/modules/todo/repository/postgres"
+	"github.com/silverspase/todo/internal/modules/todo/usecase"
+)
+
+func TestDeleteListEvents(t *testing.T) {
+	m := newModules()
+	owner := m.signUp(t, "owner")
+
+	listID, err := m.lists.CreateList(owner, listModel.List{Name: "Chores"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	want := make(map[string]bool)
+	for _, title := range []string{"Dishes", "Laundry"} {
+		id, err := m.items.CreateItem(owner, model.Item{Title: title, ListID: listID})
+		if err != nil {
+			t.Fatalf("CreateItem: %v", err)
+		}
+		want[id] = true
+	}
+
+	ctx, cancel := context.WithCancel(owner)
+	defer cancel()
+	events, err := m.items.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	if _, err = m.lists.DeleteList(owner, listID, true); err != nil {
+		t.Fatalf("DeleteList: %v", err)
+	}
+	for len(want) > 0 {
+		select {
+		case event := <-events:
+			if event.Type != model.EventDeleted || !want[event.ItemID] {
+				t.Fatalf("got %s of %s, want the deletion of %v", event.Type, event.ItemID, want)
+			}
+			delete(want, event.ItemID)
+		case <-time.After(time.Second):
+			t.Fatalf("no deletion of %v", want)
+		}
+	}
+}
+
+// failingLists fails to delete the lists after their items were deleted.
+type failingLists struct {
+	list.Repository
+}
+
+func (failingLists) DeleteList(ctx context.Context, ownerID, id string) (string, error) {
+	return "", errors.New("list not deleted")
+}
+
+func TestDeleteListRollback(t *testing.T) {
+	logger := zap.NewNop()
+	conn := sqltest.Databases()[0].Open(t)
+	items := todoPostgres.NewRepository(conn, logger)
+	lists := listPostgres.NewRepository(conn, logger)
+	users := authPostgres.NewRepository(conn, logger)
+	itemCase := usecase.NewItemUseCase(logger, items, lists, users, todo.RefuseCompletion, 0)
+	m := modules{
+		items: itemCase,
+		lists: listUseCase.NewListUseCase(logger, failingLists{lists}, items, itemCase, users),
+		users: users,
+	}
+	owner := m.signUp(t, "owner")
+
+	listID, err := m.lists.CreateList(owner, listModel.List{Name: "Chores"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	id, err := m.items.CreateItem(owner, model.Item{Title: "Dishes", ListID: listID})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(owner)
+	defer cancel()
+	events, err := m.items.Subscribe(ctx, "")
+	if err != nil {
+		t.Fatalf("Subscribe: %v", err)
+	}
+
+	if _, err = m.lists.DeleteList(owner, listID, true); err == nil {
+		t.Fatal("DeleteList succeeded")
+	}
+	if _, err = m.items.GetItem(owner, id); err != nil {
+		t.Errorf("GetItem after the rollback: %v", err)
+	}
+	select {
+	case event := <-events:
+		t.Errorf("got %s of %s after the rollback", event.Type, event.ItemID)
+	default:
+	}
+}
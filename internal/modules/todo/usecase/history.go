@@ -0,0 +1,63 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (i itemUseCase) GetHistory(ctx context.Context, id string) ([]model.Revision, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.repo.GetRevisions(ctx, user.ID, id)
+}
+
+func (i itemUseCase) GetRevision(ctx context.Context, id string, rev int64) (model.Revision, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return model.Revision{}, err
+	}
+
+	return i.repo.GetRevision(ctx, user.ID, id, rev)
+}
+
+func (i itemUseCase) RevertItem(ctx context.Context, id string, rev, version int64) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	revision, err := i.repo.GetRevision(ctx, user.ID, id, rev)
+	if err != nil {
+		return "", err
+	}
+	var old model.Item
+	if err = json.Unmarshal(revision.Item, &old); err != nil {
+		return "", err
+	}
+
+	current, err := i.repo.GetItem(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+	if version != 0 && version != current.Version {
+		return "", todo.ErrVersionMismatch
+	}
+
+	// the fields a PUT replaces are taken from the revision, the item stays where it is
+	current.Title = old.Title
+	current.Description = old.Description
+	current.DueAt = old.DueAt
+	current.Priority = old.Priority
+	current.Tags = old.Tags
+	current.Recurrence = old.Recurrence
+	current.Completed = old.Completed
+
+	return i.UpdateItem(ctx, current)
+}
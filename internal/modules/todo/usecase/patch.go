@@ -0,0 +1,65 @@
+package usecase
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/silverspase/todo/internal/jsonpatch"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// readOnlyFields are the members of the item's JSON a patch must leave as they are.
+// The list and the parent are changed by MoveItem only, the rest is maintained by the use case.
+var readOnlyFields = []string{
+	"id", "owner_id", "list_id", "parent_id", "completed_at", "series_start", "version", "created_at", "updated_at",
+}
+
+func (i itemUseCase) PatchItem(ctx context.Context, id string, patch jsonpatch.Patch, version int64) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	current, err := i.repo.GetItem(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+	if version != 0 && version != current.Version {
+		return "", todo.ErrVersionMismatch
+	}
+
+	doc, err := json.Marshal(current)
+	if err != nil {
+		return "", err
+	}
+	patched, err := patch.Apply(doc)
+	if err != nil {
+		return "", err
+	}
+
+	changed, err := jsonpatch.Changed(doc, patched, readOnlyFields...)
+	if err != nil {
+		return "", err
+	}
+	var v validate.Validator
+	for _, field := range changed {
+		v.Add(field, validate.CodeReadOnly, field+" is read-only")
+	}
+	if err = v.Err(todo.ErrInvalidItem); err != nil {
+		return "", err
+	}
+
+	var item model.Item
+	if err = validate.Unmarshal(patched, &item, todo.ErrInvalidItem); err != nil {
+		return "", err
+	}
+
+	// the patched item replaces the version it was made of, as a PUT conditional on it would
+	item.ID = current.ID
+	item.Version = current.Version
+
+	return i.UpdateItem(ctx, item)
+}
@@ -0,0 +1,130 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/rrule"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+const (
+	defaultOccurrences = 5
+	maxOccurrences     = 100
+)
+
+func (i itemUseCase) GetOccurrences(ctx context.Context, id string, n int) ([]time.Time, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case n == 0:
+		n = defaultOccurrences
+	case n < 0 || n > maxOccurrences:
+		return nil, fmt.Errorf("%w: n must be between 1 and %d", todo.ErrInvalidQuery, maxOccurrences)
+	}
+
+	item, err := i.repo.GetItem(ctx, user.ID, id)
+	if err != nil {
+		return nil, err
+	}
+	if item.Recurrence == "" {
+		return nil, fmt.Errorf("%w: item is not recurring", todo.ErrInvalidQuery)
+	}
+
+	rule, err := rrule.Parse(item.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	res := rule.After(*item.SeriesStart, *item.DueAt, n)
+	if res == nil {
+		res = []time.Time{}
+	}
+
+	return res, nil
+}
+
+// validateRecurrence checks the rule of the item and brings it to the canonical form.
+func validateRecurrence(v *validate.Validator, item *model.Item) {
+	if item.Recurrence == "" {
+		return
+	}
+
+	rule, err := rrule.Parse(item.Recurrence)
+	if err != nil {
+		v.Add("recurrence", validate.CodeFormat, err.Error())
+		return
+	}
+	v.Check("due_at", item.DueAt != nil, validate.CodeRequired, "recurring item requires due_at")
+	item.Recurrence = rule.String()
+}
+
+// nextOccurrence returns the occurrence following the item, nil when the item
+// doesn't recur or its series is over.
+func nextOccurrence(item model.Item) (*model.Item, error) {
+	if item.Recurrence == "" {
+		return nil, nil
+	}
+
+	rule, err := rrule.Parse(item.Recurrence)
+	if err != nil {
+		return nil, err
+	}
+
+	due, ok := rule.Next(*item.SeriesStart, *item.DueAt)
+	if !ok {
+		return nil, nil
+	}
+
+	return &model.Item{
+		OwnerID:     item.OwnerID,
+		ListID:      item.ListID,
+		ParentID:    item.ParentID,
+		Title:       item.Title,
+		Description: item.Description,
+		DueAt:       &due,
+		Priority:    item.Priority,
+		Tags:        item.Tags,
+		Recurrence:  item.Recurrence,
+		SeriesStart: item.SeriesStart,
+	}, nil
+}
+
+// completeOccurrence stores the completed item and spawns the next occurrence of its series.
+// The rule moves to the new occurrence, so reopening and completing the item again
+// doesn't spawn a second one.
+func (i itemUseCase) completeOccurrence(ctx context.Context, item model.Item) (string, error) {
+	next, err := nextOccurrence(item)
+	if err != nil {
+		return "", err
+	}
+
+	item.Recurrence = ""
+	item.SeriesStart = nil
+	if _, err = i.repo.UpdateItem(ctx, item); err != nil {
+		return "", err
+	}
+	i.publish(ctx, model.EventCompleted, item)
+	if next == nil {
+		return item.ID, nil
+	}
+
+	id, err := i.repo.CreateItem(ctx, *next)
+	if err != nil {
+		return "", err
+	}
+	i.logger.Debug("spawned next occurrence", zap.String("id", item.ID), zap.String("next", id))
+	next.ID = id
+	i.publish(ctx, model.EventCreated, *next)
+
+	return item.ID, nil
+}
@@ -0,0 +1,103 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+func (i itemUseCase) GetItemGrants(ctx context.Context, id string) ([]model.Grant, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := i.getOwnItem(ctx, user.ID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	grants, err := i.repo.GetGrants(ctx, item.ID, item.ListID)
+	if err != nil {
+		return nil, err
+	}
+	if grants == nil {
+		grants = []model.Grant{}
+	}
+
+	return grants, nil
+}
+
+func (i itemUseCase) ShareItem(ctx context.Context, id, userID string, access model.Access) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.getOwnItem(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+	if userID, err = todo.Grantee(ctx, i.users, userID); err != nil {
+		return "", err
+	}
+
+	grant := model.Grant{ItemID: id, UserID: userID, Access: access}
+	if err = todo.ValidateGrant(ctx, i.users, user.ID, grant); err != nil {
+		return "", err
+	}
+	if err = i.repo.SaveGrant(ctx, grant); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+func (i itemUseCase) UnshareItem(ctx context.Context, id, userID string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.getOwnItem(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+	if userID, err = todo.Grantee(ctx, i.users, userID); err != nil {
+		return "", err
+	}
+	if err = i.repo.DeleteGrant(ctx, model.Grant{ItemID: id, UserID: userID}); err != nil {
+		return "", err
+	}
+
+	return id, nil
+}
+
+// getItem returns the item of the user, or the one shared with them with the access.
+// A lesser access is ErrForbidden.
+func (i itemUseCase) getItem(ctx context.Context, userID, id string, access model.Access) (model.Item, error) {
+	item, err := i.repo.GetItem(ctx, userID, id)
+	if err != nil || item.OwnerID == userID {
+		return item, err
+	}
+
+	granted, err := i.repo.GetAccess(ctx, userID, item)
+	if err != nil {
+		return model.Item{}, err
+	}
+	if !granted.Allows(access) {
+		return model.Item{}, todo.ErrForbidden
+	}
+
+	return item, nil
+}
+
+// getOwnItem returns the item of the user, the ones shared with them are ErrForbidden.
+func (i itemUseCase) getOwnItem(ctx context.Context, userID, id string) (model.Item, error) {
+	item, err := i.repo.GetItem(ctx, userID, id)
+	if err == nil && item.OwnerID != userID {
+		return model.Item{}, todo.ErrForbidden
+	}
+
+	return item, err
+}
@@ -0,0 +1,240 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	authModel "github.com/silverspase/todo/internal/modules/auth/model"
+	authMemory "github.com/silverspase/todo/internal/modules/auth/repository/memory"
+	"github.com/silverspase/todo/internal/modules/list"
+	listModel "github.com/silverspase/todo/internal/modules/list/model"
+	listMemory "github.com/silverspase/todo/internal/modules/list/repository/memory"
+	listUseCase "github.com/silverspase/todo/internal/modules/list/usecase"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	todoMemory "github.com/silverspase/todo/internal/modules/todo/repository/memory"
+	"github.com/silverspase/todo/internal/modules/todo/usecase"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// modules are the use cases of the items and the lists sharing the memory repositories.
+type modules struct {
+	items todo.UseCase
+	lists list.UseCase
+	users auth.Repository
+}
+
+func newModules() modules {
+	logger := zap.NewNop()
+	items := todoMemory.NewMemoryStorage(logger)
+	lists := listMemory.NewMemoryStorage(logger)
+	users := authMemory.NewMemoryStorage(logger)
+
+	itemCase := usecase.NewItemUseCase(logger, items, lists, users, todo.RefuseCompletion, 0)
+
+	return modules{
+		items: itemCase,
+		lists: listUseCase.NewListUseCase(logger, lists, items, itemCase, users),
+		users: users,
+	}
+}
+
+// signUp returns the context of a new member.
+func (m modules) signUp(t *testing.T, name string) context.Context {
+	t.Helper()
+
+	user := authModel.User{Name: name, Email: name + "@example.com", Role: authModel.RoleMember}
+	id, err := m.users.CreateUser(context.Background(), user)
+	if err != nil {
+		t.Fatalf("CreateUser: %v", err)
+	}
+	user.ID = id
+
+	return auth.NewContext(context.Background(), user)
+}
+
+func userID(ctx context.Context) string {
+	user, _ := auth.FromContext(ctx)
+	return user.ID
+}
+
+// code returns the validation code of the field, empty when the error has none.
+func code(err error, field string) string {
+	var invalid *validate.Errors
+	if errors.As(err, &invalid) {
+		for _, f := range invalid.Fields {
+			if f.Field == field {
+				return f.Code
+			}
+		}
+	}
+
+	return ""
+}
+
+func TestSharedList(t *testing.T) {
+	m := newModules()
+	owner, viewer := m.signUp(t, "owner"), m.signUp(t, "viewer")
+	editor, stranger := m.signUp(t, "editor"), m.signUp(t, "stranger")
+
+	listID, err := m.lists.CreateList(owner, listModel.List{Name: "Groceries"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	if _, err = m.lists.ShareList(owner, listID, userID(viewer), model.AccessView); err != nil {
+		t.Fatalf("ShareList(view): %v", err)
+	}
+	if _, err = m.lists.ShareList(owner, listID, userID(editor), model.AccessEdit); err != nil {
+		t.Fatalf("ShareList(edit): %v", err)
+	}
+
+	for name, ctx := range map[string]context.Context{"viewer": viewer, "editor": editor} {
+		lists, err := m.lists.GetAllLists(ctx)
+		if err != nil || len(lists) != 1 || lists[0].ID != listID {
+			t.Errorf("GetAllLists of the %s: got %+v, %v, want the shared list", name, lists, err)
+		}
+		if got, err := m.lists.GetList(ctx, listID); err != nil || got.OwnerID != userID(owner) {
+			t.Errorf("GetList of the %s: got %+v, %v, want the owner's list", name, got, err)
+		}
+	}
+	if lists, err := m.lists.GetAllLists(stranger); err != nil || len(lists) != 0 {
+		t.Errorf("GetAllLists of a stranger: got %+v, %v, want none", lists, err)
+	}
+	if _, err = m.lists.GetList(stranger, listID); !errors.Is(err, list.ErrNotFound) {
+		t.Errorf("GetList of a stranger: got %v, want %v", err, list.ErrNotFound)
+	}
+
+	// the edit access adds items, which are the owner's
+	id, err := m.items.CreateItem(editor, model.Item{Title: "Milk", ListID: listID})
+	if err != nil {
+		t.Fatalf("CreateItem of the editor: %v", err)
+	}
+	item, err := m.items.GetItem(owner, id)
+	if err != nil || item.OwnerID != userID(owner) {
+		t.Errorf("GetItem of the owner: got %+v, %v, want the owner's item", item, err)
+	}
+	if _, err = m.items.CreateItem(editor, model.Item{Title: "Skimmed", ListID: listID, ParentID: id}); err != nil {
+		t.Errorf("CreateItem of a subtask by the editor: %v", err)
+	}
+	if _, err = m.items.GetItem(viewer, id); err != nil {
+		t.Errorf("GetItem of the viewer: %v", err)
+	}
+
+	_, err = m.items.CreateItem(viewer, model.Item{Title: "Bread", ListID: listID})
+	if !errors.Is(err, todo.ErrInvalidItem) || code(err, "list_id") != validate.CodeNotAllowed {
+		t.Errorf("CreateItem of the viewer: got %v, want list_id %s", err, validate.CodeNotAllowed)
+	}
+	_, err = m.items.CreateItem(stranger, model.Item{Title: "Bread", ListID: listID})
+	if !errors.Is(err, todo.ErrInvalidItem) || code(err, "list_id") != validate.CodeNotFound {
+		t.Errorf("CreateItem of a stranger: got %v, want list_id %s", err, validate.CodeNotFound)
+	}
+
+	// the editor's own items stay in their lists
+	ownList, err := m.lists.CreateList(editor, listModel.List{Name: "Chores"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	ownID, err := m.items.CreateItem(editor, model.Item{Title: "Dishes", ListID: ownList})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	_, err = m.items.MoveItem(editor, ownID, listID, "")
+	if !errors.Is(err, todo.ErrInvalidItem) || code(err, "list_id") != validate.CodeNotAllowed {
+		t.Errorf("MoveItem to the shared list: got %v, want list_id %s", err, validate.CodeNotAllowed)
+	}
+
+	// revoking the list hides it
+	if _, err = m.lists.UnshareList(owner, listID, userID(viewer)); err != nil {
+		t.Fatalf("UnshareList: %v", err)
+	}
+	if _, err = m.lists.GetList(viewer, listID); !errors.Is(err, list.ErrNotFound) {
+		t.Errorf("GetList after revoking: got %v, want %v", err, list.ErrNotFound)
+	}
+}
+
+func TestShareByEmail(t *testing.T) {
+	m := newModules()
+	owner, viewer := m.signUp(t, "owner"), m.signUp(t, "viewer")
+
+	listID, err := m.lists.CreateList(owner, listModel.List{Name: "Groceries"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	id, err := m.items.CreateItem(owner, model.Item{Title: "Milk", ListID: listID})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	if _, err = m.items.ShareItem(owner, id, "Viewer@example.com", model.AccessView); err != nil {
+		t.Fatalf("ShareItem: %v", err)
+	}
+	grants, err := m.items.GetItemGrants(owner, id)
+	if err != nil || len(grants) != 1 || grants[0].UserID != userID(viewer) {
+		t.Errorf("GetItemGrants: got %+v, %v, want the viewer's grant", grants, err)
+	}
+	if _, err = m.items.GetItem(viewer, id); err != nil {
+		t.Errorf("GetItem of the viewer: %v", err)
+	}
+
+	_, err = m.items.ShareItem(owner, id, "nobody@example.com", model.AccessView)
+	if !errors.Is(err, todo.ErrInvalidGrant) || code(err, "user_id") != validate.CodeNotFound {
+		t.Errorf("ShareItem with an unknown email: got %v, want user_id %s", err, validate.CodeNotFound)
+	}
+
+	if _, err = m.items.UnshareItem(owner, id, "viewer@example.com"); err != nil {
+		t.Fatalf("UnshareItem: %v", err)
+	}
+	if _, err = m.items.GetItem(viewer, id); !errors.Is(err, todo.ErrNotFound) {
+		t.Errorf("GetItem after revoking: got %v, want %v", err, todo.ErrNotFound)
+	}
+}
+
+func TestSharedItemEvents(t *testing.T) {
+	m := newModules()
+	owner, viewer, stranger := m.signUp(t, "owner"), m.signUp(t, "viewer"), m.signUp(t, "stranger")
+
+	subscribe := func(ctx context.Context) <-chan model.Event {
+		ctx, cancel := context.WithCancel(ctx)
+		t.Cleanup(cancel)
+
+		events, err := m.items.Subscribe(ctx, "")
+		if err != nil {
+			t.Fatalf("Subscribe: %v", err)
+		}
+		return events
+	}
+	viewerEvents, strangerEvents := subscribe(viewer), subscribe(stranger)
+
+	listID, err := m.lists.CreateList(owner, listModel.List{Name: "Groceries"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	id, err := m.items.CreateItem(owner, model.Item{Title: "Milk", ListID: listID})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	if _, err = m.items.ShareItem(owner, id, userID(viewer), model.AccessView); err != nil {
+		t.Fatalf("ShareItem: %v", err)
+	}
+	if _, err = m.items.UpdateItem(owner, model.Item{ID: id, Title: "Oat milk"}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+
+	select {
+	case event := <-viewerEvents:
+		if event.ItemID != id || event.Type != model.EventUpdated {
+			t.Errorf("got %s of %s, want the update of the shared item", event.Type, event.ItemID)
+		}
+	case <-time.After(time.Second):
+		t.Error("the viewer got no event of the shared item")
+	}
+	select {
+	case event := <-strangerEvents:
+		t.Errorf("a stranger got %s of %s", event.Type, event.ItemID)
+	default:
+	}
+}
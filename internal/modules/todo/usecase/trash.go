@@ -0,0 +1,113 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/list"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// retentionInterval is how often the trash is checked for the items past the retention.
+const retentionInterval = time.Hour
+
+func (i itemUseCase) GetTrash(ctx context.Context) ([]model.TrashedItem, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := i.repo.GetTrash(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]model.TrashedItem, 0, len(items))
+	for _, item := range items {
+		res = append(res, model.TrashedItem{Item: item, DeletedAt: item.DeletedAt.Time})
+	}
+
+	return res, nil
+}
+
+func (i itemUseCase) RestoreItem(ctx context.Context, id string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	item, err := i.repo.GetTrashedItem(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+	// the item goes back where it was
+	if item.ParentID != "" {
+		_, err = i.repo.GetItem(ctx, user.ID, item.ParentID)
+		if errors.Is(err, todo.ErrNotFound) {
+			return "", todo.ErrParentDeleted
+		}
+		if err != nil {
+			return "", err
+		}
+	}
+	_, err = i.lists.GetList(ctx, user.ID, item.ListID)
+	if errors.Is(err, list.ErrNotFound) {
+		return "", todo.ErrListDeleted
+	}
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.repo.RestoreItem(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+
+	subtasks, err := i.repo.GetSubtasks(ctx, user.ID, id)
+	if err != nil {
+		i.logger.Error("unable to load the restored subtasks", zap.String("id", id), zap.Error(err))
+	}
+	for _, restored := range append([]model.Item{item}, subtasks...) {
+		i.publish(ctx, model.EventRestored, restored)
+	}
+
+	return id, nil
+}
+
+func (i itemUseCase) PurgeItem(ctx context.Context, id string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	return i.repo.PurgeItem(ctx, user.ID, id)
+}
+
+func (i itemUseCase) RunRetention(ctx context.Context) {
+	if i.retention <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(retentionInterval)
+	defer ticker.Stop()
+
+	for {
+		n, err := i.repo.PurgeTrash(ctx, time.Now().Add(-i.retention))
+		switch {
+		case err != nil && ctx.Err() == nil:
+			i.logger.Error("unable to purge the trash", zap.Error(err))
+		case n > 0:
+			i.logger.Info("purged the trash", zap.Int64("items", n))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
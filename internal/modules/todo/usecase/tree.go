@@ -0,0 +1,139 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+func (i itemUseCase) GetItemTree(ctx context.Context, id string) (model.Node, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return model.Node{}, err
+	}
+
+	item, err := i.repo.GetItem(ctx, user.ID, id)
+	if err != nil {
+		return model.Node{}, err
+	}
+
+	subtasks, err := i.repo.GetSubtasks(ctx, user.ID, id)
+	if err != nil {
+		return model.Node{}, err
+	}
+
+	return model.NewTree(item, subtasks), nil
+}
+
+func (i itemUseCase) MoveItem(ctx context.Context, id, listID, parentID string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	item, err := i.getOwnItem(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+
+	if parentID != "" {
+		parent, err := i.getParent(ctx, user.ID, parentID)
+		if err != nil {
+			return "", err
+		}
+		if listID != "" && listID != parent.ListID {
+			return "", validate.Fail(todo.ErrInvalidItem, "list_id", validate.CodeInvalid,
+				"subtask must be in the list of its parent")
+		}
+		listID = parent.ListID
+	}
+	ownerID, err := i.checkList(ctx, user.ID, listID)
+	if err != nil {
+		return "", err
+	}
+	if ownerID != user.ID {
+		return "", validate.Fail(todo.ErrInvalidItem, "list_id", validate.CodeNotAllowed,
+			"items are moved to the lists of their owner only")
+	}
+
+	subtasks, err := i.repo.GetSubtasks(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+	for _, subtask := range append(subtasks, item) {
+		if subtask.ID == parentID {
+			return "", validate.Fail(todo.ErrInvalidItem, "parent_id", validate.CodeInvalid,
+				"item can't be moved under itself or its subtask")
+		}
+	}
+
+	item.ListID = listID
+	item.ParentID = parentID
+	if _, err = i.repo.UpdateItem(ctx, item); err != nil {
+		return "", err
+	}
+	i.publish(ctx, model.EventUpdated, item)
+
+	// subtasks always share the list of their root
+	for _, subtask := range subtasks {
+		if subtask.ListID == listID {
+			continue
+		}
+		subtask.ListID = listID
+		if _, err = i.repo.UpdateItem(ctx, subtask); err != nil {
+			return "", err
+		}
+		i.publish(ctx, model.EventUpdated, subtask)
+	}
+
+	return item.ID, nil
+}
+
+// openSubtasks applies the completion policy to the item being completed, it returns the open
+// subtasks to complete once the item is stored.
+func (i itemUseCase) openSubtasks(ctx context.Context, item model.Item) ([]model.Item, error) {
+	subtasks, err := i.repo.GetSubtasks(ctx, item.OwnerID, item.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	open := model.Open(subtasks)
+	if len(open) > 0 && i.completion != todo.CascadeCompletion {
+		return nil, fmt.Errorf("%w: %d of %d are not completed", todo.ErrOpenSubtasks, len(open), len(subtasks))
+	}
+
+	return open, nil
+}
+
+// completeSubtasks completes the open subtasks of the stored item, so a rejected update
+// of the item leaves them as they were.
+func (i itemUseCase) completeSubtasks(ctx context.Context, open []model.Item) error {
+	now := time.Now()
+	for _, subtask := range open {
+		subtask.Completed = true
+		subtask.CompletedAt = &now
+		if _, err := i.repo.UpdateItem(ctx, subtask); err != nil {
+			return err
+		}
+		i.publish(ctx, model.EventCompleted, subtask)
+	}
+
+	return nil
+}
+
+// getParent returns the parent item of a subtask, which the user can edit.
+func (i itemUseCase) getParent(ctx context.Context, userID, id string) (model.Item, error) {
+	parent, err := i.getItem(ctx, userID, id, model.AccessEdit)
+	if errors.Is(err, todo.ErrNotFound) || errors.Is(err, todo.ErrForbidden) {
+		return parent, validate.Fail(todo.ErrInvalidItem, "parent_id", validate.CodeNotFound,
+			fmt.Sprintf("parent item %v not found", id))
+	}
+
+	return parent, err
+}
@@ -0,0 +1,76 @@
+package usecase_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"go.uber.org/zap"
+
+	authMemory "github.com/silverspase/todo/internal/modules/auth/repository/memory"
+	listModel "github.com/silverspase/todo/internal/modules/list/model"
+	listMemory "github.com/silverspase/todo/internal/modules/list/repository/memory"
+	listUseCase "github.com/silverspase/todo/internal/modules/list/usecase"
+	"github.com/silverspase/todo/internal/modules/todo"
+	"github.com/silverspase/todo/internal/modules/todo/model"
+	todoMemory "github.com/silverspase/todo/internal/modules/todo/repository/memory"
+	"github.com/silverspase/todo/internal/modules/todo/usecase"
+)
+
+// conflicting loses the race of updating the item to a concurrent request.
+type conflicting struct {
+	todo.Repository
+	id string
+}
+
+func (c *conflicting) UpdateItem(ctx context.Context, item model.Item) (string, error) {
+	if item.ID == c.id {
+		return "", todo.ErrVersionMismatch
+	}
+
+	return c.Repository.UpdateItem(ctx, item)
+}
+
+func TestCascadeAfterConflict(t *testing.T) {
+	logger := zap.NewNop()
+	repo := &conflicting{Repository: todoMemory.NewMemoryStorage(logger)}
+	lists := listMemory.NewMemoryStorage(logger)
+	users := authMemory.NewMemoryStorage(logger)
+	itemCase := usecase.NewItemUseCase(logger, repo, lists, users, todo.CascadeCompletion, 0)
+	m := modules{
+		items: itemCase,
+		lists: listUseCase.NewListUseCase(logger, lists, repo, itemCase, users),
+		users: users,
+	}
+	owner := m.signUp(t, "owner")
+
+	listID, err := m.lists.CreateList(owner, listModel.List{Name: "Chores"})
+	if err != nil {
+		t.Fatalf("CreateList: %v", err)
+	}
+	parentID, err := m.items.CreateItem(owner, model.Item{Title: "Clean", ListID: listID})
+	if err != nil {
+		t.Fatalf("CreateItem: %v", err)
+	}
+	subtaskID, err := m.items.CreateItem(owner, model.Item{Title: "Dishes", ListID: listID, ParentID: parentID})
+	if err != nil {
+		t.Fatalf("CreateItem of the subtask: %v", err)
+	}
+
+	repo.id = parentID
+	_, err = m.items.UpdateItem(owner, model.Item{ID: parentID, Title: "Clean", Completed: true})
+	if !errors.Is(err, todo.ErrVersionMismatch) {
+		t.Fatalf("UpdateItem: got %v, want %v", err, todo.ErrVersionMismatch)
+	}
+	if subtask, err := m.items.GetItem(owner, subtaskID); err != nil || subtask.Completed {
+		t.Errorf("GetItem of the subtask: got %+v, %v, want it open", subtask, err)
+	}
+
+	repo.id = ""
+	if _, err = m.items.UpdateItem(owner, model.Item{ID: parentID, Title: "Clean", Completed: true}); err != nil {
+		t.Fatalf("UpdateItem: %v", err)
+	}
+	if subtask, err := m.items.GetItem(owner, subtaskID); err != nil || !subtask.Completed {
+		t.Errorf("GetItem of the subtask: got %+v, %v, want it completed", subtask, err)
+	}
+}
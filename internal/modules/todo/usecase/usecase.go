@@ -2,41 +2,398 @@ package usecase
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
 
 	"go.uber.org/zap"
 
+	"github.com/silverspase/todo/internal/cursor"
+	"github.com/silverspase/todo/internal/modules/auth"
+	"github.com/silverspase/todo/internal/modules/list"
 	"github.com/silverspase/todo/internal/modules/todo"
 	"github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/txn"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+const (
+	maxTitleLength       = 255
+	maxDescriptionLength = 4096
+	maxTagLength         = 50
+	maxTagsPerItem       = 20
+
+	defaultPageSize = 20
+	maxPageSize     = 100
 )
 
 type itemUseCase struct {
-	repo   todo.Repository
-	logger *zap.Logger
+	repo       todo.Repository
+	lists      list.Repository
+	users      auth.Repository
+	completion todo.CompletionPolicy
+	// retention is how long the deleted items are kept in the trash, 0 keeps them forever
+	retention time.Duration
+	events    *bus
+	listeners []todo.Listener
+	logger    *zap.Logger
 }
 
-func NewItemUseCase(logger *zap.Logger, repo todo.Repository) todo.UseCase {
+// NewItemUseCase returns the use case which notifies the listeners of every item change.
+// The deleted items are purged after retention, unless it's 0.
+func NewItemUseCase(logger *zap.Logger, repo todo.Repository, lists list.Repository, users auth.Repository,
+	completion todo.CompletionPolicy, retention time.Duration, listeners ...todo.Listener) todo.UseCase {
 	return &itemUseCase{
-		repo:   repo,
-		logger: logger,
+		repo:       repo,
+		lists:      lists,
+		users:      users,
+		completion: completion,
+		retention:  retention,
+		events:     newBus(),
+		listeners:  listeners,
+		logger:     logger,
 	}
 }
 
 func (i itemUseCase) CreateItem(ctx context.Context, item model.Item) (string, error) {
-	return i.repo.CreateItem(ctx, item)
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateItem(&item); err != nil {
+		return "", err
+	}
+	if item.ParentID != "" {
+		parent, err := i.getParent(ctx, user.ID, item.ParentID)
+		if err != nil {
+			return "", err
+		}
+		if item.ListID == "" {
+			item.ListID = parent.ListID
+		}
+		if item.ListID != parent.ListID {
+			return "", validate.Fail(todo.ErrInvalidItem, "list_id", validate.CodeInvalid,
+				"subtask must be in the list of its parent")
+		}
+	}
+	// the items of a shared list are its owner's
+	ownerID, err := i.checkList(ctx, user.ID, item.ListID)
+	if err != nil {
+		return "", err
+	}
+
+	item.OwnerID = ownerID
+	item.SeriesStart = nil
+	if item.Recurrence != "" {
+		item.SeriesStart = item.DueAt
+	}
+	item.CompletedAt = nil
+	if item.Completed {
+		now := time.Now()
+		item.CompletedAt = &now
+	}
+
+	id, err := i.repo.CreateItem(ctx, item)
+	if err != nil {
+		return "", err
+	}
+	item.ID = id
+	i.publish(ctx, model.EventCreated, item)
+
+	return id, nil
 }
 
-func (i itemUseCase) GetAllItems(ctx context.Context, page int) ([]model.Item, error) {
-	return i.repo.GetAllItems(ctx, page)
+func (i itemUseCase) GetAllItems(ctx context.Context, query model.Query) (model.Page, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	query.OwnerID = user.ID
+	if err := normalizeQuery(&query); err != nil {
+		return model.Page{}, err
+	}
+
+	// one extra item tells whether there is a next page
+	pageSize := query.PageSize
+	query.PageSize++
+	items, err := i.repo.GetAllItems(ctx, query)
+	if err != nil {
+		return model.Page{}, err
+	}
+
+	page := model.Page{Items: items}
+	if len(items) > pageSize {
+		page.Items = items[:pageSize]
+		page.NextCursor, err = cursor.Encode(model.CursorOf(page.Items[pageSize-1], query.Sort, query.Desc))
+		if err != nil {
+			return model.Page{}, err
+		}
+	}
+	if page.Items == nil {
+		page.Items = []model.Item{}
+	}
+
+	return page, nil
 }
 
 func (i itemUseCase) GetItem(ctx context.Context, id string) (model.Item, error) {
-	return i.repo.GetItem(ctx, id)
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return model.Item{}, err
+	}
+
+	return i.repo.GetItem(ctx, user.ID, id)
 }
 
 func (i itemUseCase) UpdateItem(ctx context.Context, item model.Item) (string, error) {
-	return i.repo.UpdateItem(ctx, item)
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateItem(&item); err != nil {
+		return "", err
+	}
+
+	current, err := i.getItem(ctx, user.ID, item.ID, model.AccessEdit)
+	if err != nil {
+		return "", err
+	}
+	// the repository checks current.Version again, which catches the concurrent updates
+	if item.Version != 0 && item.Version != current.Version {
+		return "", todo.ErrVersionMismatch
+	}
+
+	var open []model.Item
+	if item.Completed && !current.Completed {
+		if open, err = i.openSubtasks(ctx, current); err != nil {
+			return "", err
+		}
+	}
+
+	// PUT replaces every field the client controls, the rest is kept as stored.
+	// The list and the parent are changed by MoveItem only.
+	current.Title = item.Title
+	current.Description = item.Description
+	current.DueAt = item.DueAt
+	current.Priority = item.Priority
+	current.Tags = item.Tags
+	switch {
+	case item.Recurrence == "":
+		current.SeriesStart = nil
+	case item.Recurrence != current.Recurrence || current.SeriesStart == nil:
+		// a new rule starts a new series
+		current.SeriesStart = item.DueAt
+	}
+	current.Recurrence = item.Recurrence
+	completing := item.Completed && !current.Completed
+	switch {
+	case item.Completed && !current.Completed:
+		now := time.Now()
+		current.CompletedAt = &now
+	case !item.Completed:
+		current.CompletedAt = nil
+	}
+	current.Completed = item.Completed
+
+	if completing {
+		id, err := i.completeOccurrence(ctx, current)
+		if err != nil {
+			return "", err
+		}
+		if err = i.completeSubtasks(ctx, open); err != nil {
+			return "", err
+		}
+
+		return id, nil
+	}
+
+	id, err := i.repo.UpdateItem(ctx, current)
+	if err != nil {
+		return "", err
+	}
+	i.publish(ctx, model.EventUpdated, current)
+
+	return id, nil
 }
 
-func (i itemUseCase) DeleteItem(ctx context.Context, id string) (string, error) {
-	return i.repo.DeleteItem(ctx, id)
+func (i itemUseCase) DeleteItem(ctx context.Context, id string, version int64) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.getOwnItem(ctx, user.ID, id); err != nil {
+		return "", err
+	}
+	subtasks, err := i.repo.GetSubtasks(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+
+	if _, err = i.repo.DeleteItem(ctx, user.ID, id, version); err != nil {
+		return "", err
+	}
+	for _, item := range append(subtasks, model.Item{ID: id, OwnerID: user.ID}) {
+		i.publish(ctx, model.EventDeleted, item)
+	}
+
+	return id, nil
+}
+
+func (i itemUseCase) DeleteListItems(ctx context.Context, listID string) error {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return err
+	}
+
+	items, err := i.repo.DeleteListItems(ctx, user.ID, listID)
+	if err != nil {
+		return err
+	}
+	txn.AfterCommit(ctx, func(ctx context.Context) {
+		for _, item := range items {
+			i.publish(ctx, model.EventDeleted, item)
+		}
+	})
+
+	return nil
+}
+
+func (i itemUseCase) GetAllTags(ctx context.Context) ([]model.Tag, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	tags, err := i.repo.GetAllTags(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tags == nil {
+		tags = []model.Tag{}
+	}
+
+	return tags, nil
+}
+
+func (i itemUseCase) RenameTag(ctx context.Context, id, name string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	var v validate.Validator
+	names := normalizeTags(&v, "name", []string{name})
+	if err := v.Err(todo.ErrInvalidItem); err != nil {
+		return "", err
+	}
+
+	return i.repo.RenameTag(ctx, user.ID, id, names[0])
+}
+
+// checkList makes sure the item is put into an existing list of the user, or one shared
+// with them with the edit access, and returns the owner of the list.
+func (i itemUseCase) checkList(ctx context.Context, userID, listID string) (string, error) {
+	if listID == "" {
+		return "", validate.Fail(todo.ErrInvalidItem, "list_id", validate.CodeRequired, "list_id is required")
+	}
+
+	entry, err := list.GetList(ctx, i.lists, i.repo, userID, listID, model.AccessEdit)
+	switch {
+	case errors.Is(err, list.ErrNotFound):
+		return "", validate.Fail(todo.ErrInvalidItem, "list_id", validate.CodeNotFound,
+			fmt.Sprintf("list %v not found", listID))
+	case errors.Is(err, list.ErrForbidden):
+		return "", validate.Fail(todo.ErrInvalidItem, "list_id", validate.CodeNotAllowed,
+			fmt.Sprintf("list %v is shared with you with the view access", listID))
+	case err != nil:
+		return "", err
+	}
+
+	return entry.OwnerID, nil
+}
+
+// validateItem checks the item and normalizes its tags.
+func validateItem(item *model.Item) error {
+	var v validate.Validator
+	v.String("title", item.Title, validate.Required, validate.MaxLength(maxTitleLength))
+	v.String("description", item.Description, validate.MaxLength(maxDescriptionLength))
+	v.Check("priority", item.Priority.Valid(), validate.CodeNotAllowed, "priority is unknown")
+	item.Tags = normalizeTags(&v, "tags", item.Tags)
+	v.Check("tags", len(item.Tags) <= maxTagsPerItem, validate.CodeTooMany,
+		fmt.Sprintf("more than %d tags", maxTagsPerItem))
+	validateRecurrence(&v, item)
+
+	return v.Err(todo.ErrInvalidItem)
+}
+
+// normalizeTags trims and lower-cases the tag names, drops duplicates and sorts them.
+// Invalid names are reported as the field.
+func normalizeTags(v *validate.Validator, field string, names []string) []string {
+	seen := make(map[string]struct{}, len(names))
+	res := make([]string, 0, len(names))
+	for _, name := range names {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			v.Add(field, validate.CodeRequired, "tag name is empty")
+			continue
+		}
+		if utf8.RuneCountInString(name) > maxTagLength {
+			v.Add(field, validate.CodeTooLong, fmt.Sprintf("tag %q is longer than %d characters", name, maxTagLength))
+			continue
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		res = append(res, name)
+	}
+	sort.Strings(res)
+
+	return res
+}
+
+// normalizeQuery validates the query and fills the defaults the repository relies on.
+// The fields are named after the query params.
+func normalizeQuery(q *model.Query) error {
+	var v validate.Validator
+	if q.Sort == "" {
+		q.Sort = model.SortCreated
+	}
+	v.Check("sort", q.Sort.Valid(), validate.CodeNotAllowed, fmt.Sprintf("unknown sort field %q", q.Sort))
+	for _, p := range q.Priorities {
+		v.Check("priority", p.Valid(), validate.CodeNotAllowed, "priority is unknown")
+	}
+	if q.TagMode == "" {
+		q.TagMode = model.TagModeAny
+	}
+	v.String("tag_mode", string(q.TagMode), validate.OneOf(string(model.TagModeAny), string(model.TagModeAll)))
+	if len(q.Tags) > 0 {
+		q.Tags = normalizeTags(&v, "tag", q.Tags)
+	}
+	v.Check("due_after", q.DueBefore == nil || q.DueAfter == nil || q.DueAfter.Before(*q.DueBefore),
+		validate.CodeInvalid, "due_after must be before due_before")
+
+	if q.Cursor != "" {
+		var after model.Cursor
+		if err := cursor.Decode(q.Cursor, &after); err != nil || after.Sort != q.Sort || after.Desc != q.Desc {
+			v.Add("cursor", validate.CodeInvalid, "cursor doesn't match the requested order")
+		} else {
+			q.After = &after
+		}
+	}
+
+	if q.PageSize == 0 {
+		q.PageSize = defaultPageSize
+	}
+	v.Check("page_size", q.PageSize > 0 && q.PageSize <= maxPageSize, validate.CodeInvalid,
+		fmt.Sprintf("page_size must be between 1 and %d", maxPageSize))
+
+	return v.Err(todo.ErrInvalidQuery)
 }
@@ -0,0 +1,14 @@
+package webhook
+
+import "github.com/silverspase/todo/internal/errs"
+
+var (
+	// ErrNotFound is returned when a webhook doesn't exist or belongs to another user.
+	ErrNotFound = errs.New(errs.NotFound, "webhook not found")
+	// ErrDeliveryNotFound is returned when a delivery doesn't exist or belongs to another webhook.
+	ErrDeliveryNotFound = errs.New(errs.NotFound, "delivery not found")
+	// ErrInvalidWebhook is wrapped by the use case validation errors.
+	ErrInvalidWebhook = errs.New(errs.Validation, "invalid webhook")
+	// ErrDeliveryPending is returned when a delivery which is still retried is replayed.
+	ErrDeliveryPending = errs.New(errs.Conflict, "delivery is pending")
+)
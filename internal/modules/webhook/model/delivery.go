@@ -0,0 +1,59 @@
+package model
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+type DeliveryStatus string
+
+const (
+	DeliveryPending   DeliveryStatus = "pending"
+	DeliverySucceeded DeliveryStatus = "succeeded"
+	DeliveryFailed    DeliveryStatus = "failed" // no attempts left
+)
+
+// Delivery is a queued payload of a webhook and the log of its attempts.
+type Delivery struct {
+	ID        string              `json:"id" gorm:"primaryKey"`
+	WebhookID string              `json:"webhook_id" gorm:"index"`
+	OwnerID   string              `json:"-" gorm:"index"`
+	Event     todoModel.EventType `json:"event"`
+	Payload   json.RawMessage     `json:"payload"`
+	Status    DeliveryStatus      `json:"status" gorm:"index"`
+	Attempts  int                 `json:"attempts"`
+	// NextAttemptAt is nil once the delivery succeeded or failed.
+	NextAttemptAt  *time.Time `json:"next_attempt_at,omitempty" gorm:"index"`
+	ResponseStatus int        `json:"response_status,omitempty"` // of the last attempt
+	LastError      string     `json:"last_error,omitempty"`
+	ReplayOf       string     `json:"replay_of,omitempty"` // ID of the replayed delivery
+	// the lease keeps several dispatchers from sending the same delivery
+	LeaseToken string     `json:"-" gorm:"index"`
+	LeaseUntil *time.Time `json:"-"`
+	CreatedAt  time.Time  `json:"created_at"`
+	UpdatedAt  time.Time  `json:"updated_at"`
+}
+
+func (Delivery) TableName() string {
+	return "webhook_deliveries"
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (d *Delivery) BeforeCreate(tx *gorm.DB) error {
+	d.ID = uuid.New().String()
+	return nil
+}
+
+// Payload is the body POSTed to the webhook URL.
+type Payload struct {
+	Event      todoModel.EventType `json:"event"`
+	EventID    string              `json:"event_id"`
+	OccurredAt time.Time           `json:"occurred_at"`
+	ItemID     string              `json:"item_id"`
+	Item       *todoModel.Item     `json:"item,omitempty"` // nil for deleted items
+}
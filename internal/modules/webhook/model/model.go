@@ -0,0 +1,98 @@
+package model
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+)
+
+// Webhook receives the item events of its owner as signed POST requests.
+type Webhook struct {
+	ID      string `json:"id" gorm:"primaryKey"`
+	OwnerID string `json:"owner_id" gorm:"index"`
+	URL     string `json:"url"`
+	// Secret is the HMAC-SHA256 key of the payload signatures, it's only shown on creation.
+	Secret    string         `json:"-"`
+	Events    EventTypes     `json:"events"` // empty for all events
+	Disabled  bool           `json:"disabled"`
+	CreatedAt time.Time      `json:"created_at"`
+	UpdatedAt time.Time      `json:"updated_at"`
+	DeletedAt gorm.DeletedAt `json:"-" sql:"index"`
+}
+
+// BeforeCreate will set a UUID rather than numeric ID.
+func (w *Webhook) BeforeCreate(tx *gorm.DB) error {
+	w.ID = uuid.New().String()
+	return nil
+}
+
+// Subscribed tells whether the webhook receives events of the type.
+func (w Webhook) Subscribed(typ todoModel.EventType) bool {
+	if w.Disabled {
+		return false
+	}
+	if len(w.Events) == 0 {
+		return true
+	}
+	for _, e := range w.Events {
+		if e == typ {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EventTypes is stored as a comma separated list, so every backend keeps it in a text column.
+type EventTypes []todoModel.EventType
+
+func (e EventTypes) Value() (driver.Value, error) {
+	types := make([]string, 0, len(e))
+	for _, typ := range e {
+		types = append(types, string(typ))
+	}
+
+	return strings.Join(types, ","), nil
+}
+
+func (e *EventTypes) Scan(src interface{}) error {
+	var s string
+	switch v := src.(type) {
+	case string:
+		s = v
+	case []byte:
+		s = string(v)
+	case nil:
+	default:
+		return fmt.Errorf("unable to scan %T into event types", src)
+	}
+
+	*e = nil
+	for _, typ := range strings.Split(s, ",") {
+		if typ != "" {
+			*e = append(*e, todoModel.EventType(typ))
+		}
+	}
+
+	return nil
+}
+
+func (EventTypes) GormDataType() string {
+	return "text"
+}
+
+// MarshalJSON keeps an empty list an array rather than null.
+func (e EventTypes) MarshalJSON() ([]byte, error) {
+	if e == nil {
+		return []byte("[]"), nil
+	}
+
+	return json.Marshal([]todoModel.EventType(e))
+}
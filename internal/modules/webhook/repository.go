@@ -0,0 +1,31 @@
+package webhook
+
+import (
+	"context"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+)
+
+// Repository stores webhooks and their delivery queue. Webhook methods are scoped to the owner:
+// webhooks of other users are reported as ErrNotFound.
+type Repository interface {
+	CreateWebhook(ctx context.Context, hook model.Webhook) (string, error)
+	// GetAllWebhooks returns the webhooks ordered by (created_at, id).
+	GetAllWebhooks(ctx context.Context, ownerID string) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, ownerID, id string) (model.Webhook, error)
+	// UpdateWebhook changes the URL, events and disabled flag, the secret is kept.
+	UpdateWebhook(ctx context.Context, hook model.Webhook) (string, error)
+	DeleteWebhook(ctx context.Context, ownerID, id string) (string, error)
+
+	// CreateDeliveries stores the deliveries and sets their IDs.
+	CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error
+	// ClaimDeliveries leases up to limit pending deliveries which are due at now, so no other
+	// dispatcher claims them until the lease expires, and returns them by NextAttemptAt.
+	ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]model.Delivery, error)
+	// UpdateDelivery stores the outcome of an attempt and releases the lease.
+	UpdateDelivery(ctx context.Context, delivery model.Delivery) error
+	// GetDeliveries returns the latest deliveries of the webhook, newest first.
+	GetDeliveries(ctx context.Context, ownerID, webhookID string, limit int) ([]model.Delivery, error)
+	GetDelivery(ctx context.Context, ownerID, id string) (model.Delivery, error)
+}
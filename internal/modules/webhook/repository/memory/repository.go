@@ -0,0 +1,199 @@
+package memory
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+)
+
+type memoryStorage struct {
+	mu         sync.RWMutex
+	hooks      map[string]model.Webhook
+	deliveries map[string]model.Delivery
+	logger     *zap.Logger
+}
+
+func NewMemoryStorage(logger *zap.Logger) webhook.Repository {
+	return &memoryStorage{
+		hooks:      make(map[string]model.Webhook),
+		deliveries: make(map[string]model.Delivery),
+		logger:     logger,
+	}
+}
+
+func (m *memoryStorage) CreateWebhook(ctx context.Context, hook model.Webhook) (string, error) {
+	m.logger.Debug("CreateWebhook")
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	hook.ID = uuid.New().String()
+	hook.CreatedAt = now
+	hook.UpdatedAt = now
+	m.hooks[hook.ID] = hook
+
+	return hook.ID, nil
+}
+
+func (m *memoryStorage) GetAllWebhooks(ctx context.Context, ownerID string) (res []model.Webhook, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, hook := range m.hooks {
+		if hook.OwnerID == ownerID {
+			res = append(res, hook)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.Before(res[j].CreatedAt)
+		}
+		return res[i].ID < res[j].ID
+	})
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetWebhook(ctx context.Context, ownerID, id string) (model.Webhook, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	hook, ok := m.hooks[id]
+	if !ok || hook.OwnerID != ownerID {
+		return model.Webhook{}, webhook.ErrNotFound
+	}
+
+	return hook, nil
+}
+
+func (m *memoryStorage) UpdateWebhook(ctx context.Context, hook model.Webhook) (string, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	current, ok := m.hooks[hook.ID]
+	if !ok || current.OwnerID != hook.OwnerID {
+		return "", webhook.ErrNotFound
+	}
+
+	current.URL = hook.URL
+	current.Events = hook.Events
+	current.Disabled = hook.Disabled
+	current.UpdatedAt = time.Now()
+	m.hooks[hook.ID] = current
+
+	return hook.ID, nil
+}
+
+func (m *memoryStorage) DeleteWebhook(ctx context.Context, ownerID, id string) (string, error) {
+	m.logger.Info("DeleteWebhook", zap.String("id", id))
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	hook, ok := m.hooks[id]
+	if !ok || hook.OwnerID != ownerID {
+		return "", webhook.ErrNotFound
+	}
+	delete(m.hooks, id)
+
+	return id, nil
+}
+
+func (m *memoryStorage) CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	now := time.Now()
+	for i := range deliveries {
+		deliveries[i].ID = uuid.New().String()
+		deliveries[i].CreatedAt = now
+		deliveries[i].UpdatedAt = now
+		m.deliveries[deliveries[i].ID] = deliveries[i]
+	}
+
+	return nil
+}
+
+func (m *memoryStorage) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (res []model.Delivery, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, d := range m.deliveries {
+		if d.Status == model.DeliveryPending && !d.NextAttemptAt.After(now) &&
+			(d.LeaseUntil == nil || d.LeaseUntil.Before(now)) {
+			res = append(res, d)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].NextAttemptAt.Before(*res[j].NextAttemptAt)
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	token := uuid.New().String()
+	until := now.Add(lease)
+	for i := range res {
+		res[i].LeaseToken = token
+		res[i].LeaseUntil = &until
+		m.deliveries[res[i].ID] = res[i]
+	}
+
+	return res, nil
+}
+
+func (m *memoryStorage) UpdateDelivery(ctx context.Context, delivery model.Delivery) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.deliveries[delivery.ID]; !ok {
+		return webhook.ErrDeliveryNotFound
+	}
+
+	delivery.LeaseToken = ""
+	delivery.LeaseUntil = nil
+	delivery.UpdatedAt = time.Now()
+	m.deliveries[delivery.ID] = delivery
+
+	return nil
+}
+
+func (m *memoryStorage) GetDeliveries(ctx context.Context, ownerID, webhookID string, limit int) (res []model.Delivery, err error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, d := range m.deliveries {
+		if d.OwnerID == ownerID && d.WebhookID == webhookID {
+			res = append(res, d)
+		}
+	}
+	sort.Slice(res, func(i, j int) bool {
+		if !res[i].CreatedAt.Equal(res[j].CreatedAt) {
+			return res[i].CreatedAt.After(res[j].CreatedAt)
+		}
+		return res[i].ID > res[j].ID
+	})
+	if len(res) > limit {
+		res = res[:limit]
+	}
+
+	return res, nil
+}
+
+func (m *memoryStorage) GetDelivery(ctx context.Context, ownerID, id string) (model.Delivery, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	d, ok := m.deliveries[id]
+	if !ok || d.OwnerID != ownerID {
+		return model.Delivery{}, webhook.ErrDeliveryNotFound
+	}
+
+	return d, nil
+}
@@ -0,0 +1,151 @@
+package postgres
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"go.uber.org/zap"
+	"gorm.io/gorm"
+
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+)
+
+type postgres struct {
+	conn   *gorm.DB
+	logger *zap.Logger
+}
+
+func NewRepository(conn *gorm.DB, logger *zap.Logger) webhook.Repository {
+	return postgres{
+		conn:   conn,
+		logger: logger,
+	}
+}
+
+func (p postgres) CreateWebhook(ctx context.Context, hook model.Webhook) (string, error) {
+	p.logger.Debug("CreateWebhook")
+
+	if err := p.conn.WithContext(ctx).Create(&hook).Error; err != nil {
+		return "", err
+	}
+
+	return hook.ID, nil
+}
+
+func (p postgres) GetAllWebhooks(ctx context.Context, ownerID string) (hooks []model.Webhook, err error) {
+	p.logger.Debug("GetAllWebhooks")
+
+	err = p.conn.WithContext(ctx).Where("owner_id = ?", ownerID).Order("created_at, id").Find(&hooks).Error
+	return hooks, err
+}
+
+func (p postgres) GetWebhook(ctx context.Context, ownerID, id string) (model.Webhook, error) {
+	p.logger.Debug("GetWebhook", zap.String("id", id))
+
+	var hook model.Webhook
+	err := p.conn.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).First(&hook).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return hook, webhook.ErrNotFound
+	}
+
+	return hook, err
+}
+
+func (p postgres) UpdateWebhook(ctx context.Context, newHook model.Webhook) (string, error) {
+	p.logger.Debug("UpdateWebhook", zap.String("id", newHook.ID))
+
+	hook, err := p.GetWebhook(ctx, newHook.OwnerID, newHook.ID)
+	if err != nil {
+		return "", err
+	}
+
+	hook.URL = newHook.URL
+	hook.Events = newHook.Events
+	hook.Disabled = newHook.Disabled
+	if err = p.conn.WithContext(ctx).Save(&hook).Error; err != nil {
+		return "", err
+	}
+
+	return hook.ID, nil
+}
+
+func (p postgres) DeleteWebhook(ctx context.Context, ownerID, id string) (string, error) {
+	p.logger.Info("DeleteWebhook", zap.String("id", id))
+
+	res := p.conn.WithContext(ctx).Where("owner_id = ?", ownerID).Delete(&model.Webhook{ID: id})
+	if res.Error != nil {
+		return "", res.Error
+	}
+	if res.RowsAffected == 0 {
+		return "", webhook.ErrNotFound
+	}
+
+	return id, nil
+}
+
+func (p postgres) CreateDeliveries(ctx context.Context, deliveries []model.Delivery) error {
+	p.logger.Debug("CreateDeliveries", zap.Int("count", len(deliveries)))
+
+	return p.conn.WithContext(ctx).Create(&deliveries).Error
+}
+
+// claimQuery leases the due deliveries. The conditions are repeated outside of the subquery,
+// so a row claimed by a concurrent dispatcher meanwhile is skipped when the update is rechecked.
+const claimQuery = `UPDATE webhook_deliveries SET lease_token = @token, lease_until = @until
+WHERE status = @pending AND next_attempt_at <= @now AND (lease_until IS NULL OR lease_until < @now)
+AND id IN (
+	SELECT id FROM webhook_deliveries
+	WHERE status = @pending AND next_attempt_at <= @now AND (lease_until IS NULL OR lease_until < @now)
+	ORDER BY next_attempt_at LIMIT @limit
+)`
+
+func (p postgres) ClaimDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) (deliveries []model.Delivery, err error) {
+	db := p.conn.WithContext(ctx)
+	token := uuid.New().String()
+
+	err = db.Exec(claimQuery, map[string]interface{}{
+		"token":   token,
+		"until":   now.Add(lease),
+		"pending": model.DeliveryPending,
+		"now":     now,
+		"limit":   limit,
+	}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = db.Where("lease_token = ?", token).Order("next_attempt_at").Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (p postgres) UpdateDelivery(ctx context.Context, delivery model.Delivery) error {
+	p.logger.Debug("UpdateDelivery", zap.String("id", delivery.ID))
+
+	delivery.LeaseToken = ""
+	delivery.LeaseUntil = nil
+
+	return p.conn.WithContext(ctx).Save(&delivery).Error
+}
+
+func (p postgres) GetDeliveries(ctx context.Context, ownerID, webhookID string, limit int) (deliveries []model.Delivery, err error) {
+	p.logger.Debug("GetDeliveries", zap.String("webhook_id", webhookID))
+
+	err = p.conn.WithContext(ctx).Where("owner_id = ? AND webhook_id = ?", ownerID, webhookID).
+		Order("created_at DESC, id DESC").Limit(limit).Find(&deliveries).Error
+	return deliveries, err
+}
+
+func (p postgres) GetDelivery(ctx context.Context, ownerID, id string) (model.Delivery, error) {
+	p.logger.Debug("GetDelivery", zap.String("id", id))
+
+	var delivery model.Delivery
+	err := p.conn.WithContext(ctx).Where("id = ? AND owner_id = ?", id, ownerID).First(&delivery).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return delivery, webhook.ErrDeliveryNotFound
+	}
+
+	return delivery, err
+}
@@ -0,0 +1,16 @@
+package webhook
+
+import (
+	"net/http"
+)
+
+type Transport interface {
+	CreateWebhook(w http.ResponseWriter, r *http.Request)
+	GetAllWebhooks(w http.ResponseWriter, r *http.Request)
+	GetWebhook(w http.ResponseWriter, r *http.Request)
+	UpdateWebhook(w http.ResponseWriter, r *http.Request)
+	DeleteWebhook(w http.ResponseWriter, r *http.Request)
+
+	GetDeliveries(w http.ResponseWriter, r *http.Request)
+	ReplayDelivery(w http.ResponseWriter, r *http.Request)
+}
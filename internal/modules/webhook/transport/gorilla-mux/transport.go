@@ -0,0 +1,194 @@
+package gorilla_mux
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/gorilla/mux"
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/httpjson"
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+	"github.com/silverspase/todo/internal/problem"
+)
+
+// errors of the requests which don't reach the use case
+var (
+	errInvalidPayload = errs.New(errs.Validation, "invalid request payload")
+	errMissingID      = errs.New(errs.Validation, "missed id path param")
+)
+
+type transport struct {
+	useCase webhook.UseCase
+	logger  *zap.Logger
+}
+
+func NewTransport(logger *zap.Logger, useCase webhook.UseCase) webhook.Transport {
+	return &transport{
+		useCase: useCase,
+		logger:  logger,
+	}
+}
+
+// CreateWebhook responds with the secret of the signatures, it isn't shown afterwards.
+func (t *transport) CreateWebhook(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("CreateWebhook")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	var hook model.Webhook
+	if err := httpjson.Decode(r, &hook, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	hook, err := t.useCase.CreateWebhook(ctx, hook)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusCreated, map[string]string{"status": "created", "id": hook.ID, "secret": hook.Secret})
+}
+
+func (t *transport) GetAllWebhooks(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetAllWebhooks")
+
+	hooks, err := t.useCase.GetAllWebhooks(r.Context())
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, hooks)
+}
+
+func (t *transport) GetWebhook(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetWebhook")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	hook, err := t.useCase.GetWebhook(ctx, id)
+	if err != nil {
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to get webhook with id %v: %w", id, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, hook)
+}
+
+func (t *transport) UpdateWebhook(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("UpdateWebhook")
+	ctx := r.Context()
+	defer r.Body.Close()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var hook model.Webhook
+	if err := httpjson.Decode(r, &hook, errInvalidPayload); err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	hook.ID = id
+	id, err := t.useCase.UpdateWebhook(ctx, hook)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "updated", "id": id})
+}
+
+func (t *transport) DeleteWebhook(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("DeleteWebhook")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	if _, err := t.useCase.DeleteWebhook(ctx, id); err != nil {
+		problem.Write(t.logger, w, r, fmt.Errorf("unable to delete webhook with id %v: %w", id, err))
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, map[string]string{"status": "deleted", "id": id})
+}
+
+// GetDeliveries responds with the delivery log of the webhook, newest first. The limit param
+// is the number of deliveries.
+func (t *transport) GetDeliveries(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("GetDeliveries")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id := params["id"]
+	if id == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	var limit int
+	if s := r.FormValue("limit"); s != "" {
+		var err error
+		if limit, err = strconv.Atoi(s); err != nil {
+			problem.Write(t.logger, w, r, errs.New(errs.Validation, "limit param is not a number"))
+			return
+		}
+	}
+
+	deliveries, err := t.useCase.GetDeliveries(ctx, id, limit)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusOK, deliveries)
+}
+
+func (t *transport) ReplayDelivery(w http.ResponseWriter, r *http.Request) {
+	t.logger.Debug("ReplayDelivery")
+	ctx := r.Context()
+
+	params := mux.Vars(r)
+	id, deliveryID := params["id"], params["delivery_id"]
+	if id == "" || deliveryID == "" {
+		problem.Write(t.logger, w, r, errMissingID)
+		return
+	}
+
+	replayID, err := t.useCase.ReplayDelivery(ctx, id, deliveryID)
+	if err != nil {
+		problem.Write(t.logger, w, r, err)
+		return
+	}
+
+	respondWithJSON(w, http.StatusAccepted, map[string]string{"status": "queued", "id": replayID})
+}
+
+func respondWithJSON(w http.ResponseWriter, code int, payload interface{}) {
+	response, _ := json.Marshal(payload)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	w.Write(response)
+}
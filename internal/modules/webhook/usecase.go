@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"context"
+
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+)
+
+// UseCase operates on the webhooks of the user authenticated in ctx (see auth.NewContext).
+type UseCase interface {
+	// CreateWebhook returns the webhook with its generated secret. The host of the URL must not
+	// resolve to a loopback, link-local or private address, the deliveries are never sent to one.
+	CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error)
+	GetAllWebhooks(ctx context.Context) ([]model.Webhook, error)
+	GetWebhook(ctx context.Context, id string) (model.Webhook, error)
+	UpdateWebhook(ctx context.Context, hook model.Webhook) (string, error)
+	DeleteWebhook(ctx context.Context, id string) (string, error)
+
+	GetDeliveries(ctx context.Context, webhookID string, limit int) ([]model.Delivery, error)
+	// ReplayDelivery queues the payload of a finished delivery again and returns the new delivery ID.
+	ReplayDelivery(ctx context.Context, webhookID, id string) (string, error)
+
+	// ItemChanged queues a delivery for every webhook subscribed to the event of the item
+	// owner and of the users the item is shared with, it makes the use case a todo.Listener.
+	ItemChanged(ctx context.Context, event todoModel.Event) error
+	// Run sends the queued deliveries until ctx is done.
+	Run(ctx context.Context)
+}
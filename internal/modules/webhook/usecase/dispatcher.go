@@ -0,0 +1,157 @@
+package usecase
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+)
+
+const (
+	pollInterval   = time.Second
+	claimBatch     = 10
+	requestTimeout = 10 * time.Second
+	// the lease outlives the attempts of a whole batch
+	leaseDuration = claimBatch * 2 * requestTimeout
+	maxBackoff    = 6 * time.Hour
+)
+
+// The headers of a delivery, the signature is "sha256=" and the hex HMAC-SHA256
+// of the request body keyed with the webhook secret.
+const (
+	eventHeader     = "X-Todo-Event"
+	deliveryHeader  = "X-Todo-Delivery"
+	signatureHeader = "X-Todo-Signature-256"
+)
+
+func (u webhookUseCase) Run(ctx context.Context) {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		u.dispatch(ctx)
+	}
+}
+
+// dispatch sends the deliveries which are due.
+func (u webhookUseCase) dispatch(ctx context.Context) {
+	// a full batch means more deliveries may be due
+	for {
+		deliveries, err := u.repo.ClaimDeliveries(ctx, time.Now(), leaseDuration, claimBatch)
+		if err != nil {
+			if ctx.Err() == nil {
+				u.logger.Error("unable to claim webhook deliveries", zap.Error(err))
+			}
+			return
+		}
+		for _, delivery := range deliveries {
+			u.attempt(ctx, delivery)
+		}
+		if len(deliveries) < claimBatch {
+			return
+		}
+	}
+}
+
+// attempt sends the delivery and schedules the retry when it fails.
+func (u webhookUseCase) attempt(ctx context.Context, delivery model.Delivery) {
+	delivery.Attempts++
+	delivery.ResponseStatus = 0
+	delivery.LastError = ""
+
+	hook, err := u.repo.GetWebhook(ctx, delivery.OwnerID, delivery.WebhookID)
+	switch {
+	case errors.Is(err, webhook.ErrNotFound):
+		err = fmt.Errorf("webhook is deleted")
+		delivery.Attempts = u.maxAttempts
+	case err == nil && hook.Disabled:
+		err = fmt.Errorf("webhook is disabled")
+		delivery.Attempts = u.maxAttempts
+	case err == nil:
+		delivery.ResponseStatus, err = u.send(ctx, hook, delivery)
+	}
+
+	if err == nil {
+		delivery.Status = model.DeliverySucceeded
+		delivery.NextAttemptAt = nil
+	} else {
+		delivery.LastError = err.Error()
+		if delivery.Attempts >= u.maxAttempts {
+			delivery.Status = model.DeliveryFailed
+			delivery.NextAttemptAt = nil
+		} else {
+			next := time.Now().Add(u.retryDelay(delivery.Attempts))
+			delivery.NextAttemptAt = &next
+		}
+	}
+
+	if err := u.repo.UpdateDelivery(ctx, delivery); err != nil {
+		u.logger.Error("unable to store webhook delivery", zap.String("id", delivery.ID), zap.Error(err))
+	}
+}
+
+func (u webhookUseCase) send(ctx context.Context, hook model.Webhook, delivery model.Delivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, hook.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "todo-webhooks")
+	req.Header.Set(eventHeader, string(delivery.Event))
+	req.Header.Set(deliveryHeader, delivery.ID)
+	req.Header.Set(signatureHeader, Sign(hook.Secret, delivery.Payload))
+
+	resp, err := u.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drained, so the connection is reused
+	io.Copy(ioutil.Discard, io.LimitReader(resp.Body, 64<<10))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected response status %d", resp.StatusCode)
+	}
+
+	return resp.StatusCode, nil
+}
+
+// retryDelay is the backoff doubled for every failed attempt but the first one.
+func (u webhookUseCase) retryDelay(attempts int) time.Duration {
+	delay := u.backoff
+	for i := 1; i < attempts && delay < maxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > maxBackoff {
+		delay = maxBackoff
+	}
+
+	return delay
+}
+
+// Sign returns the signature header value of the payload, receivers compare it
+// with their own one in constant time.
+func Sign(secret string, payload []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write(payload)
+
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
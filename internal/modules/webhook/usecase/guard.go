@@ -0,0 +1,76 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"syscall"
+	"time"
+
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+// blockedIP tells whether the address is internal to the service's network: the webhooks
+// can't reach it, so the users can't probe the network through the delivery log.
+func blockedIP(ip net.IP) bool {
+	return ip.IsLoopback() || ip.IsPrivate() || ip.IsUnspecified() ||
+		ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() || ip.IsInterfaceLocalMulticast()
+}
+
+// checkHost makes sure every address of the webhook's host may be reached.
+func (u webhookUseCase) checkHost(ctx context.Context, rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return validate.Fail(webhook.ErrInvalidWebhook, "url", validate.CodeFormat, "url can't be parsed")
+	}
+
+	addrs, err := net.DefaultResolver.LookupIPAddr(ctx, parsed.Hostname())
+	if err != nil || len(addrs) == 0 {
+		return validate.Fail(webhook.ErrInvalidWebhook, "url", validate.CodeInvalid,
+			fmt.Sprintf("host %s can't be resolved", parsed.Hostname()))
+	}
+	for _, addr := range addrs {
+		if u.blocked(addr.IP) {
+			return validate.Fail(webhook.ErrInvalidWebhook, "url", validate.CodeNotAllowed,
+				"url must not point to a loopback, link-local or private address")
+		}
+	}
+
+	return nil
+}
+
+// newClient returns the client of the deliveries. The addresses are checked again when
+// connecting, as the host may resolve differently than on creation, and redirects aren't
+// followed, they could lead anywhere.
+func newClient(blocked func(net.IP) bool) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   requestTimeout,
+		KeepAlive: 30 * time.Second,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			host, _, err := net.SplitHostPort(address)
+			if err != nil {
+				return err
+			}
+			if ip := net.ParseIP(host); ip == nil || blocked(ip) {
+				return fmt.Errorf("address %s is not allowed", host)
+			}
+			return nil
+		},
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	// a proxy would be dialed instead of the receiver
+	transport.Proxy = nil
+	transport.DialContext = dialer.DialContext
+
+	return &http.Client{
+		Timeout:   requestTimeout,
+		Transport: transport,
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
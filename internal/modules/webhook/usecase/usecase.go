@@ -0,0 +1,268 @@
+package usecase
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+const (
+	maxURLLength = 2048
+
+	defaultDeliveries = 20
+	maxDeliveries     = 100
+)
+
+var eventTypes = map[todoModel.EventType]bool{
+	todoModel.EventCreated:   true,
+	todoModel.EventUpdated:   true,
+	todoModel.EventCompleted: true,
+	todoModel.EventDeleted:   true,
+	todoModel.EventRestored:  true,
+}
+
+type webhookUseCase struct {
+	repo   webhook.Repository
+	client *http.Client
+	// blocked tells the addresses the webhooks can't be sent to
+	blocked     func(net.IP) bool
+	maxAttempts int
+	backoff     time.Duration
+	logger      *zap.Logger
+}
+
+// NewWebhookUseCase returns the use case which also dispatches the deliveries. A failed attempt
+// is retried after backoff, doubled on every next failure, until maxAttempts are made.
+func NewWebhookUseCase(logger *zap.Logger, repo webhook.Repository, maxAttempts int, backoff time.Duration) webhook.UseCase {
+	return newWebhookUseCase(logger, repo, maxAttempts, backoff, blockedIP)
+}
+
+func newWebhookUseCase(logger *zap.Logger, repo webhook.Repository, maxAttempts int, backoff time.Duration,
+	blocked func(net.IP) bool) *webhookUseCase {
+	return &webhookUseCase{
+		repo:        repo,
+		client:      newClient(blocked),
+		blocked:     blocked,
+		maxAttempts: maxAttempts,
+		backoff:     backoff,
+		logger:      logger,
+	}
+}
+
+func (u webhookUseCase) CreateWebhook(ctx context.Context, hook model.Webhook) (model.Webhook, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	if err := validateWebhook(hook); err != nil {
+		return model.Webhook{}, err
+	}
+	if err := u.checkHost(ctx, hook.URL); err != nil {
+		return model.Webhook{}, err
+	}
+
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return model.Webhook{}, err
+	}
+
+	hook.OwnerID = user.ID
+	hook.Secret = hex.EncodeToString(secret)
+	id, err := u.repo.CreateWebhook(ctx, hook)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+	hook.ID = id
+
+	return hook, nil
+}
+
+func (u webhookUseCase) GetAllWebhooks(ctx context.Context) ([]model.Webhook, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	hooks, err := u.repo.GetAllWebhooks(ctx, user.ID)
+	if err != nil {
+		return nil, err
+	}
+	if hooks == nil {
+		hooks = []model.Webhook{}
+	}
+
+	return hooks, nil
+}
+
+func (u webhookUseCase) GetWebhook(ctx context.Context, id string) (model.Webhook, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return model.Webhook{}, err
+	}
+
+	return u.repo.GetWebhook(ctx, user.ID, id)
+}
+
+func (u webhookUseCase) UpdateWebhook(ctx context.Context, hook model.Webhook) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	if err := validateWebhook(hook); err != nil {
+		return "", err
+	}
+	if err := u.checkHost(ctx, hook.URL); err != nil {
+		return "", err
+	}
+
+	hook.OwnerID = user.ID
+	return u.repo.UpdateWebhook(ctx, hook)
+}
+
+func (u webhookUseCase) DeleteWebhook(ctx context.Context, id string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	return u.repo.DeleteWebhook(ctx, user.ID, id)
+}
+
+func (u webhookUseCase) GetDeliveries(ctx context.Context, webhookID string, limit int) ([]model.Delivery, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionReadTodo)
+	if err != nil {
+		return nil, err
+	}
+
+	switch {
+	case limit == 0:
+		limit = defaultDeliveries
+	case limit < 0 || limit > maxDeliveries:
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", webhook.ErrInvalidWebhook, maxDeliveries)
+	}
+
+	if _, err := u.repo.GetWebhook(ctx, user.ID, webhookID); err != nil {
+		return nil, err
+	}
+
+	deliveries, err := u.repo.GetDeliveries(ctx, user.ID, webhookID, limit)
+	if err != nil {
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []model.Delivery{}
+	}
+
+	return deliveries, nil
+}
+
+func (u webhookUseCase) ReplayDelivery(ctx context.Context, webhookID, id string) (string, error) {
+	user, err := auth.Authorize(ctx, auth.PermissionWriteTodo)
+	if err != nil {
+		return "", err
+	}
+
+	delivery, err := u.repo.GetDelivery(ctx, user.ID, id)
+	if err != nil {
+		return "", err
+	}
+	if delivery.WebhookID != webhookID {
+		return "", webhook.ErrDeliveryNotFound
+	}
+	if delivery.Status == model.DeliveryPending {
+		return "", webhook.ErrDeliveryPending
+	}
+	if _, err = u.repo.GetWebhook(ctx, user.ID, webhookID); err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	replay := []model.Delivery{{
+		WebhookID:     delivery.WebhookID,
+		OwnerID:       delivery.OwnerID,
+		Event:         delivery.Event,
+		Payload:       delivery.Payload,
+		Status:        model.DeliveryPending,
+		NextAttemptAt: &now,
+		ReplayOf:      delivery.ID,
+	}}
+	if err = u.repo.CreateDeliveries(ctx, replay); err != nil {
+		return "", err
+	}
+
+	return replay[0].ID, nil
+}
+
+func (u webhookUseCase) ItemChanged(ctx context.Context, event todoModel.Event) error {
+	var hooks []model.Webhook
+	for _, userID := range append([]string{event.OwnerID}, event.SharedWith...) {
+		own, err := u.repo.GetAllWebhooks(ctx, userID)
+		if err != nil {
+			return err
+		}
+		hooks = append(hooks, own...)
+	}
+
+	payload, err := json.Marshal(model.Payload{
+		Event:      event.Type,
+		EventID:    event.ID,
+		OccurredAt: event.At,
+		ItemID:     event.ItemID,
+		Item:       event.Item,
+	})
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	var deliveries []model.Delivery
+	for _, hook := range hooks {
+		if !hook.Subscribed(event.Type) {
+			continue
+		}
+		deliveries = append(deliveries, model.Delivery{
+			WebhookID:     hook.ID,
+			OwnerID:       hook.OwnerID,
+			Event:         event.Type,
+			Payload:       payload,
+			Status:        model.DeliveryPending,
+			NextAttemptAt: &now,
+		})
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+
+	return u.repo.CreateDeliveries(ctx, deliveries)
+}
+
+func validateWebhook(hook model.Webhook) error {
+	var v validate.Validator
+	v.String("url", hook.URL, validate.Required, validate.MaxLength(maxURLLength))
+	if !v.Failed("url") {
+		u, err := url.Parse(hook.URL)
+		v.Check("url", err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != "",
+			validate.CodeFormat, "url must be an absolute http or https URL")
+	}
+	for _, typ := range hook.Events {
+		v.Check("events", eventTypes[typ], validate.CodeNotAllowed, fmt.Sprintf("unknown event %q", typ))
+	}
+
+	return v.Err(webhook.ErrInvalidWebhook)
+}
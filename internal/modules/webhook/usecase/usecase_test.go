@@ -0,0 +1,375 @@
+package usecase
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/modules/auth"
+	authModel "github.com/silverspase/todo/internal/modules/auth/model"
+	todoModel "github.com/silverspase/todo/internal/modules/todo/model"
+	"github.com/silverspase/todo/internal/modules/webhook"
+	"github.com/silverspase/todo/internal/modules/webhook/model"
+	"github.com/silverspase/todo/internal/modules/webhook/repository/memory"
+)
+
+const ownerID = "owner"
+
+// request is what the receiver got.
+type request struct {
+	header http.Header
+	body   []byte
+}
+
+// receiver answers the deliveries with the statuses in turn, the last one repeated.
+type receiver struct {
+	mu       sync.Mutex
+	statuses []int
+	requests []request
+}
+
+func newReceiver(t *testing.T, statuses ...int) (*receiver, *httptest.Server) {
+	t.Helper()
+
+	rec := &receiver{statuses: statuses}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := ioutil.ReadAll(r.Body)
+
+		rec.mu.Lock()
+		defer rec.mu.Unlock()
+		rec.requests = append(rec.requests, request{header: r.Header.Clone(), body: body})
+		status := rec.statuses[0]
+		if len(rec.statuses) > 1 {
+			rec.statuses = rec.statuses[1:]
+		}
+		w.WriteHeader(status)
+	}))
+	t.Cleanup(srv.Close)
+
+	return rec, srv
+}
+
+func (rec *receiver) received() []request {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	return append([]request(nil), rec.requests...)
+}
+
+// newUseCase returns the use case with a memory repository which may reach the test receivers.
+func newUseCase(maxAttempts int, backoff time.Duration) *webhookUseCase {
+	logger := zap.NewNop()
+	allowed := func(net.IP) bool { return false }
+
+	return newWebhookUseCase(logger, memory.NewMemoryStorage(logger), maxAttempts, backoff, allowed)
+}
+
+func userContext(role authModel.Role) context.Context {
+	return auth.NewContext(context.Background(), authModel.User{ID: ownerID, Role: role})
+}
+
+func createWebhook(t *testing.T, u *webhookUseCase, url string, events ...todoModel.EventType) model.Webhook {
+	t.Helper()
+
+	hook, err := u.CreateWebhook(userContext(authModel.RoleMember), model.Webhook{URL: url, Events: events})
+	if err != nil {
+		t.Fatalf("CreateWebhook: %v", err)
+	}
+
+	return hook
+}
+
+func itemChanged(t *testing.T, u *webhookUseCase, typ todoModel.EventType) {
+	t.Helper()
+
+	event := todoModel.Event{ID: "1", Type: typ, OwnerID: ownerID, ItemID: "item", At: time.Now()}
+	if err := u.ItemChanged(context.Background(), event); err != nil {
+		t.Fatalf("ItemChanged: %v", err)
+	}
+}
+
+func getDeliveries(t *testing.T, u *webhookUseCase, webhookID string) []model.Delivery {
+	t.Helper()
+
+	deliveries, err := u.GetDeliveries(userContext(authModel.RoleMember), webhookID, 0)
+	if err != nil {
+		t.Fatalf("GetDeliveries: %v", err)
+	}
+
+	return deliveries
+}
+
+// dispatchUntil dispatches the due deliveries until done is true.
+func dispatchUntil(t *testing.T, u *webhookUseCase, done func() bool) {
+	t.Helper()
+
+	for deadline := time.Now().Add(5 * time.Second); time.Now().Before(deadline); time.Sleep(5 * time.Millisecond) {
+		u.dispatch(context.Background())
+		if done() {
+			return
+		}
+	}
+	t.Fatal("deliveries weren't dispatched in time")
+}
+
+func TestDeliverySigned(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusOK)
+	u := newUseCase(3, time.Millisecond)
+	hook := createWebhook(t, u, srv.URL)
+
+	itemChanged(t, u, todoModel.EventCreated)
+	u.dispatch(context.Background())
+
+	requests := rec.received()
+	if len(requests) != 1 {
+		t.Fatalf("got %d requests, want 1", len(requests))
+	}
+	req := requests[0]
+
+	mac := hmac.New(sha256.New, []byte(hook.Secret))
+	mac.Write(req.body)
+	if got, want := req.header.Get(signatureHeader), "sha256="+hex.EncodeToString(mac.Sum(nil)); got != want {
+		t.Errorf("signature = %q, want %q", got, want)
+	}
+	if got := req.header.Get(eventHeader); got != string(todoModel.EventCreated) {
+		t.Errorf("event header = %q, want %q", got, todoModel.EventCreated)
+	}
+
+	var payload model.Payload
+	if err := json.Unmarshal(req.body, &payload); err != nil {
+		t.Fatalf("payload: %v", err)
+	}
+	if payload.Event != todoModel.EventCreated || payload.ItemID != "item" {
+		t.Errorf("payload = %+v, want the created event of item", payload)
+	}
+
+	deliveries := getDeliveries(t, u, hook.ID)
+	if len(deliveries) != 1 {
+		t.Fatalf("got %d deliveries, want 1", len(deliveries))
+	}
+	delivery := deliveries[0]
+	if got := req.header.Get(deliveryHeader); got != delivery.ID {
+		t.Errorf("delivery header = %q, want %q", got, delivery.ID)
+	}
+	if delivery.Status != model.DeliverySucceeded || delivery.Attempts != 1 ||
+		delivery.ResponseStatus != http.StatusOK || delivery.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v, want succeeded on the first attempt", delivery)
+	}
+}
+
+func TestDeliveryRetried(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusInternalServerError, http.StatusBadGateway, http.StatusOK)
+	u := newUseCase(5, 10*time.Millisecond)
+	hook := createWebhook(t, u, srv.URL)
+
+	itemChanged(t, u, todoModel.EventUpdated)
+	u.dispatch(context.Background())
+
+	delivery := getDeliveries(t, u, hook.ID)[0]
+	if delivery.Status != model.DeliveryPending || delivery.Attempts != 1 ||
+		delivery.ResponseStatus != http.StatusInternalServerError || delivery.LastError == "" {
+		t.Fatalf("delivery = %+v, want pending after a failed attempt", delivery)
+	}
+	if delivery.NextAttemptAt == nil || !delivery.NextAttemptAt.After(delivery.UpdatedAt) {
+		t.Fatalf("next attempt at %v, want after %v", delivery.NextAttemptAt, delivery.UpdatedAt)
+	}
+
+	// not due before the backoff
+	u.dispatch(context.Background())
+	if got := len(rec.received()); got != 1 {
+		t.Fatalf("got %d requests before the backoff, want 1", got)
+	}
+
+	dispatchUntil(t, u, func() bool { return len(rec.received()) == 3 })
+
+	delivery = getDeliveries(t, u, hook.ID)[0]
+	if delivery.Status != model.DeliverySucceeded || delivery.Attempts != 3 ||
+		delivery.ResponseStatus != http.StatusOK || delivery.LastError != "" {
+		t.Errorf("delivery = %+v, want succeeded on the third attempt", delivery)
+	}
+}
+
+func TestDeliveryFailed(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusServiceUnavailable)
+	u := newUseCase(2, time.Millisecond)
+	hook := createWebhook(t, u, srv.URL)
+
+	itemChanged(t, u, todoModel.EventDeleted)
+	dispatchUntil(t, u, func() bool { return getDeliveries(t, u, hook.ID)[0].Status != model.DeliveryPending })
+
+	delivery := getDeliveries(t, u, hook.ID)[0]
+	if delivery.Status != model.DeliveryFailed || delivery.Attempts != 2 ||
+		delivery.ResponseStatus != http.StatusServiceUnavailable || delivery.LastError == "" ||
+		delivery.NextAttemptAt != nil {
+		t.Errorf("delivery = %+v, want failed after 2 attempts", delivery)
+	}
+	if got := len(rec.received()); got != 2 {
+		t.Errorf("got %d requests, want 2", got)
+	}
+}
+
+func TestRetryDelay(t *testing.T) {
+	u := newUseCase(20, time.Minute)
+
+	tests := []struct {
+		attempts int
+		want     time.Duration
+	}{
+		{1, time.Minute},
+		{2, 2 * time.Minute},
+		{3, 4 * time.Minute},
+		{8, 128 * time.Minute},
+		{9, 256 * time.Minute},
+		{10, maxBackoff},
+		{20, maxBackoff},
+	}
+	for _, tt := range tests {
+		if got := u.retryDelay(tt.attempts); got != tt.want {
+			t.Errorf("retryDelay(%d) = %v, want %v", tt.attempts, got, tt.want)
+		}
+	}
+}
+
+func TestReplayDelivery(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusOK)
+	u := newUseCase(3, time.Millisecond)
+	hook := createWebhook(t, u, srv.URL)
+	ctx := userContext(authModel.RoleMember)
+
+	itemChanged(t, u, todoModel.EventCompleted)
+	original := getDeliveries(t, u, hook.ID)[0]
+
+	if _, err := u.ReplayDelivery(ctx, hook.ID, original.ID); !errors.Is(err, webhook.ErrDeliveryPending) {
+		t.Fatalf("replaying a pending delivery: got %v, want %v", err, webhook.ErrDeliveryPending)
+	}
+
+	u.dispatch(context.Background())
+	id, err := u.ReplayDelivery(ctx, hook.ID, original.ID)
+	if err != nil {
+		t.Fatalf("ReplayDelivery: %v", err)
+	}
+	if _, err = u.ReplayDelivery(ctx, "other", original.ID); !errors.Is(err, webhook.ErrDeliveryNotFound) {
+		t.Errorf("replaying through another webhook: got %v, want %v", err, webhook.ErrDeliveryNotFound)
+	}
+	u.dispatch(context.Background())
+
+	requests := rec.received()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	if string(requests[1].body) != string(requests[0].body) {
+		t.Errorf("replayed body = %s, want %s", requests[1].body, requests[0].body)
+	}
+	if got := requests[1].header.Get(deliveryHeader); got != id {
+		t.Errorf("replayed delivery header = %q, want %q", got, id)
+	}
+
+	deliveries := getDeliveries(t, u, hook.ID)
+	if len(deliveries) != 2 {
+		t.Fatalf("got %d deliveries, want 2", len(deliveries))
+	}
+	for _, delivery := range deliveries {
+		if delivery.ID == id && (delivery.ReplayOf != original.ID || delivery.Status != model.DeliverySucceeded) {
+			t.Errorf("replay = %+v, want a succeeded replay of %s", delivery, original.ID)
+		}
+	}
+}
+
+func TestDeliveryEventFilter(t *testing.T) {
+	rec, srv := newReceiver(t, http.StatusOK)
+	u := newUseCase(3, time.Millisecond)
+	hook := createWebhook(t, u, srv.URL, todoModel.EventCompleted)
+
+	disabled := createWebhook(t, u, srv.URL)
+	disabled.Disabled = true
+	if _, err := u.UpdateWebhook(userContext(authModel.RoleMember), disabled); err != nil {
+		t.Fatalf("UpdateWebhook: %v", err)
+	}
+
+	itemChanged(t, u, todoModel.EventCreated)
+	itemChanged(t, u, todoModel.EventCompleted)
+	event := todoModel.Event{ID: "2", Type: todoModel.EventCompleted, OwnerID: "other", ItemID: "item", At: time.Now()}
+	if err := u.ItemChanged(context.Background(), event); err != nil {
+		t.Fatalf("ItemChanged: %v", err)
+	}
+	// the items shared with the webhook owner are reported too
+	event = todoModel.Event{ID: "3", Type: todoModel.EventCompleted, OwnerID: "other", SharedWith: []string{ownerID}, ItemID: "shared", At: time.Now()}
+	if err := u.ItemChanged(context.Background(), event); err != nil {
+		t.Fatalf("ItemChanged: %v", err)
+	}
+	u.dispatch(context.Background())
+
+	requests := rec.received()
+	if len(requests) != 2 {
+		t.Fatalf("got %d requests, want 2", len(requests))
+	}
+	for _, req := range requests {
+		if got := req.header.Get(eventHeader); got != string(todoModel.EventCompleted) {
+			t.Errorf("event header = %q, want %q", got, todoModel.EventCompleted)
+		}
+	}
+	if got := len(getDeliveries(t, u, hook.ID)); got != 2 {
+		t.Errorf("got %d deliveries of the subscribed webhook, want 2", got)
+	}
+	if got := len(getDeliveries(t, u, disabled.ID)); got != 0 {
+		t.Errorf("got %d deliveries of the disabled webhook, want 0", got)
+	}
+}
+
+func TestWebhookReadOnly(t *testing.T) {
+	u := newUseCase(3, time.Millisecond)
+
+	_, err := u.CreateWebhook(userContext(authModel.RoleReadOnly), model.Webhook{URL: "http://example.com"})
+	if !errors.Is(err, auth.ErrForbidden) {
+		t.Errorf("got %v, want %v", err, auth.ErrForbidden)
+	}
+}
+
+func TestInternalAddressesRefused(t *testing.T) {
+	_, srv := newReceiver(t, http.StatusOK)
+	u := NewWebhookUseCase(zap.NewNop(), memory.NewMemoryStorage(zap.NewNop()), 3, time.Millisecond)
+	ctx := userContext(authModel.RoleMember)
+
+	for _, url := range []string{srv.URL, "http://localhost/", "http://169.254.169.254/", "http://10.0.0.1/", "http://[::1]/"} {
+		_, err := u.CreateWebhook(ctx, model.Webhook{URL: url})
+		if !errors.Is(err, webhook.ErrInvalidWebhook) {
+			t.Errorf("CreateWebhook(%s): got %v, want %v", url, err, webhook.ErrInvalidWebhook)
+		}
+	}
+
+	// the host could resolve to another address when the delivery is sent
+	if _, err := newClient(blockedIP).Post(srv.URL, "application/json", nil); err == nil {
+		t.Error("the client reached a loopback address")
+	}
+}
+
+func TestRedirectNotFollowed(t *testing.T) {
+	target, targetSrv := newReceiver(t, http.StatusOK)
+	srv := httptest.NewServer(http.RedirectHandler(targetSrv.URL, http.StatusTemporaryRedirect))
+	t.Cleanup(srv.Close)
+	u := newUseCase(1, time.Millisecond)
+	hook := createWebhook(t, u, srv.URL)
+
+	itemChanged(t, u, todoModel.EventCreated)
+	u.dispatch(context.Background())
+
+	if got := len(target.received()); got != 0 {
+		t.Errorf("the redirect was followed %d times", got)
+	}
+	delivery := getDeliveries(t, u, hook.ID)[0]
+	if delivery.Status != model.DeliveryFailed || delivery.ResponseStatus != http.StatusTemporaryRedirect {
+		t.Errorf("delivery = %+v, want failed with the redirect status", delivery)
+	}
+}
@@ -0,0 +1,88 @@
+// Package problem writes errors as RFC 7807 problem details.
+package problem
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"go.uber.org/zap"
+
+	"github.com/silverspase/todo/internal/errs"
+	"github.com/silverspase/todo/internal/validate"
+)
+
+const ContentType = "application/problem+json"
+
+var statuses = map[errs.Kind]int{
+	errs.NotFound:             http.StatusNotFound,
+	errs.Conflict:             http.StatusConflict,
+	errs.Validation:           http.StatusBadRequest,
+	errs.Unauthorized:         http.StatusUnauthorized,
+	errs.Forbidden:            http.StatusForbidden,
+	errs.Gone:                 http.StatusGone,
+	errs.PreconditionFailed:   http.StatusPreconditionFailed,
+	errs.TooLarge:             http.StatusRequestEntityTooLarge,
+	errs.UnsupportedMediaType: http.StatusUnsupportedMediaType,
+}
+
+// Details is the problem details object. The type is always about:blank,
+// so the title is the status text and Code tells the error kind apart.
+type Details struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail,omitempty"`
+	Instance string `json:"instance,omitempty"`
+	Code     string `json:"code"`
+	// Errors lists the invalid fields of validation errors.
+	Errors []validate.FieldError `json:"errors,omitempty"`
+}
+
+// Status returns the HTTP status code of the error's kind.
+func Status(err error) int {
+	if status, ok := statuses[errs.KindOf(err)]; ok {
+		return status
+	}
+
+	return http.StatusInternalServerError
+}
+
+// New describes the error. Internal errors are not detailed, as their message may expose
+// the storage internals.
+func New(r *http.Request, err error) Details {
+	kind := errs.KindOf(err)
+	status := Status(err)
+	details := Details{
+		Type:     "about:blank",
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   err.Error(),
+		Instance: r.URL.Path,
+		Code:     string(kind),
+	}
+	if kind == errs.Internal {
+		details.Detail = ""
+	}
+	var fields *validate.Errors
+	if errors.As(err, &fields) {
+		details.Errors = fields.Fields
+	}
+
+	return details
+}
+
+// Write responds with the problem details of the error. Internal errors are logged with logger.
+func Write(logger *zap.Logger, w http.ResponseWriter, r *http.Request, err error) {
+	details := New(r, err)
+	if details.Status == http.StatusInternalServerError {
+		logger.Error("request failed", zap.String("method", r.Method),
+			zap.String("path", r.URL.Path), zap.Error(err))
+	}
+
+	response, _ := json.Marshal(details)
+
+	w.Header().Set("Content-Type", ContentType)
+	w.WriteHeader(details.Status)
+	w.Write(response)
+}
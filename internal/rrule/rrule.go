@@ -0,0 +1,363 @@
+// Package rrule implements a subset of the RFC 5545 recurrence rules:
+// FREQ (DAILY, WEEKLY, MONTHLY, YEARLY), INTERVAL, BYDAY, COUNT and UNTIL.
+package rrule
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type Frequency string
+
+const (
+	Daily   Frequency = "DAILY"
+	Weekly  Frequency = "WEEKLY"
+	Monthly Frequency = "MONTHLY"
+	Yearly  Frequency = "YEARLY"
+)
+
+// ErrInvalid is wrapped by every Parse error.
+var ErrInvalid = errors.New("invalid recurrence rule")
+
+// maxPeriods stops rules which never produce an occurrence, e.g. BYDAY=5MO every 12 months.
+const maxPeriods = 100000
+
+// maxWeekdaysInMonth bounds the numbered BYDAY of MONTHLY rules, no month has a 6th Monday.
+const maxWeekdaysInMonth = 5
+
+// Weekday is a BYDAY entry. N is 0 for every such weekday of the period,
+// the n-th one when positive and the n-th from the end when negative.
+// N is only meaningful for MONTHLY and YEARLY rules.
+type Weekday struct {
+	N   int
+	Day time.Weekday
+}
+
+// Rule is a parsed RRULE.
+type Rule struct {
+	Freq     Frequency
+	Interval int
+	ByDay    []Weekday
+	Count    int        // 0 means unlimited
+	Until    *time.Time // inclusive
+}
+
+var weekdays = map[string]time.Weekday{
+	"SU": time.Sunday,
+	"MO": time.Monday,
+	"TU": time.Tuesday,
+	"WE": time.Wednesday,
+	"TH": time.Thursday,
+	"FR": time.Friday,
+	"SA": time.Saturday,
+}
+
+// Parse reads a rule like "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE;COUNT=10",
+// an optional "RRULE:" prefix is allowed.
+func Parse(s string) (Rule, error) {
+	r := Rule{Interval: 1}
+
+	s = strings.TrimPrefix(strings.TrimSpace(s), "RRULE:")
+	seen := make(map[string]bool)
+	for _, part := range strings.Split(s, ";") {
+		kv := strings.SplitN(part, "=", 2)
+		if len(kv) != 2 {
+			return r, fmt.Errorf("%w: %q is not a KEY=VALUE pair", ErrInvalid, part)
+		}
+		key, value := strings.ToUpper(kv[0]), strings.ToUpper(kv[1])
+		if seen[key] {
+			return r, fmt.Errorf("%w: %s is repeated", ErrInvalid, key)
+		}
+		seen[key] = true
+
+		var err error
+		switch key {
+		case "FREQ":
+			r.Freq = Frequency(value)
+			switch r.Freq {
+			case Daily, Weekly, Monthly, Yearly:
+			default:
+				err = fmt.Errorf("unsupported FREQ %s", value)
+			}
+		case "INTERVAL":
+			r.Interval, err = strconv.Atoi(value)
+			if err == nil && r.Interval < 1 {
+				err = fmt.Errorf("INTERVAL must be positive")
+			}
+		case "COUNT":
+			r.Count, err = strconv.Atoi(value)
+			if err == nil && r.Count < 1 {
+				err = fmt.Errorf("COUNT must be positive")
+			}
+		case "UNTIL":
+			var until time.Time
+			until, err = parseUntil(value)
+			r.Until = &until
+		case "BYDAY":
+			r.ByDay, err = parseByDay(value)
+		default:
+			err = fmt.Errorf("%s is not supported", key)
+		}
+		if err != nil {
+			return r, fmt.Errorf("%w: %v", ErrInvalid, err)
+		}
+	}
+
+	if r.Freq == "" {
+		return r, fmt.Errorf("%w: FREQ is required", ErrInvalid)
+	}
+	if r.Count > 0 && r.Until != nil {
+		return r, fmt.Errorf("%w: COUNT and UNTIL are mutually exclusive", ErrInvalid)
+	}
+	for _, wd := range r.ByDay {
+		if wd.N != 0 && r.Freq != Monthly && r.Freq != Yearly {
+			return r, fmt.Errorf("%w: numbered BYDAY is only allowed with MONTHLY and YEARLY", ErrInvalid)
+		}
+		if r.Freq == Monthly && (wd.N > maxWeekdaysInMonth || wd.N < -maxWeekdaysInMonth) {
+			return r, fmt.Errorf("%w: numbered BYDAY of MONTHLY must be within -%d..%d",
+				ErrInvalid, maxWeekdaysInMonth, maxWeekdaysInMonth)
+		}
+	}
+
+	return r, nil
+}
+
+func parseUntil(s string) (time.Time, error) {
+	for _, layout := range []string{"20060102T150405Z", "20060102T150405", "20060102"} {
+		if t, err := time.Parse(layout, s); err == nil {
+			if layout == "20060102" {
+				// a date includes the whole day
+				t = t.Add(24*time.Hour - time.Nanosecond)
+			}
+			return t, nil
+		}
+	}
+
+	return time.Time{}, fmt.Errorf("UNTIL %s is not a date or UTC date-time", s)
+}
+
+func parseByDay(s string) ([]Weekday, error) {
+	var res []Weekday
+	for _, entry := range strings.Split(s, ",") {
+		if len(entry) < 2 {
+			return nil, fmt.Errorf("unknown BYDAY %q", entry)
+		}
+
+		day, ok := weekdays[entry[len(entry)-2:]]
+		if !ok {
+			return nil, fmt.Errorf("unknown BYDAY %q", entry)
+		}
+
+		wd := Weekday{Day: day}
+		if prefix := entry[:len(entry)-2]; prefix != "" {
+			n, err := strconv.Atoi(prefix)
+			if err != nil || n == 0 || n < -53 || n > 53 {
+				return nil, fmt.Errorf("unknown BYDAY %q", entry)
+			}
+			wd.N = n
+		}
+		res = append(res, wd)
+	}
+
+	return res, nil
+}
+
+// String returns the canonical form of the rule.
+func (r Rule) String() string {
+	parts := []string{"FREQ=" + string(r.Freq)}
+	if r.Interval > 1 {
+		parts = append(parts, "INTERVAL="+strconv.Itoa(r.Interval))
+	}
+	if len(r.ByDay) > 0 {
+		days := make([]string, 0, len(r.ByDay))
+		for _, wd := range r.ByDay {
+			day := strings.ToUpper(wd.Day.String()[:2])
+			if wd.N != 0 {
+				day = strconv.Itoa(wd.N) + day
+			}
+			days = append(days, day)
+		}
+		parts = append(parts, "BYDAY="+strings.Join(days, ","))
+	}
+	if r.Count > 0 {
+		parts = append(parts, "COUNT="+strconv.Itoa(r.Count))
+	}
+	if r.Until != nil {
+		parts = append(parts, "UNTIL="+r.Until.UTC().Format("20060102T150405Z"))
+	}
+
+	return strings.Join(parts, ";")
+}
+
+// After returns up to n occurrences of the series starting at start which are later than after.
+// Like DTSTART in RFC 5545, start itself is always the first occurrence.
+func (r Rule) After(start, after time.Time, n int) []time.Time {
+	var res []time.Time
+	r.each(start, func(t time.Time) bool {
+		if t.After(after) {
+			res = append(res, t)
+		}
+		return len(res) < n
+	})
+
+	return res
+}
+
+// Next returns the first occurrence later than after, false when the series is over.
+func (r Rule) Next(start, after time.Time) (time.Time, bool) {
+	res := r.After(start, after, 1)
+	if len(res) == 0 {
+		return time.Time{}, false
+	}
+
+	return res[0], true
+}
+
+// each calls fn with the occurrences in chronological order until it returns false
+// or the series ends.
+func (r Rule) each(start time.Time, fn func(time.Time) bool) {
+	count := 0
+	emit := func(t time.Time) bool {
+		if r.Until != nil && t.After(*r.Until) {
+			return false
+		}
+		count++
+		if !fn(t) {
+			return false
+		}
+		return r.Count == 0 || count < r.Count
+	}
+
+	if !emit(start) {
+		return
+	}
+
+	for period := 0; period < maxPeriods; period++ {
+		candidates := r.candidates(start, period*r.Interval)
+		for _, t := range candidates {
+			if !t.After(start) {
+				continue
+			}
+			if !emit(t) {
+				return
+			}
+		}
+		if r.Until != nil && len(candidates) > 0 && candidates[len(candidates)-1].After(*r.Until) {
+			return
+		}
+	}
+}
+
+// candidates returns the sorted occurrences in the period which is offset frequency units after start.
+func (r Rule) candidates(start time.Time, offset int) []time.Time {
+	hour, min, sec := start.Clock()
+	at := func(year int, month time.Month, day int) time.Time {
+		return time.Date(year, month, day, hour, min, sec, start.Nanosecond(), start.Location())
+	}
+
+	var res []time.Time
+	switch r.Freq {
+	case Daily:
+		t := start.AddDate(0, 0, offset)
+		if len(r.ByDay) == 0 || r.hasWeekday(t.Weekday()) {
+			res = append(res, t)
+		}
+
+	case Weekly:
+		if len(r.ByDay) == 0 {
+			res = append(res, start.AddDate(0, 0, 7*offset))
+			break
+		}
+		// weeks start on Monday, the RFC 5545 default WKST
+		monday := start.AddDate(0, 0, -((int(start.Weekday())+6)%7)+7*offset)
+		for i := 0; i < 7; i++ {
+			t := monday.AddDate(0, 0, i)
+			if r.hasWeekday(t.Weekday()) {
+				res = append(res, t)
+			}
+		}
+
+	case Monthly:
+		first := time.Date(start.Year(), start.Month()+time.Month(offset), 1, 0, 0, 0, 0, start.Location())
+		year, month := first.Year(), first.Month()
+		if len(r.ByDay) == 0 {
+			// months without this day are skipped, as RFC 5545 requires
+			if start.Day() <= daysIn(year, month) {
+				res = append(res, at(year, month, start.Day()))
+			}
+			break
+		}
+		res = r.byDayIn(at, year, month, month)
+
+	case Yearly:
+		year := start.Year() + offset
+		if len(r.ByDay) == 0 {
+			if start.Day() <= daysIn(year, start.Month()) {
+				res = append(res, at(year, start.Month(), start.Day()))
+			}
+			break
+		}
+		res = r.byDayIn(at, year, time.January, time.December)
+	}
+
+	sort.Slice(res, func(i, j int) bool { return res[i].Before(res[j]) })
+	return res
+}
+
+// byDayIn expands BYDAY within the months from..to of the year.
+func (r Rule) byDayIn(at func(int, time.Month, int) time.Time, year int, from, to time.Month) []time.Time {
+	var days []time.Time
+	for month := from; month <= to; month++ {
+		for day := 1; day <= daysIn(year, month); day++ {
+			days = append(days, at(year, month, day))
+		}
+	}
+
+	seen := make(map[int]bool)
+	var res []time.Time
+	for _, wd := range r.ByDay {
+		var matching []int
+		for i, t := range days {
+			if t.Weekday() == wd.Day {
+				matching = append(matching, i)
+			}
+		}
+
+		var picked []int
+		switch {
+		case wd.N == 0:
+			picked = matching
+		case wd.N > 0 && wd.N <= len(matching):
+			picked = []int{matching[wd.N-1]}
+		case wd.N < 0 && -wd.N <= len(matching):
+			picked = []int{matching[len(matching)+wd.N]}
+		}
+
+		for _, i := range picked {
+			if !seen[i] {
+				seen[i] = true
+				res = append(res, days[i])
+			}
+		}
+	}
+
+	return res
+}
+
+func (r Rule) hasWeekday(day time.Weekday) bool {
+	for _, wd := range r.ByDay {
+		if wd.Day == day {
+			return true
+		}
+	}
+
+	return false
+}
+
+func daysIn(year int, month time.Month) int {
+	return time.Date(year, month+1, 0, 0, 0, 0, 0, time.UTC).Day()
+}
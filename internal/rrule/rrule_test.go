@@ -0,0 +1,200 @@
+package rrule_test
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/silverspase/todo/internal/rrule"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+		want string // the canonical form
+	}{
+		{name: "daily", rule: "FREQ=DAILY", want: "FREQ=DAILY"},
+		{
+			name: "prefix and lower case",
+			rule: "RRULE:freq=weekly;interval=2;byday=mo,we",
+			want: "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+		},
+		{
+			name: "default interval",
+			rule: "FREQ=MONTHLY;INTERVAL=1;BYDAY=-1FR;COUNT=5",
+			want: "FREQ=MONTHLY;BYDAY=-1FR;COUNT=5",
+		},
+		{name: "fifth weekday", rule: "FREQ=MONTHLY;BYDAY=5MO,-5SU", want: "FREQ=MONTHLY;BYDAY=5MO,-5SU"},
+		{
+			name: "weekday of the year",
+			rule: "FREQ=YEARLY;BYDAY=20MO;UNTIL=20301231T000000Z",
+			want: "FREQ=YEARLY;BYDAY=20MO;UNTIL=20301231T000000Z",
+		},
+		{name: "until date", rule: "FREQ=DAILY;UNTIL=20240115", want: "FREQ=DAILY;UNTIL=20240115T235959Z"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := rrule.Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+			if got := r.String(); got != tt.want {
+				t.Errorf("String() = %q, want %q", got, tt.want)
+			}
+
+			again, err := rrule.Parse(r.String())
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", r.String(), err)
+			}
+			if got := again.String(); got != tt.want {
+				t.Errorf("String() after the round trip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule string
+	}{
+		{name: "empty", rule: ""},
+		{name: "no FREQ", rule: "INTERVAL=2"},
+		{name: "unsupported FREQ", rule: "FREQ=HOURLY"},
+		{name: "not a pair", rule: "FREQ=DAILY;COUNT"},
+		{name: "repeated key", rule: "FREQ=DAILY;FREQ=WEEKLY"},
+		{name: "unsupported key", rule: "FREQ=DAILY;BYMONTH=1"},
+		{name: "zero interval", rule: "FREQ=DAILY;INTERVAL=0"},
+		{name: "zero count", rule: "FREQ=DAILY;COUNT=0"},
+		{name: "bad until", rule: "FREQ=DAILY;UNTIL=tomorrow"},
+		{name: "count and until", rule: "FREQ=DAILY;COUNT=2;UNTIL=20240115"},
+		{name: "unknown weekday", rule: "FREQ=WEEKLY;BYDAY=XX"},
+		{name: "numbered weekly", rule: "FREQ=WEEKLY;BYDAY=1MO"},
+		{name: "zeroth weekday", rule: "FREQ=MONTHLY;BYDAY=0MO"},
+		{name: "sixth weekday of the month", rule: "FREQ=MONTHLY;BYDAY=6MO"},
+		{name: "sixth last weekday of the month", rule: "FREQ=MONTHLY;BYDAY=-6FR"},
+		{name: "weekday of the year in a month", rule: "FREQ=MONTHLY;BYDAY=53MO"},
+		{name: "54th weekday of the year", rule: "FREQ=YEARLY;BYDAY=54MO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := rrule.Parse(tt.rule); !errors.Is(err, rrule.ErrInvalid) {
+				t.Errorf("Parse(%q): got %v, want %v", tt.rule, err, rrule.ErrInvalid)
+			}
+		})
+	}
+}
+
+func TestAfter(t *testing.T) {
+	tests := []struct {
+		name  string
+		rule  string
+		start string
+		n     int
+		want  []string // the start is the first one
+	}{
+		{
+			name:  "interval",
+			rule:  "FREQ=DAILY;INTERVAL=2",
+			start: "2024-01-01",
+			n:     3,
+			want:  []string{"2024-01-01", "2024-01-03", "2024-01-05"},
+		},
+		{
+			name:  "weekdays every other week",
+			rule:  "FREQ=WEEKLY;INTERVAL=2;BYDAY=MO,WE",
+			start: "2024-01-01",
+			n:     4,
+			want:  []string{"2024-01-01", "2024-01-03", "2024-01-15", "2024-01-17"},
+		},
+		{
+			name:  "count",
+			rule:  "FREQ=DAILY;COUNT=3",
+			start: "2024-01-01",
+			n:     10,
+			want:  []string{"2024-01-01", "2024-01-02", "2024-01-03"},
+		},
+		{
+			name:  "until",
+			rule:  "FREQ=WEEKLY;UNTIL=20240115",
+			start: "2024-01-01",
+			n:     10,
+			want:  []string{"2024-01-01", "2024-01-08", "2024-01-15"},
+		},
+		{
+			name:  "last weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=-1FR",
+			start: "2024-01-26",
+			n:     4,
+			want:  []string{"2024-01-26", "2024-02-23", "2024-03-29", "2024-04-26"},
+		},
+		{
+			name:  "fifth weekday of the month",
+			rule:  "FREQ=MONTHLY;BYDAY=5MO",
+			start: "2024-01-29",
+			n:     3,
+			want:  []string{"2024-01-29", "2024-04-29", "2024-07-29"},
+		},
+		{
+			name:  "month end",
+			rule:  "FREQ=MONTHLY",
+			start: "2024-01-31",
+			n:     4,
+			want:  []string{"2024-01-31", "2024-03-31", "2024-05-31", "2024-07-31"},
+		},
+		{
+			name:  "leap day",
+			rule:  "FREQ=YEARLY",
+			start: "2024-02-29",
+			n:     3,
+			want:  []string{"2024-02-29", "2028-02-29", "2032-02-29"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r, err := rrule.Parse(tt.rule)
+			if err != nil {
+				t.Fatalf("Parse(%q): %v", tt.rule, err)
+			}
+
+			start := date(t, tt.start)
+			got := r.After(start, start.Add(-time.Second), tt.n)
+			if len(got) != len(tt.want) {
+				t.Fatalf("got %d occurrences %v, want %v", len(got), got, tt.want)
+			}
+			for i, want := range tt.want {
+				if !got[i].Equal(date(t, want)) {
+					t.Errorf("occurrence %d = %v, want %s 09:00", i, got[i], want)
+				}
+			}
+		})
+	}
+}
+
+func TestNextAfterTheEnd(t *testing.T) {
+	r, err := rrule.Parse("FREQ=DAILY;COUNT=2")
+	if err != nil {
+		t.Fatalf("Parse: %v", err)
+	}
+
+	start := date(t, "2024-01-01")
+	if next, ok := r.Next(start, start); !ok || !next.Equal(date(t, "2024-01-02")) {
+		t.Errorf("Next after the start = %v, %v, want 2024-01-02 09:00", next, ok)
+	}
+	if next, ok := r.Next(start, date(t, "2024-01-02")); ok {
+		t.Errorf("Next after the last one = %v, want none", next)
+	}
+}
+
+// date returns the day at 09:00 UTC.
+func date(t *testing.T, day string) time.Time {
+	t.Helper()
+
+	d, err := time.Parse("2006-01-02", day)
+	if err != nil {
+		t.Fatalf("bad date %q: %v", day, err)
+	}
+
+	return d.Add(9 * time.Hour)
+}
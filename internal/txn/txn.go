@@ -0,0 +1,53 @@
+// Package txn carries a transaction begun by a repository in the context, so the other
+// repositories of the database take part in it and the use cases act once it's committed.
+package txn
+
+import "context"
+
+type ctxKey struct{}
+
+type transaction struct {
+	conn interface{}
+	// outer is the context the transaction was begun in
+	outer     context.Context
+	committed []func(ctx context.Context)
+}
+
+// Begin returns ctx carrying the transaction conn of the repository.
+func Begin(ctx context.Context, conn interface{}) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &transaction{conn: conn, outer: ctx})
+}
+
+// Conn returns the transaction of ctx, nil outside of one.
+func Conn(ctx context.Context) interface{} {
+	if tx, ok := ctx.Value(ctxKey{}).(*transaction); ok {
+		return tx.conn
+	}
+
+	return nil
+}
+
+// AfterCommit calls fn once the transaction of ctx is committed, right away outside of one.
+// fn gets the context the transaction was begun in.
+func AfterCommit(ctx context.Context, fn func(ctx context.Context)) {
+	tx, ok := ctx.Value(ctxKey{}).(*transaction)
+	if !ok {
+		fn(ctx)
+		return
+	}
+
+	tx.committed = append(tx.committed, fn)
+}
+
+// Committed calls the AfterCommit funcs, the repository which began the transaction
+// of ctx calls it once the transaction is committed.
+func Committed(ctx context.Context) {
+	tx, ok := ctx.Value(ctxKey{}).(*transaction)
+	if !ok {
+		return
+	}
+
+	for _, fn := range tx.committed {
+		fn(tx.outer)
+	}
+}
@@ -0,0 +1,50 @@
+package validate
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// Unmarshal decodes the JSON value of data into v, rejecting unknown fields.
+// The errors are described by JSONError.
+func Unmarshal(data []byte, v interface{}, invalid error) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(v); err != nil {
+		return JSONError(err, invalid)
+	}
+
+	return nil
+}
+
+// JSONError describes an error of a json.Decoder, which disallows unknown fields, as Errors
+// wrapping invalid. The offending field is named when it's known.
+func JSONError(err error, invalid error) error {
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	switch {
+	case err == io.EOF:
+		return Fail(invalid, "", CodeRequired, "body is empty")
+	case errors.As(err, &syntaxErr):
+		return Fail(invalid, "", CodeFormat, fmt.Sprintf("malformed JSON at offset %d", syntaxErr.Offset))
+	case err == io.ErrUnexpectedEOF:
+		return Fail(invalid, "", CodeFormat, "malformed JSON, unexpected end of body")
+	case errors.As(err, &typeErr):
+		name := typeErr.Field
+		if name == "" {
+			name = "body"
+		}
+		return Fail(invalid, typeErr.Field, CodeType, fmt.Sprintf("%s must not be a JSON %s", name, typeErr.Value))
+	case strings.HasPrefix(err.Error(), "json: unknown field "):
+		// encoding/json has no type for this error
+		field := strings.Trim(strings.TrimPrefix(err.Error(), "json: unknown field "), `"`)
+		return Fail(invalid, field, CodeUnknownField, fmt.Sprintf("unknown field %s", field))
+	default:
+		// errors of the UnmarshalJSON methods, e.g. an unknown priority
+		return Fail(invalid, "", CodeInvalid, err.Error())
+	}
+}
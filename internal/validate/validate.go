@@ -0,0 +1,179 @@
+// Package validate checks the input of the use cases and reports every invalid field,
+// so the client can fix them all at once.
+package validate
+
+import (
+	"fmt"
+	"net/mail"
+	"strings"
+	"unicode/utf8"
+)
+
+// Codes of the field errors, the client can rely on them.
+const (
+	CodeRequired     = "required"
+	CodeTooShort     = "too_short"
+	CodeTooLong      = "too_long"
+	CodeTooMany      = "too_many"
+	CodeFormat       = "invalid_format"
+	CodeNotAllowed   = "not_allowed"
+	CodeTaken        = "taken"
+	CodeNotFound     = "not_found"
+	CodeUnknownField = "unknown_field"
+	CodeType         = "invalid_type"
+	CodeInvalid      = "invalid"
+	CodeReadOnly     = "read_only"
+)
+
+// FieldError is a violated rule of one input field. Field is empty when the error
+// is about the input as a whole.
+type FieldError struct {
+	Field   string `json:"field"`
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+// Errors is returned for invalid input. It wraps the sentinel error of the input,
+// so errors.Is and errs.KindOf see through it.
+type Errors struct {
+	err    error
+	Fields []FieldError
+}
+
+func (e *Errors) Error() string {
+	messages := make([]string, 0, len(e.Fields))
+	for _, f := range e.Fields {
+		messages = append(messages, f.Message)
+	}
+
+	return fmt.Sprintf("%v: %s", e.err, strings.Join(messages, "; "))
+}
+
+func (e *Errors) Unwrap() error {
+	return e.err
+}
+
+// Fail returns the error of a single invalid field.
+func Fail(err error, field, code, message string) error {
+	return &Errors{err: err, Fields: []FieldError{{Field: field, Code: code, Message: message}}}
+}
+
+// Rule checks a string and returns the code and the message of the violation,
+// empty strings when the value is valid. The message is prefixed with the field name.
+type Rule func(value string) (code, message string)
+
+// Required rejects blank strings.
+func Required(value string) (string, string) {
+	if strings.TrimSpace(value) == "" {
+		return CodeRequired, "is required"
+	}
+
+	return "", ""
+}
+
+// MinLength rejects non-empty strings shorter than n characters, use Required for empty ones.
+func MinLength(n int) Rule {
+	return func(value string) (string, string) {
+		if value != "" && utf8.RuneCountInString(value) < n {
+			return CodeTooShort, fmt.Sprintf("is shorter than %d characters", n)
+		}
+
+		return "", ""
+	}
+}
+
+// MaxLength rejects strings longer than n characters.
+func MaxLength(n int) Rule {
+	return func(value string) (string, string) {
+		if utf8.RuneCountInString(value) > n {
+			return CodeTooLong, fmt.Sprintf("is longer than %d characters", n)
+		}
+
+		return "", ""
+	}
+}
+
+// Email accepts a bare address like "ann@example.com", without a display name.
+// Empty strings pass, use Required for them.
+func Email(value string) (string, string) {
+	if value == "" {
+		return "", ""
+	}
+	if addr, err := mail.ParseAddress(value); err != nil || addr.Address != value {
+		return CodeFormat, "is not a valid email address"
+	}
+
+	return "", ""
+}
+
+// OneOf accepts the listed values only. An empty value, when listed, isn't shown in the message
+// as it stands for a field left out.
+func OneOf(values ...string) Rule {
+	return func(value string) (string, string) {
+		for _, v := range values {
+			if value == v {
+				return "", ""
+			}
+		}
+
+		allowed := make([]string, 0, len(values))
+		for _, v := range values {
+			if v != "" {
+				allowed = append(allowed, v)
+			}
+		}
+
+		return CodeNotAllowed, "must be one of " + strings.Join(allowed, ", ")
+	}
+}
+
+// Validator collects the field errors:
+//
+//	var v validate.Validator
+//	v.String("title", item.Title, validate.Required, validate.MaxLength(255))
+//	v.Check("priority", item.Priority.Valid(), validate.CodeNotAllowed, "priority is unknown")
+//	return v.Err(todo.ErrInvalidItem)
+type Validator struct {
+	fields []FieldError
+}
+
+// String applies the rules to the field value, the first violated one is reported.
+func (v *Validator) String(field, value string, rules ...Rule) {
+	for _, rule := range rules {
+		if code, message := rule(value); code != "" {
+			v.Add(field, code, field+" "+message)
+			return
+		}
+	}
+}
+
+// Check reports the field when ok is false.
+func (v *Validator) Check(field string, ok bool, code, message string) {
+	if !ok {
+		v.Add(field, code, message)
+	}
+}
+
+func (v *Validator) Add(field, code, message string) {
+	v.fields = append(v.fields, FieldError{Field: field, Code: code, Message: message})
+}
+
+// Failed tells whether the field is already reported, so the costly checks can be skipped.
+func (v *Validator) Failed(field string) bool {
+	for _, f := range v.fields {
+		if f.Field == field {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Err returns nil when every check passed, Errors wrapping err otherwise.
+func (v *Validator) Err(err error) error {
+	if len(v.fields) == 0 {
+		return nil
+	}
+
+	return &Errors{err: err, Fields: v.fields}
+}
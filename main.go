@@ -3,6 +3,7 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"os"
 	"os/signal"
 	"syscall"
@@ -13,6 +14,13 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "migrate" {
+		if err := application.Migrate(os.Args[2:], os.Stdout); err != nil {
+			log.Fatal(err)
+		}
+		return
+	}
+
 	app, err := application.Init()
 	if err != nil {
 		log.Fatal(err)
@@ -30,7 +38,22 @@ func main() {
 			log.Printf("%v", err)
 		}
 	}()
-	app.Logger.Info("The service is ready to listen and serve", zap.String("port:", app.Cfg.Port))
+	go func() {
+		lis, err := net.Listen("tcp", ":"+app.Cfg.GRPCPort)
+		if err == nil {
+			err = app.GRPCSrv.Serve(lis)
+		}
+		if err != nil {
+			shutdown <- struct{}{}
+			log.Printf("%v", err)
+		}
+	}()
+	workers, stopWorkers := context.WithCancel(context.Background())
+	go app.Webhooks.Run(workers)
+	go app.Items.RunRetention(workers)
+
+	app.Logger.Info("The service is ready to listen and serve",
+		zap.String("port:", app.Cfg.Port), zap.String("grpc port:", app.Cfg.GRPCPort))
 
 	select {
 	case killSignal := <-interrupt:
@@ -46,5 +69,7 @@ func main() {
 
 	app.Logger.Info("The service is shutting down...")
 	app.Srv.Shutdown(context.Background())
+	app.GRPCSrv.GracefulStop()
+	stopWorkers()
 	app.Logger.Info("Done")
 }